@@ -19,6 +19,7 @@ import (
 type FlightMonitor struct {
 	client       *client.Client
 	eventManager *client.SystemEventManager
+	simEvents    *client.EventManager
 	flightData   *client.FlightDataManager
 	dashboard    *Dashboard
 	stateTracker *StateTracker
@@ -119,6 +120,9 @@ func (fm *FlightMonitor) setupManagers() error {
 	// Create SystemEventManager
 	fm.eventManager = client.NewSystemEventManager(fm.client)
 
+	// Create EventManager for sending key events such as PAUSE_TOGGLE
+	fm.simEvents = client.NewEventManager(fm.client)
+
 	// Create FlightDataManager for integration testing
 	fm.flightData = client.NewFlightDataManager(fm.client)
 
@@ -671,9 +675,11 @@ func (fm *FlightMonitor) displayFlightData() {
 }
 
 func (fm *FlightMonitor) togglePause() {
-	fmt.Println("⏸️ Attempting to toggle simulation pause...")
-	fmt.Println("❗ Note: Direct pause control not implemented in this library version")
-	fmt.Println("💡 Please use ESC or PAUSE key in MSFS to test pause events")
+	fmt.Println("⏸️ Toggling simulation pause...")
+	if err := fm.simEvents.Send("PAUSE_TOGGLE"); err != nil {
+		fmt.Printf("❌ Failed to toggle pause: %v\n", err)
+		return
+	}
 	fmt.Println("🔍 Watch for Pause/Paused/Unpaused events in the monitor output")
 }
 
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -19,37 +20,28 @@ func main() { // Create a new SimConnect client
 
 	fmt.Printf("Successfully connected to SimConnect as '%s'\n", simclient.GetName())
 	fmt.Printf("Connection handle: 0x%X\n", simclient.GetHandle())
-	// Example: Request system state information
-	requestID := client.DataRequestID(1)
-
-	fmt.Println("\nRequesting system states...")
-
-	// Request simulation state
-	if err := simclient.RequestSystemState(requestID, client.SystemStateSim); err != nil {
-		log.Printf("Failed to request simulation state: %v", err)
-	} else {
-		fmt.Println("✓ Simulation state requested")
-	}
-
-	// Request aircraft loaded
-	requestID++
-	if err := simclient.RequestSystemState(requestID, client.SystemStateAircraftLoaded); err != nil {
-		log.Printf("Failed to request aircraft loaded: %v", err)
-	} else {
-		fmt.Println("✓ Aircraft loaded state requested")
-	}
-
-	// Request flight plan
-	requestID++
-	if err := simclient.RequestSystemState(requestID, client.SystemStateFlightPlan); err != nil {
-		log.Printf("Failed to request flight plan: %v", err)
-	} else {
-		fmt.Println("✓ Flight plan state requested")
+	// Example: Query system state information
+	fmt.Println("\nQuerying system states...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	states := []string{client.SystemStateSim, client.SystemStateAircraftLoaded, client.SystemStateFlightPlan}
+	for _, state := range states {
+		value, err := simclient.QuerySystemState(ctx, state)
+		if err != nil {
+			log.Printf("Failed to query %s: %v", state, err)
+			continue
+		}
+
+		switch value := value.(type) {
+		case client.SystemStatePath:
+			fmt.Printf("✓ %s: %q\n", value.State, value.Path)
+		case client.SystemStateFlag:
+			fmt.Printf("✓ %s: %t\n", value.State, value.Enabled)
+		}
 	}
 
-	// Give some time for requests to process
-	fmt.Println("\nWaiting for responses...")
-	time.Sleep(2 * time.Second)
 	// Close the connection
 	fmt.Println("\nClosing SimConnect connection...")
 	if err := simclient.Close(); err != nil {
@@ -165,21 +165,19 @@ func main() {
 		fmt.Printf("  - FlightDataManager created alongside SystemEventManager\n")
 	}
 
-	// Test that both use the same underlying GetRawDispatch mechanism
-	// (Since client is not open, both should fail with same error)
-	_, errFDM := simClient.GetRawDispatch()
-	if errFDM != nil && errFDM.Error() == "client is not open" {
-		fmt.Printf("  ✅ FlightDataManager and SystemEventManager share GetRawDispatch method\n")
+	// Test that both managers share the client's dispatcher
+	if simClient.Dispatcher() != nil && !simClient.Dispatcher().IsRunning() {
+		fmt.Printf("  ✅ FlightDataManager and SystemEventManager share the client dispatcher\n")
 	} else {
-		log.Printf("  ❌ Unexpected GetRawDispatch behavior: %v", errFDM)
+		log.Printf("  ❌ Unexpected dispatcher state")
 	}
 
-	// Test that SystemEventManager's processEventsFromRawDispatch method exists and handles connection validation
+	// Test that SystemEventManager validates the connection before registering with the dispatcher
 	err = eventManager.Start()
 	if err != nil && err.Error() == "SimConnect client is not open" {
-		fmt.Printf("  ✅ SystemEventManager.processEventsFromRawDispatch properly validates connection\n")
+		fmt.Printf("  ✅ SystemEventManager.Start properly validates connection\n")
 	} else {
-		log.Printf("  ❌ Unexpected processEventsFromRawDispatch behavior: %v", err)
+		log.Printf("  ❌ Unexpected Start behavior: %v", err)
 	}
 
 	// Verify both managers can be created together without conflicts
@@ -210,8 +208,8 @@ func main() {
 	fmt.Println("   - Event state management")
 	fmt.Println("   - Convenience methods for common operations")
 	fmt.Println("✅ Integration with existing dispatch loop:")
-	fmt.Println("   - Uses GetRawDispatch() instead of competing for messages")
-	fmt.Println("   - Shared message queue with FlightDataManager")
+	fmt.Println("   - Uses the client Dispatcher instead of competing for messages")
+	fmt.Println("   - Shared message pump with FlightDataManager")
 	fmt.Println("   - Event-specific message filtering and parsing")
 	fmt.Println("   - Maintains compatibility with existing managers")
 	fmt.Println("Ready to proceed with Step 6: Comprehensive testing example")
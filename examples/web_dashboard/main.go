@@ -13,6 +13,7 @@ import (
 type FlightData struct {
 	// Connection status
 	Connected bool   `json:"connected"`
+	State     string `json:"state"` // Disconnected, Connecting, Open, Quitting or Lost
 	Error     string `json:"error,omitempty"`
 
 	// Flight variables
@@ -85,6 +86,9 @@ func initSimConnect() {
 
 	// Create client with specific DLL path
 	simclient = client.NewClientWithDLLPath("MSFS Web Dashboard", "C:\\MSFS 2024 SDK\\SimConnect SDK\\lib\\SimConnect.dll")
+	simclient.OnStateChange(func(from, to client.ConnectionState) {
+		log.Printf("🔌 SimConnect state: %s -> %s", from, to)
+	})
 
 	// Try to connect
 	if err := simclient.Open(); err != nil {
@@ -215,8 +219,21 @@ func collectFlightData() FlightData {
 	}
 
 	// Check if SimConnect is available
+	state := client.StateDisconnected
+	if simclient != nil {
+		state = simclient.State()
+	}
+	data.State = state.String()
+
 	if simclient == nil || !simclient.IsOpen() {
-		data.Error = "SimConnect not connected"
+		switch state {
+		case client.StateQuitting:
+			data.Error = "Simulator is shutting down"
+		case client.StateLost:
+			data.Error = "SimConnect connection lost"
+		default:
+			data.Error = "SimConnect not connected"
+		}
 		log.Printf("❌ Debug: SimConnect check failed - simclient nil or not open")
 		return data
 	}
@@ -348,12 +365,11 @@ func collectFlightData() FlightData {
 	} else {
 		log.Printf("☁️ Debug: Cloud Coverage variable not found")
 	} // Collect game information variables
-	if title, ok := fdm.GetVariable("Aircraft Title"); ok {
-		// Convert float to string for aircraft title (may need special handling)
-		data.AircraftTitle = fmt.Sprintf("Aircraft %.0f", title.Value)
-		log.Printf("✈️ Debug: Aircraft Title = %.0f", title.Value)
+	if title, err := fdm.GetString("Aircraft Title"); err == nil {
+		data.AircraftTitle = title
+		log.Printf("✈️ Debug: Aircraft Title = %s", title)
 	} else {
-		log.Printf("✈️ Debug: Aircraft Title variable not found")
+		log.Printf("✈️ Debug: Aircraft Title unavailable: %v", err)
 	}
 	if simRate, ok := fdm.GetVariable("Simulation Rate"); ok {
 		data.SimulationRate = simRate.Value
@@ -1,144 +1,294 @@
 package client
 
 import (
+	"context"
+	"encoding/binary"
 	"fmt"
-	"syscall"
-	"unsafe"
+	"io"
+	"math"
+	"sync"
+	"time"
 )
 
 // HRESULT constants
 const (
 	S_OK                              = 0x00000000
 	E_FAIL                            = uint32(0x80004005)
+	E_NOTIMPL                         = uint32(0x80004001)
 	E_INVALIDARG                      = uint32(0x80070057)
 	STATUS_REMOTE_DISCONNECT          = uint32(0xC000013C)
 	SIMCONNECT_OPEN_CONFIGINDEX_LOCAL = 0
 )
 
+// openRetryInterval is how often OpenContext retries Open while the simulator is not running
+const openRetryInterval = time.Second
+
 // Request ID type for SimConnect operations
 type DataRequestID uint32
 
 // Client represents a SimConnect client instance
 type Client struct {
-	handle uintptr          // HANDLE to SimConnect object
-	dll    *syscall.LazyDLL // Reference to SimConnect.dll
-	isOpen bool             // Connection state
-	name   string           // Client name
+	transport   Transport       // Backend carrying SimConnect calls (SimConnect.dll or network)
+	dispatcher  *Dispatcher     // Routes incoming messages to registered handlers
+	ids         *IDRegistry     // Definition, request, event and group IDs shared by all users
+	conn        connectionState // Connection state and its subscribers
+	name        string          // Client name
+	worker      worker          // Serialises transport calls on one OS thread
+	sent        sentPackets     // Recently sent packets for exception correlation
+	replayMutex sync.Mutex      // Guards replays
+	replays     []replayEntry   // Re-create definitions and subscriptions after a reconnect
+	nextReplay  HandlerID       // Last assigned replay ID
+}
+
+// newClient creates a client on top of a transport together with its dispatcher
+func newClient(name string, transport Transport) *Client {
+	c := &Client{
+		name:      name,
+		transport: transport,
+		ids:       newIDRegistry(),
+	}
+	c.dispatcher = newDispatcher(c)
+	return c
 }
 
 // NewClient creates a new SimConnect client instance
 func NewClient(name string) *Client {
-	return &Client{
-		name: name,
-		dll:  syscall.NewLazyDLL("SimConnect.dll"),
-	}
+	return newClient(name, newDLLTransport("SimConnect.dll"))
 }
 
 // NewClientWithDLLPath creates a new SimConnect client instance with custom DLL path
 func NewClientWithDLLPath(name, dllPath string) *Client {
-	return &Client{
-		name: name,
-		dll:  syscall.NewLazyDLL(dllPath),
-	}
+	return newClient(name, newDLLTransport(dllPath))
+}
+
+// NewNetworkClient creates a SimConnect client that speaks the SimConnect wire protocol over TCP
+// without SimConnect.dll. The address (host:port) must match a <Protocol>IPv4</Protocol> entry
+// in the simulator's SimConnect.xml, which makes it usable from non-Windows hosts.
+func NewNetworkClient(name, address string) *Client {
+	return NewNetworkClientWithDialer(name, dialTCP(address))
+}
+
+// NewNetworkClientWithDialer creates a network client that uses dial to open the underlying
+// stream, e.g. a named pipe or a connection through a proxy. The stream must carry SimConnect framing.
+func NewNetworkClientWithDialer(name string, dial func() (io.ReadWriteCloser, error)) *Client {
+	return newClient(name, newNetTransport(dial))
+}
+
+// NewClientWithTransport creates a SimConnect client on top of a custom Transport,
+// e.g. a MemoryTransport for tests that run without the simulator
+func NewClientWithTransport(name string, transport Transport) *Client {
+	return newClient(name, transport)
 }
 
 // Open establishes a connection to the SimConnect server
 // Implements SimConnect_Open function
+// The client is Connecting until the SIMCONNECT_RECV_OPEN message is dispatched.
 func (c *Client) Open() error {
-	if c.isOpen {
+	switch c.State() {
+	case StateConnecting, StateOpen:
 		return fmt.Errorf("client is already open")
+	case StateQuitting, StateLost:
+		c.worker.call(c.transport.Close) // Release the dead connection before reconnecting
 	}
 
-	// Get the SimConnect_Open function from DLL
-	proc := c.dll.NewProc("SimConnect_Open")
+	c.setState(StateConnecting)
+	c.worker.start()
+	if err := c.worker.call(func() error { return c.transport.Open(c.name) }); err != nil {
+		c.setState(StateDisconnected)
+		c.worker.stop()
+		return err
+	}
 
-	// Convert name to null-terminated byte array
-	nameBytes, err := syscall.BytePtrFromString(c.name)
-	if err != nil {
-		return fmt.Errorf("failed to convert name to bytes: %v", err)
-	}
-	// Call SimConnect_Open
-	// HRESULT SimConnect_Open(HANDLE* phSimConnect, LPCSTR szName, HWND hWnd,
-	//                         DWORD UserEventWin32, HANDLE hEventHandle, DWORD ConfigIndex)
-	r1, _, _ := proc.Call(
-		uintptr(unsafe.Pointer(&c.handle)), // phSimConnect
-		uintptr(unsafe.Pointer(nameBytes)), // szName
-		0,                                  // hWnd (NULL)
-		0,                                  // UserEventWin32
-		0,                                  // hEventHandle
-		uintptr(SIMCONNECT_OPEN_CONFIGINDEX_LOCAL), // ConfigIndex
-	)
-
-	hresult := uint32(r1)
-	if !IsHRESULTSuccess(hresult) {
-		return NewSimConnectError("SimConnect_Open", hresult, GetHRESULTMessage(hresult))
-	}
-
-	c.isOpen = true
+	c.sent.reset() // Packet IDs restart with every connection
 	return nil
 }
 
+// OpenContext opens the connection like Open and waits until the simulator confirms it with
+// SIMCONNECT_RECV_OPEN. While the simulator is not running Open is retried every second.
+// If ctx is done or the connection ends first, the client is closed again and an error returned.
+func (c *Client) OpenContext(ctx context.Context) error {
+	// Connection outcome reported by the dispatcher
+	outcome := make(chan ConnectionState, 1)
+	handler := c.OnStateChange(func(from, to ConnectionState) {
+		if to == StateOpen || to == StateQuitting || to == StateLost {
+			select {
+			case outcome <- to:
+			default:
+			}
+		}
+	})
+	defer c.RemoveStateChangeHandler(handler)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := c.Open()
+		if err == nil {
+			break
+		}
+		if c.IsOpen() {
+			return err // Opened before, nothing to wait for
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(openRetryInterval):
+		}
+	}
+
+	c.dispatcher.Start()
+	defer c.dispatcher.Stop()
+
+	select {
+	case state := <-outcome:
+		if state == StateOpen {
+			return nil
+		}
+		c.Close()
+		return fmt.Errorf("connection %s before it was confirmed", state)
+	case <-ctx.Done():
+		c.Close()
+		return ctx.Err()
+	}
+}
+
 // Close terminates the connection to the SimConnect server
 // Implements SimConnect_Close function
+// Close also releases connections the simulator has quit or lost.
 func (c *Client) Close() error {
-	if !c.isOpen {
+	state := c.State()
+	if state == StateDisconnected {
 		return fmt.Errorf("client is not open")
 	}
 
-	// Get the SimConnect_Close function from DLL
-	proc := c.dll.NewProc("SimConnect_Close")
-	// Call SimConnect_Close
-	// HRESULT SimConnect_Close(HANDLE hSimConnect)
-	r1, _, _ := proc.Call(c.handle)
-
-	hresult := uint32(r1)
-	if !IsHRESULTSuccess(hresult) {
-		return NewSimConnectError("SimConnect_Close", hresult, GetHRESULTMessage(hresult))
+	// Closing a connection the simulator already dropped may fail, the client is disconnected either way
+	if err := c.worker.call(c.transport.Close); err != nil && (state == StateConnecting || state == StateOpen) {
+		return err
 	}
 
-	c.isOpen = false
-	c.handle = 0
+	c.setState(StateDisconnected)
+	c.worker.stop() // Started again by the next Open
 	return nil
 }
 
 // RequestSystemState requests information from Microsoft Flight Simulator system components
 // Implements SimConnect_RequestSystemState function
 func (c *Client) RequestSystemState(requestID DataRequestID, state string) error {
-	if !c.isOpen {
+	if !c.IsOpen() {
 		return fmt.Errorf("client is not open")
 	}
 
-	// Get the SimConnect_RequestSystemState function from DLL
-	proc := c.dll.NewProc("SimConnect_RequestSystemState")
+	c.ids.markUsed(IDRequest, uint32(requestID))
 
-	// Convert state string to null-terminated byte array
-	stateBytes, err := syscall.BytePtrFromString(state)
-	if err != nil {
-		return fmt.Errorf("failed to convert state to bytes: %v", err)
-	} // Call SimConnect_RequestSystemState
-	// HRESULT SimConnect_RequestSystemState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char* szState)
-	r1, _, _ := proc.Call(
-		c.handle,                            // hSimConnect
-		uintptr(requestID),                  // RequestID
-		uintptr(unsafe.Pointer(stateBytes)), // szState
-	)
+	packet := SentPacket{Operation: "RequestSystemState", Detail: fmt.Sprintf("'%s'", state), RequestID: uint32(requestID)}
+	return c.send(packet, func() error {
+		return c.transport.RequestSystemState(requestID, state)
+	})
+}
+
+// RequestSystemStateContext requests a system state like RequestSystemState and waits for the answer.
+// It returns the exception SimConnect raised for the request, or ctx.Err() when ctx is done first.
+func (c *Client) RequestSystemStateContext(ctx context.Context, requestID DataRequestID, state string) (*SystemStateResponse, error) {
+	responses := make(chan *SystemStateResponse, 1)
+	failures := make(chan error, 1)
+
+	dataHandler := c.dispatcher.HandleRequest(uint32(requestID), func(data []byte) {
+		recv, err := ParseSystemState(data)
+		if err != nil {
+			select {
+			case failures <- err:
+			default:
+			}
+			return
+		}
+		select {
+		case responses <- newSystemStateResponse(recv):
+		default:
+		}
+	})
+	defer c.dispatcher.RemoveHandler(dataHandler)
+
+	exceptionHandler := c.dispatcher.HandleException(func(err *ExceptionError) bool {
+		if err.Packet == nil || err.Packet.Operation != "RequestSystemState" || err.Packet.RequestID != uint32(requestID) {
+			return false
+		}
+		select {
+		case failures <- err:
+		default:
+		}
+		return true
+	})
+	defer c.dispatcher.RemoveHandler(exceptionHandler)
 
-	hresult := uint32(r1)
-	if !IsHRESULTSuccess(hresult) {
-		return NewSimConnectError("SimConnect_RequestSystemState", hresult, GetHRESULTMessage(hresult))
+	c.dispatcher.Start()
+	defer c.dispatcher.Stop()
+
+	if err := c.RequestSystemState(requestID, state); err != nil {
+		return nil, err
 	}
 
-	return nil
+	select {
+	case response := <-responses:
+		return response, nil
+	case err := <-failures:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
-// IsOpen returns whether the client connection is open
+// IsOpen returns whether the client connection is usable (Connecting or Open)
 func (c *Client) IsOpen() bool {
-	return c.isOpen
+	state := c.State()
+	return state == StateConnecting || state == StateOpen
 }
 
 // GetHandle returns the internal SimConnect handle (for advanced use cases)
+// Returns 0 when the client does not use SimConnect.dll
 func (c *Client) GetHandle() uintptr {
-	return c.handle
+	if h, ok := c.transport.(interface{ Handle() uintptr }); ok {
+		return h.Handle()
+	}
+	return 0
+}
+
+// Dispatcher returns the message dispatcher shared by all managers of this client.
+// Register handlers on it instead of calling GetRawDispatch while managers are running.
+func (c *Client) Dispatcher() *Dispatcher {
+	return c.dispatcher
+}
+
+// WaitForMessage blocks until a message may be available, the connection ends or ctx is done,
+// and returns ctx.Err() in the latter case. Drain all queued messages with GetRawDispatch after
+// each wake-up. Transports that cannot signal messages (see MessageWaiter) and closed clients
+// are polled every 50 ms instead.
+func (c *Client) WaitForMessage(ctx context.Context) error {
+	if waiter, ok := c.transport.(MessageWaiter); ok && c.IsOpen() {
+		err := waiter.WaitForMessage(ctx)
+		if err == nil || ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// The transport could not wait, fall back to polling
+	}
+
+	timer := time.NewTimer(dispatchInterval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// IDs returns the registry handing out definition, request, event and group IDs for this client.
+// Allocate IDs from it when mixing direct calls with managers so they cannot collide.
+func (c *Client) IDs() *IDRegistry {
+	return c.ids
 }
 
 // GetName returns the client name
@@ -150,68 +300,60 @@ func (c *Client) GetName() string {
 // Note: SimConnect does not have a built-in function to send messages to the MSFS console.
 // This function uses OutputDebugString which sends messages to the Windows debug console
 // that can be viewed with tools like DebugView or Visual Studio Output window.
+// On other platforms the message is written to stderr.
 func (c *Client) SendDebugMessage(message string) error {
-	if !c.isOpen {
+	if !c.IsOpen() {
 		return fmt.Errorf("client is not open")
 	}
 
-	// Get the OutputDebugStringA function from kernel32.dll
-	kernel32 := syscall.NewLazyDLL("kernel32.dll")
-	outputDebugStringA := kernel32.NewProc("OutputDebugStringA")
-
-	// Convert message string to null-terminated byte array
-	messageBytes, err := syscall.BytePtrFromString(fmt.Sprintf("[SimConnect:%s] %s", c.name, message))
-	if err != nil {
-		return fmt.Errorf("failed to convert message to bytes: %v", err)
-	}
-
-	// Call OutputDebugStringA
-	// void OutputDebugStringA(LPCSTR lpOutputString)
-	outputDebugStringA.Call(uintptr(unsafe.Pointer(messageBytes)))
-	return nil
+	return outputDebugString(fmt.Sprintf("[SimConnect:%s] %s", c.name, message))
 }
 
 // AddToDataDefinition adds a simulation variable to a data definition
 // Implements SimConnect_AddToDataDefinition function
 func (c *Client) AddToDataDefinition(defineID DataDefinitionID, datumName, unitsName string, datumType SIMCONNECT_DATATYPE) error {
-	if !c.isOpen {
-		return fmt.Errorf("client is not open")
-	}
+	// fEpsilon 0.0 for exact match, DatumID 0 for automatic assignment
+	return c.AddToDataDefinitionWithEpsilon(defineID, datumName, unitsName, datumType, 0, 0)
+}
 
-	// Get the SimConnect_AddToDataDefinition function from DLL
-	proc := c.dll.NewProc("SimConnect_AddToDataDefinition")
+// AddToDataDefinitionWithEpsilon adds a simulation variable to a data definition with a change threshold and datum ID
+// Implements SimConnect_AddToDataDefinition function with all parameters; epsilon is the change
+// a request with SIMCONNECT_DATA_REQUEST_FLAG_CHANGED ignores, datumID tags the value in TAGGED data
+func (c *Client) AddToDataDefinitionWithEpsilon(defineID DataDefinitionID, datumName, unitsName string, datumType SIMCONNECT_DATATYPE, epsilon float32, datumID uint32) error {
+	return c.addToDataDefinition(nil, defineID, datumName, unitsName, datumType, epsilon, datumID)
+}
 
-	// Convert strings to null-terminated byte arrays
-	datumNameBytes, err := syscall.BytePtrFromString(datumName)
-	if err != nil {
-		return fmt.Errorf("failed to convert datum name to bytes: %v", err)
+// addToDataDefinition is AddToDataDefinitionWithEpsilon recording owner with the sent packet
+func (c *Client) addToDataDefinition(owner interface{}, defineID DataDefinitionID, datumName, unitsName string, datumType SIMCONNECT_DATATYPE, epsilon float32, datumID uint32) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
 	}
 
-	unitsNameBytes, err := syscall.BytePtrFromString(unitsName)
-	if err != nil {
-		return fmt.Errorf("failed to convert units name to bytes: %v", err)
-	}
+	c.ids.markUsed(IDDefinition, uint32(defineID))
 
-	// Call SimConnect_AddToDataDefinition
-	// HRESULT SimConnect_AddToDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID,
-	//                                        const char* DatumName, const char* UnitsName,
-	//                                        SIMCONNECT_DATATYPE DatumType, float fEpsilon, DWORD DatumID)
-	r1, _, _ := proc.Call(
-		c.handle,                                // hSimConnect
-		uintptr(defineID),                       // DefineID
-		uintptr(unsafe.Pointer(datumNameBytes)), // DatumName
-		uintptr(unsafe.Pointer(unitsNameBytes)), // UnitsName
-		uintptr(datumType),                      // DatumType
-		uintptr(0),                              // fEpsilon (0.0 for exact match)
-		uintptr(0),                              // DatumID (0 for automatic assignment)
-	)
+	packet := SentPacket{Operation: "AddToDataDefinition", Detail: fmt.Sprintf("'%s'", datumName), DefineID: defineID, owner: owner}
+	return c.send(packet, func() error {
+		return c.transport.AddToDataDefinition(defineID, datumName, unitsName, datumType, epsilon, datumID)
+	})
+}
+
+// ClearDataDefinition removes all simulation variables from a data definition
+// Implements SimConnect_ClearDataDefinition function; requests still using the definition
+// should be cancelled with SIMCONNECT_PERIOD_NEVER first
+func (c *Client) ClearDataDefinition(defineID DataDefinitionID) error {
+	return c.clearDataDefinition(nil, defineID)
+}
 
-	hresult := uint32(r1)
-	if !IsHRESULTSuccess(hresult) {
-		return NewSimConnectError("SimConnect_AddToDataDefinition", hresult, GetHRESULTMessage(hresult))
+// clearDataDefinition is ClearDataDefinition recording owner with the sent packet
+func (c *Client) clearDataDefinition(owner interface{}, defineID DataDefinitionID) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
 	}
 
-	return nil
+	packet := SentPacket{Operation: "ClearDataDefinition", DefineID: defineID, owner: owner}
+	return c.send(packet, func() error {
+		return c.transport.ClearDataDefinition(defineID)
+	})
 }
 
 // RequestDataOnSimObject requests data for the specified simulation object
@@ -223,118 +365,74 @@ func (c *Client) RequestDataOnSimObject(requestID SimObjectDataRequestID, define
 // RequestDataOnSimObjectWithFlags requests data for the specified simulation object with flags and timing parameters
 // Implements SimConnect_RequestDataOnSimObject function with all parameters
 func (c *Client) RequestDataOnSimObjectWithFlags(requestID SimObjectDataRequestID, defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, period SIMCONNECT_PERIOD, flags SIMCONNECT_DATA_REQUEST_FLAG, origin, interval, limit uint32) error {
-	if !c.isOpen {
+	return c.requestDataOnSimObject(nil, requestID, defineID, objectID, period, flags, origin, interval, limit)
+}
+
+// requestDataOnSimObject is RequestDataOnSimObjectWithFlags recording owner with the sent packet
+func (c *Client) requestDataOnSimObject(owner interface{}, requestID SimObjectDataRequestID, defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, period SIMCONNECT_PERIOD, flags SIMCONNECT_DATA_REQUEST_FLAG, origin, interval, limit uint32) error {
+	if !c.IsOpen() {
 		return fmt.Errorf("client is not open")
 	}
 
-	// Get the SimConnect_RequestDataOnSimObject function from DLL
-	proc := c.dll.NewProc("SimConnect_RequestDataOnSimObject")
-
-	// Call SimConnect_RequestDataOnSimObject
-	// HRESULT SimConnect_RequestDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID,
-	//                                           SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID,
-	//                                           SIMCONNECT_PERIOD Period, SIMCONNECT_DATA_REQUEST_FLAG Flags,
-	//                                           DWORD origin, DWORD interval, DWORD limit)
-	r1, _, _ := proc.Call(
-		c.handle,           // hSimConnect
-		uintptr(requestID), // RequestID
-		uintptr(defineID),  // DefineID
-		uintptr(objectID),  // ObjectID
-		uintptr(period),    // Period
-		uintptr(flags),     // Flags
-		uintptr(origin),    // origin
-		uintptr(interval),  // interval
-		uintptr(limit),     // limit
-	)
+	c.ids.markUsed(IDRequest, uint32(requestID))
 
-	hresult := uint32(r1)
-	if !IsHRESULTSuccess(hresult) {
-		return NewSimConnectError("SimConnect_RequestDataOnSimObject", hresult, GetHRESULTMessage(hresult))
+	packet := SentPacket{
+		Operation: "RequestDataOnSimObject",
+		Detail:    fmt.Sprintf("request %d (definition %d)", requestID, defineID),
+		DefineID:  defineID,
+		RequestID: uint32(requestID),
+		owner:     owner,
 	}
-
-	return nil
+	return c.send(packet, func() error {
+		return c.transport.RequestDataOnSimObject(requestID, defineID, objectID, period, flags, origin, interval, limit)
+	})
 }
 
 // GetRawDispatch retrieves the next message from SimConnect as raw bytes
 // Implements SimConnect_GetNextDispatch function returning raw data
 func (c *Client) GetRawDispatch() ([]byte, error) {
-	if !c.isOpen {
+	if !c.IsOpen() {
 		return nil, fmt.Errorf("client is not open")
 	}
 
-	// Get the SimConnect_GetNextDispatch function from DLL
-	proc := c.dll.NewProc("SimConnect_GetNextDispatch")
-
-	var pData uintptr
-	var cbData uint32
-
-	// Call SimConnect_GetNextDispatch
-	// HRESULT SimConnect_GetNextDispatch(HANDLE hSimConnect, SIMCONNECT_RECV** ppData, DWORD* pcbData)
-	r1, _, _ := proc.Call(
-		c.handle,                         // hSimConnect
-		uintptr(unsafe.Pointer(&pData)),  // ppData
-		uintptr(unsafe.Pointer(&cbData)), // pcbData
-	)
-
-	hresult := uint32(r1)
-
-	// Handle E_FAIL as "no data available" - this is normal behavior
-	if hresult == E_FAIL {
-		return nil, nil // No message available in queue
-	}
-
-	if !IsHRESULTSuccess(hresult) {
-		return nil, NewSimConnectError("SimConnect_GetNextDispatch", hresult, GetHRESULTMessage(hresult))
-	}
-
-	// Check if we have data
-	if pData == 0 || cbData == 0 {
-		return nil, nil // No message available
+	var data []byte
+	err := c.worker.call(func() error {
+		var err error
+		data, err = c.transport.GetNextDispatch()
+		return err
+	})
+	if err != nil {
+		c.observeError(err)
+		return nil, err
 	}
 
-	// Copy the data from the SimConnect-managed memory to our own buffer
-	buffer := make([]byte, cbData)
-	for i := uint32(0); i < cbData; i++ {
-		buffer[i] = *(*byte)(unsafe.Pointer(pData + uintptr(i)))
+	if data != nil {
+		c.observeMessage(data)
 	}
-
-	return buffer, nil
+	return data, nil
 }
 
 // SetDataOnSimObject sets data on a simulation object
 // Implements SimConnect_SetDataOnSimObject function
 func (c *Client) SetDataOnSimObject(defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, flags SIMCONNECT_DATA_SET_FLAG, data []byte) error {
-	if !c.isOpen {
+	return c.setDataOnSimObject(nil, defineID, objectID, flags, data)
+}
+
+// setDataOnSimObject is SetDataOnSimObject recording owner with the sent packet
+func (c *Client) setDataOnSimObject(owner interface{}, defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, flags SIMCONNECT_DATA_SET_FLAG, data []byte) error {
+	if !c.IsOpen() {
 		return fmt.Errorf("client is not open")
 	}
 
-	// Get the SimConnect_SetDataOnSimObject function from DLL
-	proc := c.dll.NewProc("SimConnect_SetDataOnSimObject")
-
-	// Calculate parameters
-	arrayCount := uint32(1)       // We're setting one data element
-	unitSize := uint32(len(data)) // Size of our data in bytes
-
-	// Call SimConnect_SetDataOnSimObject
-	// HRESULT SimConnect_SetDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID,
-	//                                       SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_SET_FLAG Flags,
-	//                                       DWORD ArrayCount, DWORD cbUnitSize, void* pDataSet)
-	r1, _, _ := proc.Call(
-		c.handle,                          // hSimConnect
-		uintptr(defineID),                 // DefineID
-		uintptr(objectID),                 // ObjectID
-		uintptr(flags),                    // Flags
-		uintptr(arrayCount),               // ArrayCount
-		uintptr(unitSize),                 // cbUnitSize
-		uintptr(unsafe.Pointer(&data[0])), // pDataSet
-	)
-
-	hresult := uint32(r1)
-	if !IsHRESULTSuccess(hresult) {
-		return NewSimConnectError("SimConnect_SetDataOnSimObject", hresult, GetHRESULTMessage(hresult))
+	if len(data) == 0 {
+		return fmt.Errorf("no data to set")
 	}
 
-	return nil
+	// We're setting one data element of len(data) bytes
+	packet := SentPacket{Operation: "SetDataOnSimObject", Detail: fmt.Sprintf("definition %d", defineID), DefineID: defineID, owner: owner}
+	return c.send(packet, func() error {
+		return c.transport.SetDataOnSimObject(defineID, objectID, flags, 1, uint32(len(data)), data)
+	})
 }
 
 // SetFloat64OnSimObject sets a single float64 value on a simulation object
@@ -342,7 +440,7 @@ func (c *Client) SetDataOnSimObject(defineID DataDefinitionID, objectID SIMCONNE
 func (c *Client) SetFloat64OnSimObject(defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, value float64) error {
 	// Convert float64 to byte array
 	data := make([]byte, 8)
-	*(*float64)(unsafe.Pointer(&data[0])) = value
+	binary.LittleEndian.PutUint64(data, math.Float64bits(value))
 
 	// Use non-tagged mode since we're setting a single variable with its own data definition
 	return c.SetDataOnSimObject(defineID, objectID, SIMCONNECT_DATA_SET_FLAG_DEFAULT, data)
@@ -352,7 +450,7 @@ func (c *Client) SetFloat64OnSimObject(defineID DataDefinitionID, objectID SIMCO
 func (c *Client) SetFloat32OnSimObject(defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, value float32) error {
 	// Convert float32 to byte array
 	data := make([]byte, 4)
-	*(*float32)(unsafe.Pointer(&data[0])) = value
+	binary.LittleEndian.PutUint32(data, math.Float32bits(value))
 
 	return c.SetDataOnSimObject(defineID, objectID, SIMCONNECT_DATA_SET_FLAG_DEFAULT, data)
 }
@@ -361,98 +459,352 @@ func (c *Client) SetFloat32OnSimObject(defineID DataDefinitionID, objectID SIMCO
 func (c *Client) SetInt32OnSimObject(defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, value int32) error {
 	// Convert int32 to byte array
 	data := make([]byte, 4)
-	*(*int32)(unsafe.Pointer(&data[0])) = value
+	binary.LittleEndian.PutUint32(data, uint32(value))
 
 	return c.SetDataOnSimObject(defineID, objectID, SIMCONNECT_DATA_SET_FLAG_DEFAULT, data)
 }
 
-// SubscribeToSystemEvent subscribes to a system event notification
-// Implements SimConnect_SubscribeToSystemEvent function
-func (c *Client) SubscribeToSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, systemEventName string) error {
-	if !c.isOpen {
+// MapClientEventToSimEvent connects a client event ID to a sim event (key event) such as "GEAR_TOGGLE"
+// Implements SimConnect_MapClientEventToSimEvent function; an empty name defines a custom client event
+func (c *Client) MapClientEventToSimEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, eventName string) error {
+	if !c.IsOpen() {
 		return fmt.Errorf("client is not open")
 	}
 
-	// Get the SimConnect_SubscribeToSystemEvent function from DLL
-	proc := c.dll.NewProc("SimConnect_SubscribeToSystemEvent")
+	c.ids.markUsed(IDEvent, uint32(eventID))
 
-	// Convert system event name to null-terminated byte array
-	eventNameBytes, err := syscall.BytePtrFromString(systemEventName)
-	if err != nil {
-		return fmt.Errorf("failed to convert system event name to bytes: %v", err)
+	packet := SentPacket{Operation: "MapClientEventToSimEvent", Detail: fmt.Sprintf("'%s'", eventName), EventID: eventID}
+	return c.send(packet, func() error {
+		return c.transport.MapClientEventToSimEvent(eventID, eventName)
+	})
+}
+
+// TransmitClientEvent sends a mapped client event with one parameter to the simulator
+// Implements SimConnect_TransmitClientEvent function; with SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY
+// groupID carries a SIMCONNECT_GROUP_PRIORITY instead of a notification group
+func (c *Client) TransmitClientEvent(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, data uint32, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
 	}
 
-	// Call SimConnect_SubscribeToSystemEvent
-	// HRESULT SimConnect_SubscribeToSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char* SystemEventName)
-	r1, _, _ := proc.Call(
-		c.handle,                                // hSimConnect
-		uintptr(eventID),                        // EventID
-		uintptr(unsafe.Pointer(eventNameBytes)), // SystemEventName
-	)
+	packet := SentPacket{Operation: "TransmitClientEvent", EventID: eventID}
+	return c.send(packet, func() error {
+		return c.transport.TransmitClientEvent(objectID, eventID, data, groupID, flags)
+	})
+}
 
-	hresult := uint32(r1)
-	if !IsHRESULTSuccess(hresult) {
-		return NewSimConnectError("SimConnect_SubscribeToSystemEvent", hresult, GetHRESULTMessage(hresult))
+// TransmitClientEvent_EX1 sends a mapped client event with up to five parameters to the simulator
+// Implements SimConnect_TransmitClientEvent_EX1 function, available in MSFS only
+func (c *Client) TransmitClientEvent_EX1(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG, data0, data1, data2, data3, data4 uint32) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
 	}
 
-	return nil
+	data := [5]uint32{data0, data1, data2, data3, data4}
+	packet := SentPacket{Operation: "TransmitClientEvent_EX1", EventID: eventID}
+	return c.send(packet, func() error {
+		return c.transport.TransmitClientEvent_EX1(objectID, eventID, groupID, flags, data)
+	})
 }
 
-// UnsubscribeFromSystemEvent unsubscribes from a system event notification
-// Implements SimConnect_UnsubscribeFromSystemEvent function
-func (c *Client) UnsubscribeFromSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID) error {
-	if !c.isOpen {
+// AddClientEventToNotificationGroup adds a mapped client event to a notification group, so the client
+// receives the event whenever it occurs in the simulator. Maskable events can be withheld from the
+// simulator and lower priority groups once the group priority is SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE or lower.
+// Implements SimConnect_AddClientEventToNotificationGroup function
+func (c *Client) AddClientEventToNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, maskable bool) error {
+	if !c.IsOpen() {
 		return fmt.Errorf("client is not open")
 	}
 
-	// Get the SimConnect_UnsubscribeFromSystemEvent function from DLL
-	proc := c.dll.NewProc("SimConnect_UnsubscribeFromSystemEvent")
+	c.ids.markUsed(IDNotificationGroup, uint32(groupID))
+
+	packet := SentPacket{Operation: "AddClientEventToNotificationGroup", Detail: fmt.Sprintf("group %d", groupID), EventID: eventID, GroupID: groupID}
+	return c.send(packet, func() error {
+		return c.transport.AddClientEventToNotificationGroup(groupID, eventID, maskable)
+	})
+}
 
-	// Call SimConnect_UnsubscribeFromSystemEvent
-	// HRESULT SimConnect_UnsubscribeFromSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID)
-	r1, _, _ := proc.Call(
-		c.handle,         // hSimConnect
-		uintptr(eventID), // EventID
-	)
+// RemoveClientEvent removes a client event from a notification group
+// Implements SimConnect_RemoveClientEvent function
+func (c *Client) RemoveClientEvent(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
 
-	hresult := uint32(r1)
-	if !IsHRESULTSuccess(hresult) {
-		return NewSimConnectError("SimConnect_UnsubscribeFromSystemEvent", hresult, GetHRESULTMessage(hresult))
+	packet := SentPacket{Operation: "RemoveClientEvent", Detail: fmt.Sprintf("group %d", groupID), EventID: eventID, GroupID: groupID}
+	return c.send(packet, func() error {
+		return c.transport.RemoveClientEvent(groupID, eventID)
+	})
+}
+
+// SetNotificationGroupPriority sets the priority of a notification group; groups exist once an event was added
+// Implements SimConnect_SetNotificationGroupPriority function
+func (c *Client) SetNotificationGroupPriority(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
 	}
 
-	return nil
+	packet := SentPacket{Operation: "SetNotificationGroupPriority", Detail: fmt.Sprintf("group %d", groupID), GroupID: groupID}
+	return c.send(packet, func() error {
+		return c.transport.SetNotificationGroupPriority(groupID, priority)
+	})
+}
+
+// ClearNotificationGroup removes all client events from a notification group
+// Implements SimConnect_ClearNotificationGroup function
+func (c *Client) ClearNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	packet := SentPacket{Operation: "ClearNotificationGroup", Detail: fmt.Sprintf("group %d", groupID), GroupID: groupID}
+	return c.send(packet, func() error {
+		return c.transport.ClearNotificationGroup(groupID)
+	})
+}
+
+// MapInputEventToClientEvent connects a key or joystick input such as "shift+ctrl+u" or "joystick:0:button:3"
+// to client events: downEventID is transmitted with downValue when the input is pressed, upEventID
+// (SIMCONNECT_UNUSED for none) with upValue when it is released. Maskable inputs are withheld from
+// lower priority input groups and the simulator's own bindings.
+// Implements SimConnect_MapInputEventToClientEvent function
+func (c *Client) MapInputEventToClientEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string, downEventID SIMCONNECT_CLIENT_EVENT_ID, downValue uint32, upEventID SIMCONNECT_CLIENT_EVENT_ID, upValue uint32, maskable bool) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	c.ids.markUsed(IDInputGroup, uint32(groupID))
+
+	packet := SentPacket{Operation: "MapInputEventToClientEvent", Detail: fmt.Sprintf("'%s'", inputDefinition), EventID: downEventID, InputGroupID: groupID}
+	return c.send(packet, func() error {
+		return c.transport.MapInputEventToClientEvent(groupID, inputDefinition, downEventID, downValue, upEventID, upValue, maskable)
+	})
+}
+
+// SetInputGroupPriority sets the priority of an input group; groups exist once an input was mapped
+// Implements SimConnect_SetInputGroupPriority function
+func (c *Client) SetInputGroupPriority(groupID SIMCONNECT_INPUT_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	packet := SentPacket{Operation: "SetInputGroupPriority", Detail: fmt.Sprintf("input group %d", groupID), InputGroupID: groupID}
+	return c.send(packet, func() error {
+		return c.transport.SetInputGroupPriority(groupID, priority)
+	})
+}
+
+// RemoveInputEvent removes an input definition from an input group
+// Implements SimConnect_RemoveInputEvent function
+func (c *Client) RemoveInputEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	packet := SentPacket{Operation: "RemoveInputEvent", Detail: fmt.Sprintf("'%s'", inputDefinition), InputGroupID: groupID}
+	return c.send(packet, func() error {
+		return c.transport.RemoveInputEvent(groupID, inputDefinition)
+	})
+}
+
+// ClearInputGroup removes all input definitions from an input group
+// Implements SimConnect_ClearInputGroup function
+func (c *Client) ClearInputGroup(groupID SIMCONNECT_INPUT_GROUP_ID) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	packet := SentPacket{Operation: "ClearInputGroup", Detail: fmt.Sprintf("input group %d", groupID), InputGroupID: groupID}
+	return c.send(packet, func() error {
+		return c.transport.ClearInputGroup(groupID)
+	})
+}
+
+// SetInputGroupState turns an input group on or off
+// Implements SimConnect_SetInputGroupState function
+func (c *Client) SetInputGroupState(groupID SIMCONNECT_INPUT_GROUP_ID, state SIMCONNECT_STATE) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	packet := SentPacket{Operation: "SetInputGroupState", Detail: fmt.Sprintf("input group %d", groupID), InputGroupID: groupID}
+	return c.send(packet, func() error {
+		return c.transport.SetInputGroupState(groupID, state)
+	})
+}
+
+// RequestReservedKey reserves the first available of up to three keys (e.g. "q"), used together with
+// the Tab key, for a client event; the simulator answers with SIMCONNECT_RECV_RESERVED_KEY
+// Implements SimConnect_RequestReservedKey function
+func (c *Client) RequestReservedKey(eventID SIMCONNECT_CLIENT_EVENT_ID, keyChoice1, keyChoice2, keyChoice3 string) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	packet := SentPacket{Operation: "RequestReservedKey", Detail: fmt.Sprintf("'%s'", keyChoice1), EventID: eventID}
+	return c.send(packet, func() error {
+		return c.transport.RequestReservedKey(eventID, keyChoice1, keyChoice2, keyChoice3)
+	})
+}
+
+// SubscribeToSystemEvent subscribes to a system event notification
+// Implements SimConnect_SubscribeToSystemEvent function
+func (c *Client) SubscribeToSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, systemEventName string) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	c.ids.markUsed(IDEvent, uint32(eventID))
+
+	packet := SentPacket{Operation: "SubscribeToSystemEvent", Detail: fmt.Sprintf("'%s'", systemEventName), EventID: eventID}
+	return c.send(packet, func() error {
+		return c.transport.SubscribeToSystemEvent(eventID, systemEventName)
+	})
+}
+
+// UnsubscribeFromSystemEvent unsubscribes from a system event notification
+// Implements SimConnect_UnsubscribeFromSystemEvent function
+func (c *Client) UnsubscribeFromSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	packet := SentPacket{Operation: "UnsubscribeFromSystemEvent", Detail: fmt.Sprintf("event %d", eventID), EventID: eventID}
+	return c.send(packet, func() error {
+		return c.transport.UnsubscribeFromSystemEvent(eventID)
+	})
 }
 
 // SetSystemEventState sets the state of a system event (ON/OFF)
 // Implements SimConnect_SetSystemEventState function
 func (c *Client) SetSystemEventState(eventID SIMCONNECT_CLIENT_EVENT_ID, state SIMCONNECT_STATE) error {
-	if !c.isOpen {
+	if !c.IsOpen() {
 		return fmt.Errorf("client is not open")
 	}
 
-	// Get the SimConnect_SetSystemEventState function from DLL
-	proc := c.dll.NewProc("SimConnect_SetSystemEventState")
+	packet := SentPacket{Operation: "SetSystemEventState", Detail: fmt.Sprintf("event %d", eventID), EventID: eventID}
+	return c.send(packet, func() error {
+		return c.transport.SetSystemEventState(eventID, state)
+	})
+}
 
-	// Call SimConnect_SetSystemEventState
-	// HRESULT SimConnect_SetSystemEventState(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, SIMCONNECT_STATE State)
-	r1, _, _ := proc.Call(
-		c.handle,         // hSimConnect
-		uintptr(eventID), // EventID
-		uintptr(state),   // State (ON/OFF)
-	)
+// EnumerateInputEvents requests the input events of the loaded aircraft (MSFS 2024); the simulator
+// answers with one or more SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS messages
+// Implements SimConnect_EnumerateInputEvents function
+func (c *Client) EnumerateInputEvents(requestID DataRequestID) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	c.ids.markUsed(IDRequest, uint32(requestID))
+
+	packet := SentPacket{Operation: "EnumerateInputEvents", Detail: fmt.Sprintf("request %d", requestID), RequestID: uint32(requestID)}
+	return c.send(packet, func() error {
+		return c.transport.EnumerateInputEvents(requestID)
+	})
+}
 
-	hresult := uint32(r1)
-	if !IsHRESULTSuccess(hresult) {
-		return NewSimConnectError("SimConnect_SetSystemEventState", hresult, GetHRESULTMessage(hresult))
+// GetInputEvent requests the value of an input event (MSFS 2024); the simulator answers
+// with SIMCONNECT_RECV_GET_INPUT_EVENT
+// Implements SimConnect_GetInputEvent function
+func (c *Client) GetInputEvent(requestID DataRequestID, hash uint64) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
 	}
 
-	return nil
+	c.ids.markUsed(IDRequest, uint32(requestID))
+
+	packet := SentPacket{Operation: "GetInputEvent", Detail: fmt.Sprintf("hash 0x%016X", hash), RequestID: uint32(requestID), InputEvent: hash}
+	return c.send(packet, func() error {
+		return c.transport.GetInputEvent(requestID, hash)
+	})
+}
+
+// SetInputEvent sets the value of an input event (MSFS 2024); value is a float64 for
+// SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE events or a string for SIMCONNECT_INPUT_EVENT_TYPE_STRING events
+// Implements SimConnect_SetInputEvent function
+func (c *Client) SetInputEvent(hash uint64, value interface{}) error {
+	return c.setInputEvent(nil, hash, value)
+}
+
+// setInputEvent is SetInputEvent recording owner with the sent packet
+func (c *Client) setInputEvent(owner interface{}, hash uint64, value interface{}) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case float64:
+		data = make([]byte, 8)
+		binary.LittleEndian.PutUint64(data, math.Float64bits(v))
+	case string:
+		data = append([]byte(v), 0) // NUL-terminated
+	default:
+		return fmt.Errorf("unsupported input event value type %T, use float64 or string", value)
+	}
+
+	packet := SentPacket{Operation: "SetInputEvent", Detail: fmt.Sprintf("hash 0x%016X", hash), InputEvent: hash, owner: owner}
+	return c.send(packet, func() error {
+		return c.transport.SetInputEvent(hash, data)
+	})
+}
+
+// SubscribeInputEvent subscribes to value changes of an input event (MSFS 2024), delivered
+// as SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT
+// Implements SimConnect_SubscribeInputEvent function
+func (c *Client) SubscribeInputEvent(hash uint64) error {
+	return c.subscribeInputEvent(nil, hash)
+}
+
+// subscribeInputEvent is SubscribeInputEvent recording owner with the sent packet
+func (c *Client) subscribeInputEvent(owner interface{}, hash uint64) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	packet := SentPacket{Operation: "SubscribeInputEvent", Detail: fmt.Sprintf("hash 0x%016X", hash), InputEvent: hash, owner: owner}
+	return c.send(packet, func() error {
+		return c.transport.SubscribeInputEvent(hash)
+	})
+}
+
+// UnsubscribeInputEvent stops value change notifications of an input event (MSFS 2024)
+// Implements SimConnect_UnsubscribeInputEvent function
+func (c *Client) UnsubscribeInputEvent(hash uint64) error {
+	return c.unsubscribeInputEvent(nil, hash)
+}
+
+// unsubscribeInputEvent is UnsubscribeInputEvent recording owner with the sent packet
+func (c *Client) unsubscribeInputEvent(owner interface{}, hash uint64) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	packet := SentPacket{Operation: "UnsubscribeInputEvent", Detail: fmt.Sprintf("hash 0x%016X", hash), InputEvent: hash, owner: owner}
+	return c.send(packet, func() error {
+		return c.transport.UnsubscribeInputEvent(hash)
+	})
+}
+
+// EnumerateInputEventParams requests the parameter types of an input event (MSFS 2024); the
+// simulator answers with SIMCONNECT_RECV_ENUMERATE_INPUT_EVENT_PARAMS
+// Implements SimConnect_EnumerateInputEventParams function
+func (c *Client) EnumerateInputEventParams(hash uint64) error {
+	if !c.IsOpen() {
+		return fmt.Errorf("client is not open")
+	}
+
+	packet := SentPacket{Operation: "EnumerateInputEventParams", Detail: fmt.Sprintf("hash 0x%016X", hash), InputEvent: hash}
+	return c.send(packet, func() error {
+		return c.transport.EnumerateInputEventParams(hash)
+	})
 }
 
 // GetSystemEvent retrieves the next system event from SimConnect
 // Returns nil if no event is available or the message is not an event
 func (c *Client) GetSystemEvent() (*SystemEventData, error) {
-	if !c.isOpen {
+	if !c.IsOpen() {
 		return nil, fmt.Errorf("client is not open")
 	}
 
@@ -0,0 +1,478 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// maxEventParams is the number of parameters TransmitClientEvent_EX1 carries
+const maxEventParams = 5
+
+// Priorities of the notification groups intercepted events are added to. Masking groups need
+// SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE or lower; Send and Forward transmit just below it.
+const (
+	observePriority = SIMCONNECT_GROUP_PRIORITY_DEFAULT
+	maskPriority    = SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE
+	forwardPriority = maskPriority + 1
+)
+
+// InterceptedEvent is a sim event received through a notification group
+type InterceptedEvent struct {
+	Name    string                           // Sim event name, e.g. "THROTTLE_SET"
+	EventID SIMCONNECT_CLIENT_EVENT_ID       // Client event ID the name is mapped to
+	GroupID SIMCONNECT_NOTIFICATION_GROUP_ID // Notification group that received the event
+	Data    [maxEventParams]uint32           // Event parameters, events with one parameter only set Data[0]
+	Masked  bool                             // Whether the event was withheld from the simulator
+}
+
+// EventCallback is a function type for intercepted event callbacks
+type EventCallback func(event InterceptedEvent)
+
+// intercept is an intercepted sim event
+type intercept struct {
+	callback EventCallback // Receives the events
+	mask     bool          // Withhold the event from the simulator
+	handler  HandlerID     // Dispatcher handler for the client event ID
+}
+
+// EventManager sends sim events (key events) such as GEAR_TOGGLE, AP_MASTER or COM_RADIO_SET_HZ by name
+// and intercepts the sim events triggered in the simulator, optionally masking them.
+// Each name is mapped to a client event ID from the client's registry the first time it is used,
+// and the mappings and notification groups are re-created when a Supervisor reconnects the client.
+type EventManager struct {
+	client       *Client                                   // SimConnect client
+	mutex        sync.RWMutex                              // Thread safety
+	events       map[string]SIMCONNECT_CLIENT_EVENT_ID     // Client event IDs by upper-case sim event name
+	names        map[SIMCONNECT_CLIENT_EVENT_ID]string     // Sim event names by client event ID
+	intercepts   map[SIMCONNECT_CLIENT_EVENT_ID]*intercept // Intercepted events by client event ID
+	observeGroup SIMCONNECT_NOTIFICATION_GROUP_ID          // Group of intercepted events that are not masked, 0 until used
+	maskGroup    SIMCONNECT_NOTIFICATION_GROUP_ID          // Group of masked events, 0 until used
+	closed       bool                                      // Close was called, the manager cannot be used anymore
+	onError      HandlerID                                 // Exception handler registered by NewEventManager
+	replayID     HandlerID                                 // Replay registered by NewEventManager
+	errorChan    chan error                                // Error notifications
+}
+
+// NewEventManager creates a new EventManager instance
+func NewEventManager(client *Client) *EventManager {
+	em := &EventManager{
+		client:     client,
+		events:     make(map[string]SIMCONNECT_CLIENT_EVENT_ID),
+		names:      make(map[SIMCONNECT_CLIENT_EVENT_ID]string),
+		intercepts: make(map[SIMCONNECT_CLIENT_EVENT_ID]*intercept),
+		errorChan:  make(chan error, 10), // Buffered channel for non-blocking errors
+	}
+
+	// Exceptions caused by our mappings and transmissions are reported on our error channel
+	em.onError = client.Dispatcher().HandleException(em.handleException)
+	// Mappings are re-created when a Supervisor reconnects the client
+	em.replayID = client.addReplay(em.replay)
+	return em
+}
+
+// Send transmits a sim event to the user aircraft. Without or with one parameter it uses
+// TransmitClientEvent, which every simulator supports; two to five parameters need
+// TransmitClientEvent_EX1 (MSFS only). Negative values are passed as uint32(int32(value)).
+func (em *EventManager) Send(name string, params ...uint32) error {
+	return em.SendToObject(SIMCONNECT_OBJECT_ID_USER, name, params...)
+}
+
+// SendToObject transmits a sim event to a simulation object, like Send does for the user aircraft.
+// Events are transmitted just below the priority of masking groups, like Forward, so the manager's
+// own masking group does not swallow them. Masking groups of other clients still receive them first.
+func (em *EventManager) SendToObject(objectID SIMCONNECT_OBJECT_ID, name string, params ...uint32) error {
+	if len(params) > maxEventParams {
+		return fmt.Errorf("event %s takes at most %d parameters, got %d", name, maxEventParams, len(params))
+	}
+
+	eventID, err := em.Map(name)
+	if err != nil {
+		return err
+	}
+
+	var data [maxEventParams]uint32
+	copy(data[:], params)
+	if err := em.transmit(objectID, eventID, forwardPriority, len(params), data); err != nil {
+		return fmt.Errorf("failed to send event %s: %v", name, err)
+	}
+	return nil
+}
+
+// Intercept delivers a sim event to callback whenever it occurs in the simulator, e.g. when the user
+// moves a throttle lever (THROTTLE_SET). With mask set the event is also withheld from the simulator;
+// Forward passes it on, possibly with changed parameters.
+// Callbacks run in order on the dispatcher's goroutine and must return quickly.
+func (em *EventManager) Intercept(name string, callback EventCallback, mask bool) error {
+	if callback == nil {
+		return fmt.Errorf("callback for event %s is nil", name)
+	}
+
+	eventID, err := em.Map(name)
+	if err != nil {
+		return err
+	}
+
+	em.mutex.Lock()
+	defer em.mutex.Unlock()
+
+	if _, exists := em.intercepts[eventID]; exists {
+		return fmt.Errorf("event %s is already intercepted", name)
+	}
+
+	groupID, priority, allocated := em.group(mask)
+	if err := em.addToGroup(groupID, priority, eventID, mask); err != nil {
+		if allocated {
+			em.releaseGroup(mask)
+		}
+		return fmt.Errorf("failed to intercept event %s: %v", name, err)
+	}
+
+	dispatcher := em.client.Dispatcher()
+	if len(em.intercepts) == 0 {
+		dispatcher.Start() // Intercepted events are delivered while any event is intercepted
+	}
+	em.intercepts[eventID] = &intercept{
+		callback: callback,
+		mask:     mask,
+		handler:  dispatcher.HandleEvent(eventID, em.handleEvent),
+	}
+	return nil
+}
+
+// RemoveIntercept stops intercepting a sim event; a masked event reaches the simulator again
+func (em *EventManager) RemoveIntercept(name string) error {
+	em.mutex.Lock()
+
+	eventID, exists := em.events[strings.ToUpper(name)]
+	entry, intercepted := em.intercepts[eventID]
+	if !exists || !intercepted {
+		em.mutex.Unlock()
+		return fmt.Errorf("event %s is not intercepted", name)
+	}
+
+	groupID := em.observeGroup
+	if entry.mask {
+		groupID = em.maskGroup
+	}
+	if err := em.client.RemoveClientEvent(groupID, eventID); err != nil {
+		em.mutex.Unlock()
+		return fmt.Errorf("failed to remove intercept of event %s: %v", name, err)
+	}
+
+	dispatcher := em.client.Dispatcher()
+	dispatcher.RemoveHandler(entry.handler)
+	delete(em.intercepts, eventID)
+	last := len(em.intercepts) == 0
+	em.mutex.Unlock()
+
+	// Released outside the lock, a handler may still be waiting for it
+	if last {
+		dispatcher.Stop()
+	}
+	return nil
+}
+
+// Forward passes an intercepted event on with its parameters, e.g. after changing event.Data.
+// It is transmitted just below the priority of masking groups, so it is not intercepted again
+// and reaches lower priority groups and the simulator.
+func (em *EventManager) Forward(event InterceptedEvent) error {
+	em.mutex.RLock()
+	closed := em.closed
+	em.mutex.RUnlock()
+	if closed {
+		return fmt.Errorf("event manager is closed")
+	}
+
+	params := 1
+	for i := maxEventParams - 1; i > 0; i-- {
+		if event.Data[i] != 0 {
+			params = i + 1
+			break
+		}
+	}
+
+	if err := em.transmit(SIMCONNECT_OBJECT_ID_USER, event.EventID, forwardPriority, params, event.Data); err != nil {
+		return fmt.Errorf("failed to forward event %s: %v", event.Name, err)
+	}
+	return nil
+}
+
+// GetIntercepts returns the intercepted sim event names and whether they are masked
+func (em *EventManager) GetIntercepts() map[string]bool {
+	em.mutex.RLock()
+	defer em.mutex.RUnlock()
+
+	result := make(map[string]bool, len(em.intercepts))
+	for eventID, entry := range em.intercepts {
+		result[em.names[eventID]] = entry.mask
+	}
+	return result
+}
+
+// Map returns the client event ID of a sim event, mapping it with MapClientEventToSimEvent the
+// first time. Send maps events on demand; Map maps them up front. Names are case-insensitive.
+func (em *EventManager) Map(name string) (SIMCONNECT_CLIENT_EVENT_ID, error) {
+	if name == "" {
+		return 0, fmt.Errorf("event name is empty")
+	}
+	key := strings.ToUpper(name)
+
+	em.mutex.RLock()
+	eventID, exists := em.events[key]
+	closed := em.closed
+	em.mutex.RUnlock()
+	if closed {
+		return 0, fmt.Errorf("event manager is closed")
+	}
+	if exists {
+		return eventID, nil
+	}
+
+	em.mutex.Lock()
+	defer em.mutex.Unlock()
+
+	if em.closed {
+		return 0, fmt.Errorf("event manager is closed") // Closed in the meantime
+	}
+
+	// Mapped by another caller in the meantime
+	if eventID, exists := em.events[key]; exists {
+		return eventID, nil
+	}
+
+	// Assign new event ID from the client's registry
+	eventID = em.client.IDs().NewEventID()
+	if err := em.client.MapClientEventToSimEvent(eventID, name); err != nil {
+		em.client.IDs().Release(IDEvent, uint32(eventID))
+		return 0, fmt.Errorf("failed to map event %s: %v", name, err)
+	}
+
+	em.events[key] = eventID
+	em.names[eventID] = name
+	return eventID, nil
+}
+
+// GetMappedEvents returns the mapped sim event names by client event ID
+func (em *EventManager) GetMappedEvents() map[SIMCONNECT_CLIENT_EVENT_ID]string {
+	em.mutex.RLock()
+	defer em.mutex.RUnlock()
+
+	result := make(map[SIMCONNECT_CLIENT_EVENT_ID]string, len(em.names))
+	for eventID, name := range em.names {
+		result[eventID] = name
+	}
+	return result
+}
+
+// Close removes all intercepts, clears the manager's notification groups in SimConnect, returns
+// its event and group IDs to the registry and unregisters its exception handler and replay from
+// the client. A closed manager cannot be used anymore; closing it twice is a no-op. Close must not
+// be called from a dispatcher handler.
+func (em *EventManager) Close() error {
+	em.mutex.Lock()
+
+	if em.closed {
+		em.mutex.Unlock()
+		return nil
+	}
+	em.closed = true
+
+	dispatcher := em.client.Dispatcher()
+	dispatcher.RemoveHandler(em.onError)
+	em.client.removeReplay(em.replayID)
+
+	// Without a connection there is nothing to clear, SimConnect dropped the groups with it
+	var result error
+	for _, mask := range []bool{false, true} {
+		groupID := em.observeGroup
+		if mask {
+			groupID = em.maskGroup
+		}
+		if groupID == 0 {
+			continue // Never used
+		}
+		if em.client.IsOpen() {
+			if err := em.client.ClearNotificationGroup(groupID); err != nil && result == nil {
+				result = fmt.Errorf("failed to clear notification group %d: %v", groupID, err)
+			}
+		}
+		em.releaseGroup(mask)
+	}
+
+	intercepted := len(em.intercepts) > 0
+	for _, entry := range em.intercepts {
+		dispatcher.RemoveHandler(entry.handler)
+	}
+	for eventID := range em.names {
+		em.client.IDs().Release(IDEvent, uint32(eventID))
+	}
+	em.intercepts = make(map[SIMCONNECT_CLIENT_EVENT_ID]*intercept)
+	em.events = make(map[string]SIMCONNECT_CLIENT_EVENT_ID)
+	em.names = make(map[SIMCONNECT_CLIENT_EVENT_ID]string)
+	em.mutex.Unlock()
+
+	// Released outside the lock, a handler may still be waiting for it
+	if intercepted {
+		dispatcher.Stop()
+	}
+	return result
+}
+
+// GetErrors returns the error channel for monitoring runtime errors
+func (em *EventManager) GetErrors() <-chan error {
+	return em.errorChan
+}
+
+// replay re-maps all events and re-creates the notification groups on a new connection
+func (em *EventManager) replay() error {
+	em.mutex.RLock()
+	defer em.mutex.RUnlock()
+
+	for eventID, name := range em.names {
+		if err := em.client.MapClientEventToSimEvent(eventID, name); err != nil {
+			return fmt.Errorf("failed to re-map event %s: %v", name, err)
+		}
+	}
+
+	for eventID, entry := range em.intercepts {
+		groupID, priority, _ := em.group(entry.mask)
+		if err := em.addToGroup(groupID, priority, eventID, entry.mask); err != nil {
+			return fmt.Errorf("failed to re-intercept event %s: %v", em.names[eventID], err)
+		}
+	}
+	return nil
+}
+
+// group returns the notification group and priority for masked or observed events,
+// allocating the group ID on first use. The caller holds the write lock, or the read
+// lock when the group is known to exist.
+func (em *EventManager) group(mask bool) (SIMCONNECT_NOTIFICATION_GROUP_ID, SIMCONNECT_GROUP_PRIORITY, bool) {
+	groupID, priority := &em.observeGroup, observePriority
+	if mask {
+		groupID, priority = &em.maskGroup, maskPriority
+	}
+
+	if *groupID != 0 {
+		return *groupID, priority, false
+	}
+	*groupID = em.client.IDs().NewNotificationGroupID()
+	return *groupID, priority, true
+}
+
+// releaseGroup returns an unused group ID to the registry; the caller holds the write lock
+func (em *EventManager) releaseGroup(mask bool) {
+	groupID := &em.observeGroup
+	if mask {
+		groupID = &em.maskGroup
+	}
+	em.client.IDs().Release(IDNotificationGroup, uint32(*groupID))
+	*groupID = 0
+}
+
+// addToGroup adds a client event to a notification group and sets the group priority,
+// SimConnect creates groups with their first event
+func (em *EventManager) addToGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY, eventID SIMCONNECT_CLIENT_EVENT_ID, mask bool) error {
+	if err := em.client.AddClientEventToNotificationGroup(groupID, eventID, mask); err != nil {
+		return err
+	}
+	return em.client.SetNotificationGroupPriority(groupID, priority)
+}
+
+// transmit sends a client event at the given priority, using TransmitClientEvent_EX1
+// only for events with more than one parameter
+func (em *EventManager) transmit(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, priority SIMCONNECT_GROUP_PRIORITY, params int, data [maxEventParams]uint32) error {
+	groupID := SIMCONNECT_NOTIFICATION_GROUP_ID(priority)
+	flags := SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY
+
+	if params <= 1 {
+		return em.client.TransmitClientEvent(objectID, eventID, data[0], groupID, flags)
+	}
+	return em.client.TransmitClientEvent_EX1(objectID, eventID, groupID, flags, data[0], data[1], data[2], data[3], data[4])
+}
+
+// handleEvent is the dispatcher handler for intercepted events
+func (em *EventManager) handleEvent(data []byte) {
+	msgType, err := ParseMessageType(data)
+	if err != nil {
+		em.reportError(fmt.Errorf("error parsing message type: %v", err))
+		return
+	}
+
+	var event InterceptedEvent
+	switch msgType {
+	case SIMCONNECT_RECV_ID_EVENT:
+		recv, err := ParseEvent(data)
+		if err != nil {
+			em.reportError(fmt.Errorf("error parsing event data: %v", err))
+			return
+		}
+		event.EventID = SIMCONNECT_CLIENT_EVENT_ID(recv.EventID)
+		event.GroupID = SIMCONNECT_NOTIFICATION_GROUP_ID(recv.GroupID)
+		event.Data[0] = recv.Data
+	case SIMCONNECT_RECV_ID_EVENT_EX1:
+		recv, err := ParseEventEx1(data)
+		if err != nil {
+			em.reportError(fmt.Errorf("error parsing event data: %v", err))
+			return
+		}
+		event.EventID = SIMCONNECT_CLIENT_EVENT_ID(recv.EventID)
+		event.GroupID = SIMCONNECT_NOTIFICATION_GROUP_ID(recv.GroupID)
+		event.Data = recv.Data
+	default:
+		return // Not a key event
+	}
+
+	em.mutex.RLock()
+	entry, exists := em.intercepts[event.EventID]
+	event.Name = em.names[event.EventID]
+	em.mutex.RUnlock()
+
+	if !exists {
+		return // Removed in the meantime
+	}
+	event.Masked = entry.mask
+
+	// Called in order on the dispatcher goroutine, panics are reported on our error channel
+	defer func() {
+		if r := recover(); r != nil {
+			em.reportError(fmt.Errorf("event callback panic: %v", r))
+		}
+	}()
+	entry.callback(event)
+}
+
+// handleException claims exceptions caused by this manager's events and notification groups.
+// A mapping SimConnect refused (e.g. an unknown event name) is forgotten, so the next Send maps
+// the name again; intercepted events keep their mapping until RemoveIntercept.
+func (em *EventManager) handleException(err *ExceptionError) bool {
+	if err.Packet == nil || (err.Packet.EventID == 0 && err.Packet.GroupID == 0) {
+		return false
+	}
+
+	em.mutex.Lock()
+	name, owned := em.names[err.Packet.EventID]
+	if err.Packet.EventID == 0 {
+		owned = err.Packet.GroupID == em.observeGroup || err.Packet.GroupID == em.maskGroup
+	}
+	_, intercepted := em.intercepts[err.Packet.EventID]
+	if owned && !intercepted && err.Packet.Operation == "MapClientEventToSimEvent" {
+		delete(em.events, strings.ToUpper(name))
+		delete(em.names, err.Packet.EventID)
+		em.client.IDs().Release(IDEvent, uint32(err.Packet.EventID))
+	}
+	em.mutex.Unlock()
+
+	if owned {
+		em.reportError(err)
+	}
+	return owned
+}
+
+// reportError sends an error to the error channel without blocking
+func (em *EventManager) reportError(err error) {
+	select {
+	case em.errorChan <- err:
+	default: // Channel full, skip this error
+	}
+}
@@ -0,0 +1,119 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+func TestEventManagerSendsBelowMaskingGroups(t *testing.T) {
+	server := simtest.NewServer()
+	simClient := openClient(t, server)
+
+	events := client.NewEventManager(simClient)
+	if err := events.Intercept("GEAR_TOGGLE", func(client.InterceptedEvent) {}, true); err != nil {
+		t.Fatalf("Intercept: %v", err)
+	}
+	defer events.RemoveIntercept("GEAR_TOGGLE")
+
+	var maskPriority client.SIMCONNECT_GROUP_PRIORITY
+	for _, group := range server.NotificationGroups() {
+		maskPriority = group.Priority
+	}
+	if maskPriority == 0 {
+		t.Fatal("no notification group for the masked event")
+	}
+
+	if err := events.Send("GEAR_TOGGLE"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	transmitted := server.TransmittedEvents()
+	if len(transmitted) != 1 {
+		t.Fatalf("%d events transmitted, want 1", len(transmitted))
+	}
+	sent := transmitted[0]
+	if sent.Flags&client.SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY == 0 {
+		t.Fatalf("event sent to group %d instead of a priority", sent.GroupID)
+	}
+	// Lower priorities have higher values; the masking group must not receive the event
+	if priority := client.SIMCONNECT_GROUP_PRIORITY(sent.GroupID); priority <= maskPriority {
+		t.Errorf("event sent at priority %d, the masking group at %d would withhold it", priority, maskPriority)
+	}
+}
+
+func TestEventManagerClose(t *testing.T) {
+	server := simtest.NewServer()
+	simClient := openClient(t, server)
+
+	events := client.NewEventManager(simClient)
+	if err := events.Intercept("GEAR_TOGGLE", func(client.InterceptedEvent) {}, true); err != nil {
+		t.Fatalf("Intercept: %v", err)
+	}
+	if err := events.Intercept("FLAPS_INCR", func(client.InterceptedEvent) {}, false); err != nil {
+		t.Fatalf("Intercept: %v", err)
+	}
+	if err := events.Send("AP_MASTER"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	mapped := events.GetMappedEvents()
+	var groupIDs []client.SIMCONNECT_NOTIFICATION_GROUP_ID
+	for groupID := range server.NotificationGroups() {
+		groupIDs = append(groupIDs, groupID)
+	}
+	if len(groupIDs) != 2 {
+		t.Fatalf("%d notification groups, want 2", len(groupIDs))
+	}
+
+	if err := events.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if groups := server.NotificationGroups(); len(groups) != 0 {
+		t.Errorf("notification groups %v still registered after Close", groups)
+	}
+	for eventID, name := range mapped {
+		if simClient.IDs().InUse(client.IDEvent, uint32(eventID)) {
+			t.Errorf("event ID %d of %s still in use after Close", eventID, name)
+		}
+	}
+	for _, groupID := range groupIDs {
+		if simClient.IDs().InUse(client.IDNotificationGroup, uint32(groupID)) {
+			t.Errorf("group ID %d still in use after Close", groupID)
+		}
+	}
+	if intercepts := events.GetIntercepts(); len(intercepts) != 0 {
+		t.Errorf("intercepts %v left after Close", intercepts)
+	}
+	if err := events.Send("AP_MASTER"); err == nil {
+		t.Error("Send after Close succeeded")
+	}
+	if err := events.Intercept("GEAR_TOGGLE", func(client.InterceptedEvent) {}, false); err == nil {
+		t.Error("Intercept after Close succeeded")
+	}
+	if err := events.Forward(client.InterceptedEvent{Name: "GEAR_TOGGLE", EventID: 1}); err == nil {
+		t.Error("Forward after Close succeeded")
+	}
+	if err := events.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// A reconnect must not replay the closed manager
+	simClient.Dispatcher().Start()
+	defer simClient.Dispatcher().Stop()
+	supervisor := client.NewSupervisor(simClient, client.ReconnectConfig{InitialDelay: time.Millisecond})
+	if err := supervisor.Start(); err != nil {
+		t.Fatalf("supervisor Start: %v", err)
+	}
+	defer supervisor.Stop()
+
+	server.Quit()
+	waitFor(t, "reconnect", func() bool {
+		reconnects, _ := supervisor.GetStats()
+		return reconnects == 1
+	})
+	if events := server.ClientEvents(); len(events) != 0 {
+		t.Errorf("closed manager re-mapped %v after reconnect", events)
+	}
+}
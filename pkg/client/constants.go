@@ -1,5 +1,7 @@
 package client
 
+import "fmt"
+
 // System state constants for RequestSystemState function
 // These match the values documented in the SimConnect API reference
 const (
@@ -35,8 +37,46 @@ const (
 	SIMCONNECT_DATATYPE_STRING256 SIMCONNECT_DATATYPE = 9
 	SIMCONNECT_DATATYPE_STRING260 SIMCONNECT_DATATYPE = 10
 	SIMCONNECT_DATATYPE_STRINGV   SIMCONNECT_DATATYPE = 11
+
+	// SimConnect structures, see SIMCONNECT_DATA_* in messages.go
+	SIMCONNECT_DATATYPE_INITPOSITION SIMCONNECT_DATATYPE = 12
+	SIMCONNECT_DATATYPE_MARKERSTATE  SIMCONNECT_DATATYPE = 13
+	SIMCONNECT_DATATYPE_WAYPOINT     SIMCONNECT_DATATYPE = 14
+	SIMCONNECT_DATATYPE_LATLONALT    SIMCONNECT_DATATYPE = 15
+	SIMCONNECT_DATATYPE_XYZ          SIMCONNECT_DATATYPE = 16
+	SIMCONNECT_DATATYPE_PBH          SIMCONNECT_DATATYPE = 17
 )
 
+// dataTypeNames maps data types to their SimConnect names without the SIMCONNECT_DATATYPE_ prefix
+var dataTypeNames = map[SIMCONNECT_DATATYPE]string{
+	SIMCONNECT_DATATYPE_INVALID:      "INVALID",
+	SIMCONNECT_DATATYPE_INT32:        "INT32",
+	SIMCONNECT_DATATYPE_INT64:        "INT64",
+	SIMCONNECT_DATATYPE_FLOAT32:      "FLOAT32",
+	SIMCONNECT_DATATYPE_FLOAT64:      "FLOAT64",
+	SIMCONNECT_DATATYPE_STRING8:      "STRING8",
+	SIMCONNECT_DATATYPE_STRING32:     "STRING32",
+	SIMCONNECT_DATATYPE_STRING64:     "STRING64",
+	SIMCONNECT_DATATYPE_STRING128:    "STRING128",
+	SIMCONNECT_DATATYPE_STRING256:    "STRING256",
+	SIMCONNECT_DATATYPE_STRING260:    "STRING260",
+	SIMCONNECT_DATATYPE_STRINGV:      "STRINGV",
+	SIMCONNECT_DATATYPE_INITPOSITION: "INITPOSITION",
+	SIMCONNECT_DATATYPE_MARKERSTATE:  "MARKERSTATE",
+	SIMCONNECT_DATATYPE_WAYPOINT:     "WAYPOINT",
+	SIMCONNECT_DATATYPE_LATLONALT:    "LATLONALT",
+	SIMCONNECT_DATATYPE_XYZ:          "XYZ",
+	SIMCONNECT_DATATYPE_PBH:          "PBH",
+}
+
+// String returns the SimConnect name of the data type, e.g. "FLOAT64"
+func (t SIMCONNECT_DATATYPE) String() string {
+	if name, exists := dataTypeNames[t]; exists {
+		return name
+	}
+	return fmt.Sprintf("DATATYPE_%d", uint32(t))
+}
+
 // SimConnect data request periods
 type SIMCONNECT_PERIOD uint32
 
@@ -79,6 +119,43 @@ const (
 // SimConnect client event ID type for system events
 type SIMCONNECT_CLIENT_EVENT_ID uint32
 
+// SIMCONNECT_UNUSED marks an optional ID argument as not used, e.g. the up event of MapInputEventToClientEvent
+const SIMCONNECT_UNUSED = 0xFFFFFFFF
+
+// SimConnect event flags for TransmitClientEvent
+type SIMCONNECT_EVENT_FLAG uint32
+
+const (
+	SIMCONNECT_EVENT_FLAG_DEFAULT             SIMCONNECT_EVENT_FLAG = 0x00
+	SIMCONNECT_EVENT_FLAG_FAST_REPEAT_TIMER   SIMCONNECT_EVENT_FLAG = 0x01 // Simulate a key held down with the fast repeat rate
+	SIMCONNECT_EVENT_FLAG_SLOW_REPEAT_TIMER   SIMCONNECT_EVENT_FLAG = 0x02 // Simulate a key held down with the slow repeat rate
+	SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY SIMCONNECT_EVENT_FLAG = 0x10 // The group ID is a SIMCONNECT_GROUP_PRIORITY
+)
+
+// SimConnect notification group priorities; lower values are served first
+type SIMCONNECT_GROUP_PRIORITY uint32
+
+const (
+	SIMCONNECT_GROUP_PRIORITY_HIGHEST          SIMCONNECT_GROUP_PRIORITY = 1          // Highest priority
+	SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE SIMCONNECT_GROUP_PRIORITY = 10000000   // Highest priority that can mask events
+	SIMCONNECT_GROUP_PRIORITY_STANDARD         SIMCONNECT_GROUP_PRIORITY = 1900000000 // Standard priority
+	SIMCONNECT_GROUP_PRIORITY_DEFAULT          SIMCONNECT_GROUP_PRIORITY = 2000000000 // Default priority
+	SIMCONNECT_GROUP_PRIORITY_LOWEST           SIMCONNECT_GROUP_PRIORITY = 4000000000 // Lowest priority, events can still be received but not masked
+)
+
+// SimConnect group and client data ID types
+type SIMCONNECT_NOTIFICATION_GROUP_ID uint32
+type SIMCONNECT_INPUT_GROUP_ID uint32
+type SIMCONNECT_CLIENT_DATA_ID uint32
+
+// SimConnect input event value types (MSFS 2024)
+type SIMCONNECT_INPUT_EVENT_TYPE uint32
+
+const (
+	SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE SIMCONNECT_INPUT_EVENT_TYPE = 0
+	SIMCONNECT_INPUT_EVENT_TYPE_STRING SIMCONNECT_INPUT_EVENT_TYPE = 1
+)
+
 // SimConnect system event state
 type SIMCONNECT_STATE uint32
 
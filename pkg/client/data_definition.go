@@ -0,0 +1,733 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// simvarTag is the struct tag describing a field's simvar: `simvar:"NAME,units,datatype"`
+const simvarTag = "simvar"
+
+// structDataTypes maps the SimConnect structures to their data type
+var structDataTypes = map[reflect.Type]SIMCONNECT_DATATYPE{
+	reflect.TypeOf(SIMCONNECT_DATA_INITPOSITION{}): SIMCONNECT_DATATYPE_INITPOSITION,
+	reflect.TypeOf(SIMCONNECT_DATA_MARKERSTATE{}):  SIMCONNECT_DATATYPE_MARKERSTATE,
+	reflect.TypeOf(SIMCONNECT_DATA_WAYPOINT{}):     SIMCONNECT_DATATYPE_WAYPOINT,
+	reflect.TypeOf(SIMCONNECT_DATA_LATLONALT{}):    SIMCONNECT_DATATYPE_LATLONALT,
+	reflect.TypeOf(SIMCONNECT_DATA_XYZ{}):          SIMCONNECT_DATATYPE_XYZ,
+	reflect.TypeOf(SIMCONNECT_DATA_PBH{}):          SIMCONNECT_DATATYPE_PBH,
+}
+
+// DataTypeSize returns the size of a data type in SIMOBJECT_DATA messages, or 0 for STRINGV
+func DataTypeSize(dataType SIMCONNECT_DATATYPE) (int, error) {
+	switch dataType {
+	case SIMCONNECT_DATATYPE_INT32, SIMCONNECT_DATATYPE_FLOAT32:
+		return 4, nil
+	case SIMCONNECT_DATATYPE_INT64, SIMCONNECT_DATATYPE_FLOAT64, SIMCONNECT_DATATYPE_STRING8:
+		return 8, nil
+	case SIMCONNECT_DATATYPE_STRING32:
+		return 32, nil
+	case SIMCONNECT_DATATYPE_STRING64:
+		return 64, nil
+	case SIMCONNECT_DATATYPE_STRING128:
+		return 128, nil
+	case SIMCONNECT_DATATYPE_STRING256:
+		return 256, nil
+	case SIMCONNECT_DATATYPE_STRING260:
+		return 260, nil
+	case SIMCONNECT_DATATYPE_STRINGV:
+		return 0, nil
+	case SIMCONNECT_DATATYPE_INITPOSITION:
+		return binary.Size(SIMCONNECT_DATA_INITPOSITION{}), nil
+	case SIMCONNECT_DATATYPE_MARKERSTATE:
+		return binary.Size(SIMCONNECT_DATA_MARKERSTATE{}), nil
+	case SIMCONNECT_DATATYPE_WAYPOINT:
+		return binary.Size(SIMCONNECT_DATA_WAYPOINT{}), nil
+	case SIMCONNECT_DATATYPE_LATLONALT:
+		return binary.Size(SIMCONNECT_DATA_LATLONALT{}), nil
+	case SIMCONNECT_DATATYPE_XYZ:
+		return binary.Size(SIMCONNECT_DATA_XYZ{}), nil
+	case SIMCONNECT_DATATYPE_PBH:
+		return binary.Size(SIMCONNECT_DATA_PBH{}), nil
+	default:
+		return 0, fmt.Errorf("unsupported data type %s", dataType)
+	}
+}
+
+// dataField is one simvar-tagged field of a struct
+type dataField struct {
+	index    []int               // Field index for reflect.Value.FieldByIndex
+	name     string              // Go field name
+	simVar   string              // SimConnect variable name
+	units    string              // Units of measurement, empty for strings and structures
+	dataType SIMCONNECT_DATATYPE // Type of the datum in SimConnect messages
+	size     int                 // Encoded size, 0 for STRINGV
+}
+
+// dataLayout is the SimConnect data definition computed from a struct type
+type dataLayout struct {
+	typ    reflect.Type
+	fields []dataField
+}
+
+// newDataLayout reads the simvar tags of a struct type; fields of embedded structs are included
+func newDataLayout(typ reflect.Type) (*dataLayout, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", typ)
+	}
+
+	layout := &dataLayout{typ: typ}
+	if err := layout.addFields(typ, nil); err != nil {
+		return nil, fmt.Errorf("%s: %v", typ, err)
+	}
+	if len(layout.fields) == 0 {
+		return nil, fmt.Errorf("%s has no fields with a %s tag", typ, simvarTag)
+	}
+	return layout, nil
+}
+
+// addFields appends the tagged fields of typ, found at index within the layout's struct
+func (l *dataLayout) addFields(typ reflect.Type, index []int) error {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		tag, tagged := field.Tag.Lookup(simvarTag)
+		if !tagged {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := l.addFields(field.Type, fieldIndex); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		if !field.IsExported() {
+			return fmt.Errorf("field %s has a %s tag but is not exported", field.Name, simvarTag)
+		}
+
+		parsed, err := parseDataField(field, tag)
+		if err != nil {
+			return err
+		}
+		parsed.index = fieldIndex
+		l.fields = append(l.fields, parsed)
+	}
+	return nil
+}
+
+// parseDataField parses a simvar tag; the data type defaults to the one matching the field's Go type
+func parseDataField(field reflect.StructField, tag string) (dataField, error) {
+	parts := strings.Split(tag, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	if len(parts) > 3 || parts[0] == "" {
+		return dataField{}, fmt.Errorf("field %s: invalid %s tag '%s', want \"NAME,units,datatype\"", field.Name, simvarTag, tag)
+	}
+
+	parsed := dataField{name: field.Name, simVar: parts[0]}
+	if len(parts) > 1 {
+		parsed.units = parts[1]
+	}
+
+	if len(parts) > 2 && parts[2] != "" {
+		dataType, ok := parseDataType(parts[2])
+		if !ok {
+			return dataField{}, fmt.Errorf("field %s: unknown data type '%s'", field.Name, parts[2])
+		}
+		parsed.dataType = dataType
+	} else {
+		dataType, ok := defaultDataType(field.Type)
+		if !ok {
+			return dataField{}, fmt.Errorf("field %s: no default data type for %s, set one in the tag", field.Name, field.Type)
+		}
+		parsed.dataType = dataType
+	}
+
+	if !canDecode(parsed.dataType, field.Type) {
+		return dataField{}, fmt.Errorf("field %s: cannot decode %s into %s", field.Name, parsed.dataType, field.Type)
+	}
+
+	parsed.size, _ = DataTypeSize(parsed.dataType)
+	return parsed, nil
+}
+
+// parseDataType looks up a data type by its case-insensitive name, e.g. "float64" or "string256"
+func parseDataType(name string) (SIMCONNECT_DATATYPE, bool) {
+	for dataType, dataTypeName := range dataTypeNames {
+		if dataType != SIMCONNECT_DATATYPE_INVALID && strings.EqualFold(name, dataTypeName) {
+			return dataType, true
+		}
+	}
+	return SIMCONNECT_DATATYPE_INVALID, false
+}
+
+// defaultDataType returns the data type used for a Go type when the tag does not name one
+func defaultDataType(typ reflect.Type) (SIMCONNECT_DATATYPE, bool) {
+	if dataType, exists := structDataTypes[typ]; exists {
+		return dataType, true
+	}
+
+	switch typ.Kind() {
+	case reflect.Float64:
+		return SIMCONNECT_DATATYPE_FLOAT64, true
+	case reflect.Float32:
+		return SIMCONNECT_DATATYPE_FLOAT32, true
+	case reflect.Int64, reflect.Uint64:
+		return SIMCONNECT_DATATYPE_INT64, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Bool:
+		return SIMCONNECT_DATATYPE_INT32, true
+	case reflect.String:
+		return SIMCONNECT_DATATYPE_STRING256, true
+	case reflect.Array:
+		if typ.Elem().Kind() != reflect.Uint8 {
+			return SIMCONNECT_DATATYPE_INVALID, false
+		}
+		for _, dataType := range []SIMCONNECT_DATATYPE{
+			SIMCONNECT_DATATYPE_STRING8, SIMCONNECT_DATATYPE_STRING32, SIMCONNECT_DATATYPE_STRING64,
+			SIMCONNECT_DATATYPE_STRING128, SIMCONNECT_DATATYPE_STRING256, SIMCONNECT_DATATYPE_STRING260,
+		} {
+			if size, _ := DataTypeSize(dataType); size == typ.Len() {
+				return dataType, true
+			}
+		}
+	}
+	return SIMCONNECT_DATATYPE_INVALID, false
+}
+
+// canDecode reports whether values of dataType can be stored in a field of typ
+func canDecode(dataType SIMCONNECT_DATATYPE, typ reflect.Type) bool {
+	switch dataType {
+	case SIMCONNECT_DATATYPE_INT32, SIMCONNECT_DATATYPE_INT64, SIMCONNECT_DATATYPE_FLOAT32, SIMCONNECT_DATATYPE_FLOAT64:
+		switch typ.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64, reflect.Bool:
+			return true
+		}
+		return false
+	case SIMCONNECT_DATATYPE_STRING8, SIMCONNECT_DATATYPE_STRING32, SIMCONNECT_DATATYPE_STRING64,
+		SIMCONNECT_DATATYPE_STRING128, SIMCONNECT_DATATYPE_STRING256, SIMCONNECT_DATATYPE_STRING260,
+		SIMCONNECT_DATATYPE_STRINGV:
+		return typ.Kind() == reflect.String || (typ.Kind() == reflect.Array && typ.Elem().Kind() == reflect.Uint8)
+	default:
+		structType, exists := structDataTypes[typ]
+		return exists && structType == dataType
+	}
+}
+
+// decode reads the data of a SIMOBJECT_DATA message into target, a settable struct of the layout's type
+func (l *dataLayout) decode(data []byte, target reflect.Value) error {
+	offset := 0
+	for _, field := range l.fields {
+		size, err := decodeDatum(field.dataType, field.size, data[offset:], target.FieldByIndex(field.index))
+		if err != nil {
+			return newMessageError(SIMCONNECT_RECV_ID_SIMOBJECT_DATA, ErrMessageTruncated,
+				"field %s (%s) at offset %d: %v", field.name, field.simVar, offset, err)
+		}
+		offset += size
+	}
+	return nil
+}
+
+// decodeDatum stores one datum of dataType from data in value and returns its encoded size
+func decodeDatum(dataType SIMCONNECT_DATATYPE, size int, data []byte, value reflect.Value) (int, error) {
+	if dataType == SIMCONNECT_DATATYPE_STRINGV {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			return 0, fmt.Errorf("unterminated STRINGV value")
+		}
+		setString(value, data[:end])
+		return end + 1, nil
+	}
+
+	if len(data) < size {
+		return 0, fmt.Errorf("%s value needs %d bytes, have %d", dataType, size, len(data))
+	}
+
+	switch dataType {
+	case SIMCONNECT_DATATYPE_INT32:
+		setInt(value, int64(int32(binary.LittleEndian.Uint32(data))))
+	case SIMCONNECT_DATATYPE_INT64:
+		setInt(value, int64(binary.LittleEndian.Uint64(data)))
+	case SIMCONNECT_DATATYPE_FLOAT32:
+		setFloat(value, float64(math.Float32frombits(binary.LittleEndian.Uint32(data))))
+	case SIMCONNECT_DATATYPE_FLOAT64:
+		setFloat(value, math.Float64frombits(binary.LittleEndian.Uint64(data)))
+	case SIMCONNECT_DATATYPE_STRING8, SIMCONNECT_DATATYPE_STRING32, SIMCONNECT_DATATYPE_STRING64,
+		SIMCONNECT_DATATYPE_STRING128, SIMCONNECT_DATATYPE_STRING256, SIMCONNECT_DATATYPE_STRING260:
+		field := data[:size]
+		if end := bytes.IndexByte(field, 0); end >= 0 {
+			field = field[:end]
+		}
+		setString(value, field)
+	default:
+		// SimConnect structures are packed, like binary.Decode expects
+		if _, err := binary.Decode(data[:size], binary.LittleEndian, value.Addr().Interface()); err != nil {
+			return 0, err
+		}
+	}
+	return size, nil
+}
+
+// isStringDataType reports whether dataType is one of the fixed or variable length strings
+func isStringDataType(dataType SIMCONNECT_DATATYPE) bool {
+	return dataType >= SIMCONNECT_DATATYPE_STRING8 && dataType <= SIMCONNECT_DATATYPE_STRINGV
+}
+
+// datumGoType returns the Go type of a data type's values: int32, int64, float32, float64,
+// string or a SIMCONNECT_DATA_* structure
+func datumGoType(dataType SIMCONNECT_DATATYPE) (reflect.Type, error) {
+	switch dataType {
+	case SIMCONNECT_DATATYPE_INT32:
+		return reflect.TypeOf(int32(0)), nil
+	case SIMCONNECT_DATATYPE_INT64:
+		return reflect.TypeOf(int64(0)), nil
+	case SIMCONNECT_DATATYPE_FLOAT32:
+		return reflect.TypeOf(float32(0)), nil
+	case SIMCONNECT_DATATYPE_FLOAT64:
+		return reflect.TypeOf(float64(0)), nil
+	}
+	if isStringDataType(dataType) {
+		return reflect.TypeOf(""), nil
+	}
+	for typ, structType := range structDataTypes {
+		if structType == dataType {
+			return typ, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported data type %s", dataType)
+}
+
+// readDatum decodes one datum of dataType into a value of its Go type and returns its encoded size
+func readDatum(dataType SIMCONNECT_DATATYPE, data []byte) (interface{}, int, error) {
+	typ, err := datumGoType(dataType)
+	if err != nil {
+		return nil, 0, err
+	}
+	size, _ := DataTypeSize(dataType)
+
+	value := reflect.New(typ).Elem()
+	n, err := decodeDatum(dataType, size, data, value)
+	if err != nil {
+		return nil, 0, err
+	}
+	return value.Interface(), n, nil
+}
+
+// appendDatum encodes value as dataType for SetDataOnSimObject. Numbers and booleans convert
+// between the numeric types, strings are NUL-padded to fixed sizes and structures must match exactly.
+func appendDatum(buf []byte, dataType SIMCONNECT_DATATYPE, value interface{}) ([]byte, error) {
+	if isStringDataType(dataType) {
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s needs a string, not %T", dataType, value)
+		}
+		size, _ := DataTypeSize(dataType)
+		if size == 0 {
+			return append(append(buf, text...), 0), nil // STRINGV
+		}
+		if len(text) >= size {
+			return nil, fmt.Errorf("string of %d bytes does not fit %s", len(text), dataType)
+		}
+		field := make([]byte, size) // Keeps at least one terminating NUL
+		copy(field, text)
+		return append(buf, field...), nil
+	}
+
+	if structType, exists := structDataTypes[reflect.TypeOf(value)]; exists {
+		if structType != dataType {
+			return nil, fmt.Errorf("%s needs a %s value, not %T", dataType, dataType, value)
+		}
+		return binary.Append(buf, binary.LittleEndian, value)
+	}
+
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case float32:
+		number = float64(v)
+	case int:
+		number = float64(v)
+	case int32:
+		number = float64(v)
+	case int64:
+		// INT64 is written directly to keep all 64 bits
+		if dataType == SIMCONNECT_DATATYPE_INT64 {
+			return binary.LittleEndian.AppendUint64(buf, uint64(v)), nil
+		}
+		number = float64(v)
+	case bool:
+		if v {
+			number = 1
+		}
+	default:
+		return nil, fmt.Errorf("%s cannot hold a %T value", dataType, value)
+	}
+
+	switch dataType {
+	case SIMCONNECT_DATATYPE_INT32:
+		return binary.LittleEndian.AppendUint32(buf, uint32(int32(number))), nil
+	case SIMCONNECT_DATATYPE_INT64:
+		return binary.LittleEndian.AppendUint64(buf, uint64(int64(number))), nil
+	case SIMCONNECT_DATATYPE_FLOAT32:
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(number))), nil
+	case SIMCONNECT_DATATYPE_FLOAT64:
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(number)), nil
+	default:
+		return nil, fmt.Errorf("%s cannot hold a %T value", dataType, value)
+	}
+}
+
+// setInt stores an integer datum in a numeric or boolean field
+func setInt(value reflect.Value, i int64) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat(float64(i))
+	case reflect.Bool:
+		value.SetBool(i != 0)
+	}
+}
+
+// setFloat stores a floating point datum in a numeric or boolean field
+func setFloat(value reflect.Value, f float64) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(uint64(f))
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat(f)
+	case reflect.Bool:
+		value.SetBool(f != 0)
+	}
+}
+
+// setString stores a string datum in a string or byte array field
+func setString(value reflect.Value, data []byte) {
+	if value.Kind() == reflect.String {
+		value.SetString(string(data))
+		return
+	}
+	value.SetZero()
+	reflect.Copy(value, reflect.ValueOf(data))
+}
+
+// DataDefinition registers the simvar-tagged fields of the struct type T as a single
+// SimConnect data definition and decodes every SIMOBJECT_DATA message for it into a T.
+// Fields are tagged `simvar:"NAME,units,datatype"`; units may be empty and the data type
+// defaults to the one matching the field's Go type (float64 is FLOAT64, string is STRING256).
+type DataDefinition[T any] struct {
+	client    *Client
+	layout    *dataLayout
+	defineID  DataDefinitionID
+	requestID SimObjectDataRequestID
+	period    SIMCONNECT_PERIOD            // Period of the running request
+	flags     SIMCONNECT_DATA_REQUEST_FLAG // Flags of the running request
+	mutex     sync.RWMutex
+	running   bool
+	closed    bool       // Close was called, the definition cannot be used anymore
+	handler   HandlerID  // Dispatcher handler registered while running
+	onError   HandlerID  // Exception handler registered by NewDataDefinition
+	replayID  HandlerID  // Replay registered by NewDataDefinition
+	callbacks []func(T)  // Called for every decoded value
+	values    chan T     // Decoded values, oldest dropped when full
+	latest    T          // Last decoded value
+	errorChan chan error // Error notifications
+	dataCount int64
+	lastData  time.Time
+}
+
+// NewDataDefinition parses the simvar tags of T and adds its fields to a new data definition.
+// The client must be open. The definition is re-created when a Supervisor reconnects the client
+// until Close is called.
+func NewDataDefinition[T any](client *Client) (*DataDefinition[T], error) {
+	layout, err := newDataLayout(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DataDefinition[T]{
+		client:    client,
+		layout:    layout,
+		defineID:  client.IDs().NewDefinitionID(),
+		values:    make(chan T, 10),     // Buffered channel for values
+		errorChan: make(chan error, 10), // Buffered channel for errors
+	}
+
+	if err := d.define(); err != nil {
+		client.IDs().Release(IDDefinition, uint32(d.defineID))
+		return nil, err
+	}
+
+	// Exceptions caused by our definition and request are reported on our error channel
+	d.onError = client.Dispatcher().HandleException(d.handleException)
+	// The definition and request are re-created when a Supervisor reconnects the client
+	d.replayID = client.addReplay(d.replay)
+	return d, nil
+}
+
+// DefineID returns the SimConnect data definition ID of T
+func (d *DataDefinition[T]) DefineID() DataDefinitionID {
+	return d.defineID
+}
+
+// OnData registers a callback receiving every decoded value on the dispatcher goroutine
+func (d *DataDefinition[T]) OnData(callback func(value T)) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.callbacks = append(d.callbacks, callback)
+}
+
+// Values returns a channel receiving decoded values; when it is full the oldest value is dropped
+func (d *DataDefinition[T]) Values() <-chan T {
+	return d.values
+}
+
+// Latest returns the last decoded value and when it arrived; the time is zero before the first value
+func (d *DataDefinition[T]) Latest() (T, time.Time) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.latest, d.lastData
+}
+
+// Decode decodes the data of a SIMOBJECT_DATA message for this definition
+func (d *DataDefinition[T]) Decode(simData []byte) (T, error) {
+	var value T
+	err := d.layout.decode(simData, reflect.ValueOf(&value).Elem())
+	return value, err
+}
+
+// Start requests the user aircraft's data with the given period and flags and starts decoding it.
+// SIMCONNECT_DATA_REQUEST_FLAG_TAGGED is not supported, values are decoded by field order.
+func (d *DataDefinition[T]) Start(period SIMCONNECT_PERIOD, flags SIMCONNECT_DATA_REQUEST_FLAG) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.closed {
+		return fmt.Errorf("data definition is closed")
+	}
+	if d.running {
+		return fmt.Errorf("data definition is already running")
+	}
+	if flags&SIMCONNECT_DATA_REQUEST_FLAG_TAGGED != 0 {
+		return fmt.Errorf("tagged data is not supported for struct definitions")
+	}
+
+	dispatcher := d.client.Dispatcher()
+	d.requestID = d.client.IDs().NewRequestID()
+	d.period, d.flags = period, flags
+	d.handler = dispatcher.HandleRequest(uint32(d.requestID), d.handleData)
+
+	if err := d.request(d.period); err != nil {
+		dispatcher.RemoveHandler(d.handler)
+		d.client.IDs().Release(IDRequest, uint32(d.requestID))
+		d.requestID = 0
+		return fmt.Errorf("failed to request data for %s: %v", d.layout.typ, err)
+	}
+
+	d.running = true
+	dispatcher.Start()
+	return nil
+}
+
+// Run starts decoding like Start, blocks until ctx is done and stops again.
+// It returns the Start error, or ctx.Err() once stopped.
+func (d *DataDefinition[T]) Run(ctx context.Context, period SIMCONNECT_PERIOD, flags SIMCONNECT_DATA_REQUEST_FLAG) error {
+	if err := d.Start(period, flags); err != nil {
+		return err
+	}
+	defer d.Stop()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Stop ends the data request and stops decoding
+func (d *DataDefinition[T]) Stop() {
+	d.mutex.Lock()
+	if !d.running {
+		d.mutex.Unlock()
+		return
+	}
+
+	dispatcher := d.client.Dispatcher()
+	dispatcher.RemoveHandler(d.handler)
+	if d.client.IsOpen() {
+		if err := d.request(SIMCONNECT_PERIOD_NEVER); err != nil {
+			d.reportError(fmt.Errorf("failed to stop data request for %s: %v", d.layout.typ, err))
+		}
+	}
+	d.client.IDs().Release(IDRequest, uint32(d.requestID))
+	d.requestID = 0
+	d.running = false
+	d.mutex.Unlock()
+
+	// Released outside the lock, a handler may still be waiting for it
+	dispatcher.Stop()
+}
+
+// Close stops the definition, clears it in SimConnect and unregisters its exception handler and
+// replay from the client. The definition cannot be started again; closing it twice is a no-op.
+func (d *DataDefinition[T]) Close() error {
+	d.Stop()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+
+	d.client.Dispatcher().RemoveHandler(d.onError)
+	d.client.removeReplay(d.replayID)
+
+	var err error
+	if d.client.IsOpen() {
+		if clearErr := d.client.ClearDataDefinition(d.defineID); clearErr != nil {
+			err = fmt.Errorf("failed to clear data definition for %s: %v", d.layout.typ, clearErr)
+		}
+	}
+	d.client.IDs().Release(IDDefinition, uint32(d.defineID))
+	return err
+}
+
+// IsRunning returns whether data is being requested and decoded
+func (d *DataDefinition[T]) IsRunning() bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.running
+}
+
+// GetStats returns how many values were decoded and when the last one arrived
+func (d *DataDefinition[T]) GetStats() (dataCount int64, lastData time.Time) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.dataCount, d.lastData
+}
+
+// GetErrors returns a channel for receiving errors (non-blocking)
+func (d *DataDefinition[T]) GetErrors() <-chan error {
+	return d.errorChan
+}
+
+// define adds every field to the SimConnect data definition in struct order
+func (d *DataDefinition[T]) define() error {
+	for _, field := range d.layout.fields {
+		if err := d.client.AddToDataDefinition(d.defineID, field.simVar, field.units, field.dataType); err != nil {
+			return fmt.Errorf("failed to add field %s (%s): %v", field.name, field.simVar, err)
+		}
+	}
+	return nil
+}
+
+// request sends the data request with the given period for the user aircraft
+func (d *DataDefinition[T]) request(period SIMCONNECT_PERIOD) error {
+	return d.client.RequestDataOnSimObjectWithFlags(d.requestID, d.defineID, SIMCONNECT_OBJECT_ID_USER, period, d.flags, 0, 0, 0)
+}
+
+// replay re-creates the data definition, and the data request while running, on a new connection
+func (d *DataDefinition[T]) replay() error {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	if err := d.define(); err != nil {
+		return err
+	}
+	if !d.running {
+		return nil
+	}
+	if err := d.request(d.period); err != nil {
+		return fmt.Errorf("failed to re-request data for %s: %v", d.layout.typ, err)
+	}
+	return nil
+}
+
+// handleData decodes a SIMOBJECT_DATA message and delivers the value
+func (d *DataDefinition[T]) handleData(data []byte) {
+	recv, simData, err := ParseSimObjectData(data)
+	if err != nil {
+		d.reportError(err)
+		return
+	}
+
+	// Data of a request ended by Stop may still be in flight
+	d.mutex.RLock()
+	active := d.running && recv.DwRequestID == uint32(d.requestID)
+	d.mutex.RUnlock()
+	if !active {
+		return
+	}
+
+	value, err := d.Decode(simData)
+	if err != nil {
+		d.reportError(err)
+		return
+	}
+
+	d.mutex.Lock()
+	d.latest = value
+	d.dataCount++
+	d.lastData = time.Now()
+	callbacks := d.callbacks
+	d.mutex.Unlock()
+
+	for _, callback := range callbacks {
+		callback(value)
+	}
+
+	// Only the dispatcher goroutine sends, so making room always succeeds
+	for {
+		select {
+		case d.values <- value:
+			return
+		default:
+		}
+		select {
+		case <-d.values: // Channel full, drop the oldest value
+		default:
+		}
+	}
+}
+
+// handleException claims exceptions caused by this definition and its request
+func (d *DataDefinition[T]) handleException(err *ExceptionError) bool {
+	if err.Packet == nil {
+		return false
+	}
+
+	d.mutex.RLock()
+	owned := err.Packet.DefineID == d.defineID ||
+		(d.requestID != 0 && err.Packet.RequestID == uint32(d.requestID))
+	d.mutex.RUnlock()
+
+	if owned {
+		d.reportError(err)
+	}
+	return owned
+}
+
+// reportError sends an error to the error channel without blocking
+func (d *DataDefinition[T]) reportError(err error) {
+	select {
+	case d.errorChan <- err:
+	default: // Channel full, drop error
+	}
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Structs covering the simvar tag forms
+type (
+	taggedDefaults struct {
+		Altitude float64  `simvar:"PLANE ALTITUDE,feet"`
+		Heading  float32  `simvar:"PLANE HEADING DEGREES TRUE,degrees"`
+		Count    int64    `simvar:"NUMBER OF ENGINES,number"`
+		OnGround bool     `simvar:"SIM ON GROUND,bool"`
+		Title    string   `simvar:"TITLE"`
+		Tail     [32]byte `simvar:"ATC ID"`
+	}
+	taggedExplicit struct {
+		Title       string                    `simvar:" TITLE , , STRINGV "`
+		Gear        int                       `simvar:"GEAR HANDLE POSITION,bool,int32"`
+		Position    SIMCONNECT_DATA_XYZ       `simvar:"STRUCT WORLDVELOCITY,feet per second"`
+		Orientation SIMCONNECT_DATA_PBH       `simvar:"STRUCT ORIENTATION,degrees,pbh"`
+		Skipped     float64                   `simvar:"-"`
+		Untagged    float64                   // Not part of the definition
+		Tagged      SIMCONNECT_DATA_XYZ       `simvar:"STRUCT SURFACE RELATIVE VELOCITY,feet per second,xyz"`
+		Location    SIMCONNECT_DATA_LATLONALT `simvar:"STRUCT LATLONALT,degrees"`
+	}
+	taggedBase struct {
+		Altitude float64 `simvar:"PLANE ALTITUDE,feet"`
+	}
+	taggedEmbedded struct {
+		taggedBase
+		Speed float64 `simvar:"AIRSPEED INDICATED,knots"`
+	}
+)
+
+func TestNewDataLayout(t *testing.T) {
+	type want struct {
+		name     string
+		simVar   string
+		units    string
+		dataType SIMCONNECT_DATATYPE
+		size     int
+		index    []int
+	}
+
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want []want
+	}{
+		{
+			name: "default data types",
+			typ:  reflect.TypeOf(taggedDefaults{}),
+			want: []want{
+				{"Altitude", "PLANE ALTITUDE", "feet", SIMCONNECT_DATATYPE_FLOAT64, 8, []int{0}},
+				{"Heading", "PLANE HEADING DEGREES TRUE", "degrees", SIMCONNECT_DATATYPE_FLOAT32, 4, []int{1}},
+				{"Count", "NUMBER OF ENGINES", "number", SIMCONNECT_DATATYPE_INT64, 8, []int{2}},
+				{"OnGround", "SIM ON GROUND", "bool", SIMCONNECT_DATATYPE_INT32, 4, []int{3}},
+				{"Title", "TITLE", "", SIMCONNECT_DATATYPE_STRING256, 256, []int{4}},
+				{"Tail", "ATC ID", "", SIMCONNECT_DATATYPE_STRING32, 32, []int{5}},
+			},
+		},
+		{
+			name: "explicit data types and structures",
+			typ:  reflect.TypeOf(taggedExplicit{}),
+			want: []want{
+				{"Title", "TITLE", "", SIMCONNECT_DATATYPE_STRINGV, 0, []int{0}},
+				{"Gear", "GEAR HANDLE POSITION", "bool", SIMCONNECT_DATATYPE_INT32, 4, []int{1}},
+				{"Position", "STRUCT WORLDVELOCITY", "feet per second", SIMCONNECT_DATATYPE_XYZ, 24, []int{2}},
+				{"Orientation", "STRUCT ORIENTATION", "degrees", SIMCONNECT_DATATYPE_PBH, 12, []int{3}},
+				{"Tagged", "STRUCT SURFACE RELATIVE VELOCITY", "feet per second", SIMCONNECT_DATATYPE_XYZ, 24, []int{6}},
+				{"Location", "STRUCT LATLONALT", "degrees", SIMCONNECT_DATATYPE_LATLONALT, 24, []int{7}},
+			},
+		},
+		{
+			name: "embedded struct fields",
+			typ:  reflect.TypeOf(taggedEmbedded{}),
+			want: []want{
+				{"Altitude", "PLANE ALTITUDE", "feet", SIMCONNECT_DATATYPE_FLOAT64, 8, []int{0, 0}},
+				{"Speed", "AIRSPEED INDICATED", "knots", SIMCONNECT_DATATYPE_FLOAT64, 8, []int{1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, err := newDataLayout(tt.typ)
+			if err != nil {
+				t.Fatalf("newDataLayout: %v", err)
+			}
+			if len(layout.fields) != len(tt.want) {
+				t.Fatalf("%d fields, want %d", len(layout.fields), len(tt.want))
+			}
+			for i, field := range layout.fields {
+				got := want{field.name, field.simVar, field.units, field.dataType, field.size, field.index}
+				if !reflect.DeepEqual(got, tt.want[i]) {
+					t.Errorf("field %d is %+v, want %+v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewDataLayoutRejectsInvalidTags(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+	}{
+		{"not a struct", reflect.TypeOf(1.0)},
+		{"no tagged fields", reflect.TypeOf(struct{ Altitude float64 }{})},
+		{"empty name", reflect.TypeOf(struct {
+			Altitude float64 `simvar:",feet"`
+		}{})},
+		{"too many parts", reflect.TypeOf(struct {
+			Altitude float64 `simvar:"PLANE ALTITUDE,feet,float64,extra"`
+		}{})},
+		{"unknown data type", reflect.TypeOf(struct {
+			Altitude float64 `simvar:"PLANE ALTITUDE,feet,float128"`
+		}{})},
+		{"string into number", reflect.TypeOf(struct {
+			Altitude float64 `simvar:"PLANE ALTITUDE,feet,string32"`
+		}{})},
+		{"number into string", reflect.TypeOf(struct {
+			Title string `simvar:"TITLE,,float64"`
+		}{})},
+		{"structure into number", reflect.TypeOf(struct {
+			Position float64 `simvar:"STRUCT WORLDVELOCITY,feet per second,xyz"`
+		}{})},
+		{"no default for slices", reflect.TypeOf(struct {
+			Values []float64 `simvar:"PLANE ALTITUDE,feet"`
+		}{})},
+		{"no default for odd byte arrays", reflect.TypeOf(struct {
+			Title [10]byte `simvar:"TITLE"`
+		}{})},
+		{"unexported field", reflect.TypeOf(struct {
+			altitude float64 `simvar:"PLANE ALTITUDE,feet"`
+		}{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newDataLayout(tt.typ); err == nil {
+				t.Error("invalid layout accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// position is the struct definition used by the tests
+type position struct {
+	Altitude float64 `simvar:"PLANE ALTITUDE,feet"`
+	OnGround bool    `simvar:"SIM ON GROUND,bool"`
+	Title    string  `simvar:"TITLE,,string256"`
+}
+
+func TestDataDefinitionClose(t *testing.T) {
+	server := simtest.NewServer()
+	server.SetSimVar("PLANE ALTITUDE", 1500.0)
+	server.SetSimVar("SIM ON GROUND", 0.0)
+	server.SetSimVar("TITLE", "Cessna 172")
+	simClient := openClient(t, server)
+
+	definition, err := client.NewDataDefinition[position](simClient)
+	if err != nil {
+		t.Fatalf("NewDataDefinition: %v", err)
+	}
+	defineID := definition.DefineID()
+
+	if err := definition.Start(client.SIMCONNECT_PERIOD_SIM_FRAME, 0); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	server.Step(1)
+	select {
+	case value := <-definition.Values():
+		if value.Altitude != 1500 || value.OnGround || value.Title != "Cessna 172" {
+			t.Errorf("decoded %+v", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no value decoded")
+	}
+
+	if err := definition.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if len(server.Definition(defineID)) != 0 {
+		t.Error("definition still registered with SimConnect after Close")
+	}
+	if err := definition.Start(client.SIMCONNECT_PERIOD_SIM_FRAME, 0); err == nil {
+		t.Error("Start after Close succeeded")
+	}
+	if err := definition.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// A reconnect must not re-create the closed definition
+	supervisor := client.NewSupervisor(simClient, client.ReconnectConfig{InitialDelay: time.Millisecond})
+	if err := supervisor.Start(); err != nil {
+		t.Fatalf("supervisor Start: %v", err)
+	}
+	defer supervisor.Stop()
+	simClient.Dispatcher().Start()
+	defer simClient.Dispatcher().Stop()
+
+	server.Quit()
+	waitFor(t, "reconnect", func() bool {
+		reconnects, _ := supervisor.GetStats()
+		return reconnects == 1
+	})
+	if len(server.Definition(defineID)) != 0 {
+		t.Error("closed definition was replayed after reconnect")
+	}
+}
@@ -0,0 +1,378 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// dispatchInterval is how often messages are polled for when the transport cannot signal them
+// (see MessageWaiter) or the client is not open
+const dispatchInterval = 50 * time.Millisecond
+
+// MessageHandler receives a raw SimConnect message routed by the Dispatcher
+type MessageHandler func(data []byte)
+
+// ExceptionHandler receives exceptions matched with the call that caused them.
+// It returns true when the exception belongs to the handler's component.
+type ExceptionHandler func(err *ExceptionError) bool
+
+// HandlerID identifies a registered handler so it can be removed again
+type HandlerID uint64
+
+// Dispatcher reads every SimConnect message of a Client exactly once and fans it out
+// to registered handlers by SIMCONNECT_RECV_ID, request ID and client event ID.
+// FlightDataManager, SystemEventManager and user code register handlers here instead
+// of calling GetRawDispatch themselves, so no component drops another's messages.
+type Dispatcher struct {
+	client    *Client
+	mutex     sync.RWMutex                            // Guards handler maps and run state
+	all       map[HandlerID]MessageHandler            // Handlers receiving every message
+	byType    map[uint32]map[HandlerID]MessageHandler // Handlers by SIMCONNECT_RECV_ID
+	byRequest map[uint32]map[HandlerID]MessageHandler // Handlers by request ID
+	byEvent   map[uint32]map[HandlerID]MessageHandler // Handlers by client event ID
+	onError   map[HandlerID]ExceptionHandler          // Exception handlers
+	nextID    HandlerID                               // Next handler ID
+	users     int                                     // Number of active Start calls
+	cancel    context.CancelFunc                      // Stops the pump
+	done      chan struct{}                           // Closed when the pump has exited
+	errorChan chan error                              // Error notifications
+}
+
+// newDispatcher creates the dispatcher owned by a Client
+func newDispatcher(client *Client) *Dispatcher {
+	return &Dispatcher{
+		client:    client,
+		all:       make(map[HandlerID]MessageHandler),
+		byType:    make(map[uint32]map[HandlerID]MessageHandler),
+		byRequest: make(map[uint32]map[HandlerID]MessageHandler),
+		byEvent:   make(map[uint32]map[HandlerID]MessageHandler),
+		onError:   make(map[HandlerID]ExceptionHandler),
+		errorChan: make(chan error, 10), // Buffered channel for non-blocking errors
+	}
+}
+
+// HandleAll registers a handler that receives every message
+func (d *Dispatcher) HandleAll(handler MessageHandler) HandlerID {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.nextID++
+	d.all[d.nextID] = handler
+	return d.nextID
+}
+
+// HandleMessageType registers a handler for all messages with the given SIMCONNECT_RECV_ID
+func (d *Dispatcher) HandleMessageType(recvID uint32, handler MessageHandler) HandlerID {
+	return d.add(d.byType, recvID, handler)
+}
+
+// HandleRequest registers a handler for messages answering the given request ID
+// (SIMOBJECT_DATA, SIMOBJECT_DATA_BYTYPE, SYSTEM_STATE, CLIENT_DATA, facility and input event lists, ...)
+func (d *Dispatcher) HandleRequest(requestID uint32, handler MessageHandler) HandlerID {
+	return d.add(d.byRequest, requestID, handler)
+}
+
+// HandleEvent registers a handler for event messages carrying the given client event ID
+func (d *Dispatcher) HandleEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, handler MessageHandler) HandlerID {
+	return d.add(d.byEvent, uint32(eventID), handler)
+}
+
+// HandleException registers a handler for SIMCONNECT_RECV_EXCEPTION messages.
+// Exceptions no handler claims are sent to the dispatcher's error channel.
+func (d *Dispatcher) HandleException(handler ExceptionHandler) HandlerID {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.nextID++
+	d.onError[d.nextID] = handler
+	return d.nextID
+}
+
+// RemoveHandler unregisters a handler; removing an unknown ID is a no-op
+func (d *Dispatcher) RemoveHandler(id HandlerID) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.all, id)
+	delete(d.onError, id)
+	for _, table := range []map[uint32]map[HandlerID]MessageHandler{d.byType, d.byRequest, d.byEvent} {
+		for key, handlers := range table {
+			if _, exists := handlers[id]; exists {
+				delete(handlers, id)
+				if len(handlers) == 0 {
+					delete(table, key)
+				}
+			}
+		}
+	}
+}
+
+// Start begins pumping messages in a background goroutine.
+// Start calls are counted, the pump keeps running until every caller has called Stop.
+func (d *Dispatcher) Start() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.users++
+	if d.users > 1 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	go d.pump(ctx, d.done)
+}
+
+// Stop releases one Start call and stops the pump when none remain.
+// It waits for the pump goroutine to exit, so it must not be called from a handler.
+func (d *Dispatcher) Stop() {
+	d.mutex.Lock()
+	if d.users == 0 {
+		d.mutex.Unlock()
+		return
+	}
+
+	d.users--
+	if d.users > 0 {
+		d.mutex.Unlock()
+		return
+	}
+
+	cancel, done := d.cancel, d.done
+	d.mutex.Unlock()
+
+	cancel()
+	<-done
+}
+
+// Run pumps messages until ctx is done and returns ctx.Err().
+// Like Start it is counted, so the pump keeps running for other users.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	d.Start()
+	defer d.Stop()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// IsRunning returns whether the message pump is active
+func (d *Dispatcher) IsRunning() bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.users > 0
+}
+
+// GetErrors returns the error channel for dispatch and handler failures
+func (d *Dispatcher) GetErrors() <-chan error {
+	return d.errorChan
+}
+
+// Dispatch routes a single raw message to the matching handlers.
+// The pump calls this for every message; applications reading messages
+// themselves can use it to feed the same handlers.
+func (d *Dispatcher) Dispatch(data []byte) {
+	recvID, err := ParseMessageType(data)
+	if err != nil {
+		d.reportError(err)
+		return
+	}
+
+	// Collect handlers under the lock and call them without it, so handlers may register or remove handlers
+	d.mutex.RLock()
+	handlers := make([]MessageHandler, 0, len(d.all)+1)
+	for _, handler := range d.all {
+		handlers = append(handlers, handler)
+	}
+	for _, handler := range d.byType[recvID] {
+		handlers = append(handlers, handler)
+	}
+	if requestID, ok := messageRequestID(recvID, data); ok {
+		for _, handler := range d.byRequest[requestID] {
+			handlers = append(handlers, handler)
+		}
+	}
+	if eventID, ok := messageEventID(recvID, data); ok {
+		for _, handler := range d.byEvent[eventID] {
+			handlers = append(handlers, handler)
+		}
+	}
+	d.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		d.invoke(handler, data)
+	}
+
+	if recvID == SIMCONNECT_RECV_ID_EXCEPTION {
+		d.dispatchException(data)
+	}
+}
+
+// dispatchException offers an exception to the exception handlers
+func (d *Dispatcher) dispatchException(data []byte) {
+	recv, err := ParseException(data)
+	if err != nil {
+		d.reportError(err)
+		return
+	}
+	exception := d.client.ExceptionError(recv)
+
+	d.mutex.RLock()
+	handlers := make([]ExceptionHandler, 0, len(d.onError))
+	for _, handler := range d.onError {
+		handlers = append(handlers, handler)
+	}
+	d.mutex.RUnlock()
+
+	claimed := false
+	for _, handler := range handlers {
+		if d.invokeException(handler, exception) {
+			claimed = true
+		}
+	}
+
+	if !claimed {
+		d.reportError(exception)
+	}
+}
+
+// invokeException calls an exception handler and turns panics into errors
+func (d *Dispatcher) invokeException(handler ExceptionHandler, exception *ExceptionError) (claimed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.reportError(fmt.Errorf("exception handler panic: %v", r))
+		}
+	}()
+	return handler(exception)
+}
+
+// add registers a handler in one of the keyed tables
+func (d *Dispatcher) add(table map[uint32]map[HandlerID]MessageHandler, key uint32, handler MessageHandler) HandlerID {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.nextID++
+	if table[key] == nil {
+		table[key] = make(map[HandlerID]MessageHandler)
+	}
+	table[key][d.nextID] = handler
+	return d.nextID
+}
+
+// invoke calls a handler and turns panics into errors
+func (d *Dispatcher) invoke(handler MessageHandler, data []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.reportError(fmt.Errorf("message handler panic: %v", r))
+		}
+	}()
+	handler(data)
+}
+
+// pump drains the message queue each time the client signals new messages until stopped
+func (d *Dispatcher) pump(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for {
+		d.drain()
+
+		if err := d.client.WaitForMessage(ctx); err != nil {
+			return
+		}
+	}
+}
+
+// drain dispatches every queued message
+func (d *Dispatcher) drain() {
+	if !d.client.IsOpen() {
+		return
+	}
+
+	for {
+		data, err := d.client.GetRawDispatch()
+		if err != nil {
+			d.reportError(fmt.Errorf("error getting raw dispatch: %v", err))
+			return
+		}
+
+		if data == nil {
+			return // No more messages available
+		}
+
+		d.Dispatch(data)
+	}
+}
+
+// reportError sends an error to the error channel without blocking
+func (d *Dispatcher) reportError(err error) {
+	select {
+	case d.errorChan <- err:
+	default: // Channel full, drop error
+	}
+}
+
+// messageRequestID extracts the request ID of messages that answer a request
+func messageRequestID(recvID uint32, data []byte) (uint32, bool) {
+	switch recvID {
+	case SIMCONNECT_RECV_ID_SIMOBJECT_DATA,
+		SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE,
+		SIMCONNECT_RECV_ID_SYSTEM_STATE,
+		SIMCONNECT_RECV_ID_CLIENT_DATA,
+		SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID,
+		SIMCONNECT_RECV_ID_AIRPORT_LIST,
+		SIMCONNECT_RECV_ID_VOR_LIST,
+		SIMCONNECT_RECV_ID_NDB_LIST,
+		SIMCONNECT_RECV_ID_WAYPOINT_LIST,
+		SIMCONNECT_RECV_ID_WEATHER_OBSERVATION,
+		SIMCONNECT_RECV_ID_CLOUD_STATE,
+		SIMCONNECT_RECV_ID_FACILITY_DATA,
+		SIMCONNECT_RECV_ID_FACILITY_DATA_END,
+		SIMCONNECT_RECV_ID_FACILITY_MINIMAL_LIST,
+		SIMCONNECT_RECV_ID_JETWAY_DATA,
+		SIMCONNECT_RECV_ID_CONTROLLERS_LIST,
+		SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS,
+		SIMCONNECT_RECV_ID_GET_INPUT_EVENT,
+		SIMCONNECT_RECV_ID_ENUMERATE_SIMOBJECT_AND_LIVERY_LIST:
+		// dwRequestID directly follows the SIMCONNECT_RECV header
+		if len(data) < 16 {
+			return 0, false
+		}
+		return binary.LittleEndian.Uint32(data[12:]), true
+	case SIMCONNECT_RECV_ID_ACTION_CALLBACK:
+		// cbRequestId follows szActionID
+		if len(data) < 12+MAX_PATH+4 {
+			return 0, false
+		}
+		return binary.LittleEndian.Uint32(data[12+MAX_PATH:]), true
+	default:
+		return 0, false
+	}
+}
+
+// messageEventID extracts the client event ID of event messages
+func messageEventID(recvID uint32, data []byte) (uint32, bool) {
+	switch recvID {
+	case SIMCONNECT_RECV_ID_EVENT,
+		SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE,
+		SIMCONNECT_RECV_ID_EVENT_FILENAME,
+		SIMCONNECT_RECV_ID_EVENT_FRAME,
+		SIMCONNECT_RECV_ID_CUSTOM_ACTION,
+		SIMCONNECT_RECV_ID_EVENT_WEATHER_MODE,
+		SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED,
+		SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED,
+		SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED,
+		SIMCONNECT_RECV_ID_EVENT_RACE_END,
+		SIMCONNECT_RECV_ID_EVENT_RACE_LAP,
+		SIMCONNECT_RECV_ID_EVENT_EX1:
+		// uEventID follows uGroupID after the SIMCONNECT_RECV header
+		if len(data) < 20 {
+			return 0, false
+		}
+		return binary.LittleEndian.Uint32(data[16:]), true
+	default:
+		return 0, false
+	}
+}
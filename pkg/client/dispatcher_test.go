@@ -0,0 +1,214 @@
+package client_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// newMemoryClient returns an open client on a MemoryTransport and its running dispatcher
+func newMemoryClient(t *testing.T) (*client.Client, *client.MemoryTransport) {
+	t.Helper()
+	transport := client.NewMemoryTransport()
+	simClient := client.NewClientWithTransport("test", transport)
+	if err := simClient.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { simClient.Close() })
+
+	simClient.Dispatcher().Start()
+	t.Cleanup(simClient.Dispatcher().Stop)
+
+	transport.Push(simtest.EncodeOpen("test"))
+	waitFor(t, "open confirmation", func() bool { return simClient.State() == client.StateOpen })
+	return simClient, transport
+}
+
+// receiveMessage waits for a message on received
+func receiveMessage(t *testing.T, received <-chan []byte) []byte {
+	t.Helper()
+	select {
+	case data := <-received:
+		return data
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message dispatched")
+		return nil
+	}
+}
+
+func TestDispatcherRoutesMemoryTransportMessages(t *testing.T) {
+	simClient, transport := newMemoryClient(t)
+	dispatcher := simClient.Dispatcher()
+
+	tests := []struct {
+		name     string
+		register func(handler client.MessageHandler) client.HandlerID
+		other    []byte // Message the handler must not receive
+		message  []byte // Message the handler must receive
+	}{
+		{
+			name: "by message type",
+			register: func(handler client.MessageHandler) client.HandlerID {
+				return dispatcher.HandleMessageType(client.SIMCONNECT_RECV_ID_EVENT_FRAME, handler)
+			},
+			other:   simtest.EncodeEvent(0, 6, 0),
+			message: simtest.EncodeEventFrame(6, 60, 1),
+		},
+		{
+			name: "by data request ID",
+			register: func(handler client.MessageHandler) client.HandlerID {
+				return dispatcher.HandleRequest(9, handler)
+			},
+			other:   simtest.EncodeSimObjectData(8, 0, 1, 0, 1, make([]byte, 8)),
+			message: simtest.EncodeSimObjectData(9, 0, 1, 0, 1, make([]byte, 8)),
+		},
+		{
+			name: "by system state request ID",
+			register: func(handler client.MessageHandler) client.HandlerID {
+				return dispatcher.HandleRequest(4, handler)
+			},
+			other:   simtest.EncodeSystemState(5, 1, 0, ""),
+			message: simtest.EncodeSystemState(4, 1, 0, ""),
+		},
+		{
+			name: "by input event request ID",
+			register: func(handler client.MessageHandler) client.HandlerID {
+				return dispatcher.HandleRequest(12, handler)
+			},
+			other:   simtest.EncodeGetInputEvent(13, 1.0),
+			message: simtest.EncodeGetInputEvent(12, 1.0),
+		},
+		{
+			name: "by event ID",
+			register: func(handler client.MessageHandler) client.HandlerID {
+				return dispatcher.HandleEvent(7, handler)
+			},
+			other:   simtest.EncodeEvent(0, 3, 1),
+			message: simtest.EncodeEvent(0, 7, 1),
+		},
+		{
+			name: "by event ID of filename events",
+			register: func(handler client.MessageHandler) client.HandlerID {
+				return dispatcher.HandleEvent(7, handler)
+			},
+			other:   simtest.EncodeEventFilename(3, 0, "aircraft.cfg"),
+			message: simtest.EncodeEventFilename(7, 0, "aircraft.cfg"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := make(chan []byte, 4)
+			id := tt.register(func(data []byte) { received <- data })
+			defer dispatcher.RemoveHandler(id)
+
+			// A catch-all handler tells when both messages were dispatched
+			all := make(chan []byte, 4)
+			allID := dispatcher.HandleAll(func(data []byte) { all <- data })
+			defer dispatcher.RemoveHandler(allID)
+
+			transport.Push(tt.other, tt.message)
+			receiveMessage(t, all)
+			receiveMessage(t, all)
+
+			if data := receiveMessage(t, received); !bytes.Equal(data, tt.message) {
+				t.Errorf("handler received %x, want %x", data, tt.message)
+			}
+			select {
+			case data := <-received:
+				t.Errorf("handler also received %x", data)
+			default:
+			}
+		})
+	}
+}
+
+func TestDispatcherRemovedHandlerReceivesNothing(t *testing.T) {
+	simClient, transport := newMemoryClient(t)
+	dispatcher := simClient.Dispatcher()
+
+	removed := make(chan []byte, 1)
+	dispatcher.RemoveHandler(dispatcher.HandleEvent(7, func(data []byte) { removed <- data }))
+	all := make(chan []byte, 1)
+	dispatcher.HandleAll(func(data []byte) { all <- data })
+
+	transport.Push(simtest.EncodeEvent(0, 7, 1))
+	receiveMessage(t, all)
+	select {
+	case <-removed:
+		t.Error("removed handler was called")
+	default:
+	}
+}
+
+func TestDispatcherMatchesExceptionsWithCalls(t *testing.T) {
+	simClient, transport := newMemoryClient(t)
+	dispatcher := simClient.Dispatcher()
+
+	if err := simClient.SubscribeToSystemEvent(1, "Pause"); err != nil {
+		t.Fatalf("SubscribeToSystemEvent: %v", err)
+	}
+	calls := transport.CallsTo("SimConnect_SubscribeToSystemEvent")
+	subscribeID := calls[len(calls)-1].SendID
+
+	claimed := make(chan *client.ExceptionError, 1)
+	dispatcher.HandleException(func(err *client.ExceptionError) bool {
+		if err.SendID != subscribeID {
+			return false
+		}
+		claimed <- err
+		return true
+	})
+
+	// The claimed exception is matched with its call and not reported
+	transport.Push(simtest.EncodeException(uint32(client.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED), subscribeID, 2))
+	select {
+	case err := <-claimed:
+		if err.Packet == nil || err.Packet.Operation != "SubscribeToSystemEvent" {
+			t.Errorf("exception matched with %+v", err.Packet)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("exception not offered to the handler")
+	}
+
+	// Exceptions nobody claims are reported on the error channel
+	transport.Push(simtest.EncodeException(uint32(client.SIMCONNECT_EXCEPTION_ERROR), subscribeID+100, 0))
+	select {
+	case err := <-dispatcher.GetErrors():
+		var exception *client.ExceptionError
+		if !errors.As(err, &exception) || exception.SendID != subscribeID+100 {
+			t.Errorf("reported %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("unclaimed exception not reported")
+	}
+	select {
+	case err := <-dispatcher.GetErrors():
+		t.Errorf("claimed exception also reported: %v", err)
+	default:
+	}
+}
+
+func TestDispatcherRecoversHandlerPanics(t *testing.T) {
+	simClient, transport := newMemoryClient(t)
+	dispatcher := simClient.Dispatcher()
+
+	dispatcher.HandleEvent(7, func([]byte) { panic("handler bug") })
+	received := make(chan []byte, 1)
+	dispatcher.HandleEvent(8, func(data []byte) { received <- data })
+
+	transport.Push(simtest.EncodeEvent(0, 7, 1), simtest.EncodeEvent(0, 8, 1))
+	receiveMessage(t, received)
+	select {
+	case err := <-dispatcher.GetErrors():
+		if err == nil {
+			t.Error("nil error")
+		}
+	default:
+		t.Error("handler panic not reported")
+	}
+}
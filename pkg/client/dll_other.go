@@ -0,0 +1,155 @@
+//go:build !windows
+
+package client
+
+import (
+	"fmt"
+	"os"
+)
+
+// dllTransport stands in for SimConnect.dll on platforms that cannot load it.
+// Open always fails so callers learn early that they need the network backend.
+type dllTransport struct {
+	dllPath string // Path the caller asked for, reported in errors
+}
+
+// newDLLTransport creates a transport that reports SimConnect.dll as unavailable
+func newDLLTransport(dllPath string) Transport {
+	return &dllTransport{dllPath: dllPath}
+}
+
+// unavailable returns the error reported by every call on this platform
+func (t *dllTransport) unavailable(function string) error {
+	return NewSimConnectError(function, E_FAIL,
+		fmt.Sprintf("%s can only be loaded on Windows, use NewNetworkClient to reach a remote simulator", t.dllPath))
+}
+
+func (t *dllTransport) Open(name string) error {
+	return t.unavailable("SimConnect_Open")
+}
+
+func (t *dllTransport) Close() error {
+	return t.unavailable("SimConnect_Close")
+}
+
+func (t *dllTransport) AddToDataDefinition(defineID DataDefinitionID, datumName, unitsName string, datumType SIMCONNECT_DATATYPE, epsilon float32, datumID uint32) error {
+	return t.unavailable("SimConnect_AddToDataDefinition")
+}
+
+func (t *dllTransport) ClearDataDefinition(defineID DataDefinitionID) error {
+	return t.unavailable("SimConnect_ClearDataDefinition")
+}
+
+func (t *dllTransport) RequestDataOnSimObject(requestID SimObjectDataRequestID, defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, period SIMCONNECT_PERIOD, flags SIMCONNECT_DATA_REQUEST_FLAG, origin, interval, limit uint32) error {
+	return t.unavailable("SimConnect_RequestDataOnSimObject")
+}
+
+func (t *dllTransport) SetDataOnSimObject(defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, flags SIMCONNECT_DATA_SET_FLAG, arrayCount, unitSize uint32, data []byte) error {
+	return t.unavailable("SimConnect_SetDataOnSimObject")
+}
+
+func (t *dllTransport) RequestSystemState(requestID DataRequestID, state string) error {
+	return t.unavailable("SimConnect_RequestSystemState")
+}
+
+func (t *dllTransport) MapClientEventToSimEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, eventName string) error {
+	return t.unavailable("SimConnect_MapClientEventToSimEvent")
+}
+
+func (t *dllTransport) TransmitClientEvent(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, data uint32, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG) error {
+	return t.unavailable("SimConnect_TransmitClientEvent")
+}
+
+func (t *dllTransport) TransmitClientEvent_EX1(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG, data [5]uint32) error {
+	return t.unavailable("SimConnect_TransmitClientEvent_EX1")
+}
+
+func (t *dllTransport) AddClientEventToNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, maskable bool) error {
+	return t.unavailable("SimConnect_AddClientEventToNotificationGroup")
+}
+
+func (t *dllTransport) RemoveClientEvent(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	return t.unavailable("SimConnect_RemoveClientEvent")
+}
+
+func (t *dllTransport) SetNotificationGroupPriority(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error {
+	return t.unavailable("SimConnect_SetNotificationGroupPriority")
+}
+
+func (t *dllTransport) ClearNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID) error {
+	return t.unavailable("SimConnect_ClearNotificationGroup")
+}
+
+func (t *dllTransport) MapInputEventToClientEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string, downEventID SIMCONNECT_CLIENT_EVENT_ID, downValue uint32, upEventID SIMCONNECT_CLIENT_EVENT_ID, upValue uint32, maskable bool) error {
+	return t.unavailable("SimConnect_MapInputEventToClientEvent")
+}
+
+func (t *dllTransport) SetInputGroupPriority(groupID SIMCONNECT_INPUT_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error {
+	return t.unavailable("SimConnect_SetInputGroupPriority")
+}
+
+func (t *dllTransport) RemoveInputEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string) error {
+	return t.unavailable("SimConnect_RemoveInputEvent")
+}
+
+func (t *dllTransport) ClearInputGroup(groupID SIMCONNECT_INPUT_GROUP_ID) error {
+	return t.unavailable("SimConnect_ClearInputGroup")
+}
+
+func (t *dllTransport) SetInputGroupState(groupID SIMCONNECT_INPUT_GROUP_ID, state SIMCONNECT_STATE) error {
+	return t.unavailable("SimConnect_SetInputGroupState")
+}
+
+func (t *dllTransport) RequestReservedKey(eventID SIMCONNECT_CLIENT_EVENT_ID, keyChoice1, keyChoice2, keyChoice3 string) error {
+	return t.unavailable("SimConnect_RequestReservedKey")
+}
+
+func (t *dllTransport) SubscribeToSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, systemEventName string) error {
+	return t.unavailable("SimConnect_SubscribeToSystemEvent")
+}
+
+func (t *dllTransport) UnsubscribeFromSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	return t.unavailable("SimConnect_UnsubscribeFromSystemEvent")
+}
+
+func (t *dllTransport) SetSystemEventState(eventID SIMCONNECT_CLIENT_EVENT_ID, state SIMCONNECT_STATE) error {
+	return t.unavailable("SimConnect_SetSystemEventState")
+}
+
+func (t *dllTransport) EnumerateInputEvents(requestID DataRequestID) error {
+	return t.unavailable("SimConnect_EnumerateInputEvents")
+}
+
+func (t *dllTransport) GetInputEvent(requestID DataRequestID, hash uint64) error {
+	return t.unavailable("SimConnect_GetInputEvent")
+}
+
+func (t *dllTransport) SetInputEvent(hash uint64, value []byte) error {
+	return t.unavailable("SimConnect_SetInputEvent")
+}
+
+func (t *dllTransport) SubscribeInputEvent(hash uint64) error {
+	return t.unavailable("SimConnect_SubscribeInputEvent")
+}
+
+func (t *dllTransport) UnsubscribeInputEvent(hash uint64) error {
+	return t.unavailable("SimConnect_UnsubscribeInputEvent")
+}
+
+func (t *dllTransport) EnumerateInputEventParams(hash uint64) error {
+	return t.unavailable("SimConnect_EnumerateInputEventParams")
+}
+
+func (t *dllTransport) GetLastSentPacketID() (uint32, error) {
+	return 0, t.unavailable("SimConnect_GetLastSentPacketID")
+}
+
+func (t *dllTransport) GetNextDispatch() ([]byte, error) {
+	return nil, t.unavailable("SimConnect_GetNextDispatch")
+}
+
+// outputDebugString writes the message to stderr, as there is no debug console outside Windows
+func outputDebugString(message string) error {
+	_, err := fmt.Fprintln(os.Stderr, message)
+	return err
+}
@@ -0,0 +1,647 @@
+//go:build windows
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"syscall"
+	"unsafe"
+)
+
+// Win32 functions used to wait for SimConnect messages
+var (
+	kernel32                   = syscall.NewLazyDLL("kernel32.dll")
+	procCreateEventW           = kernel32.NewProc("CreateEventW")
+	procSetEvent               = kernel32.NewProc("SetEvent")
+	procWaitForMultipleObjects = kernel32.NewProc("WaitForMultipleObjects")
+)
+
+// Win32 wait constants
+const (
+	win32Infinite   = 0xFFFFFFFF // INFINITE timeout
+	win32WaitFailed = 0xFFFFFFFF // WAIT_FAILED result
+)
+
+// dllTransport carries SimConnect calls through SimConnect.dll
+type dllTransport struct {
+	handle       uintptr          // HANDLE to SimConnect object
+	dll          *syscall.LazyDLL // Reference to SimConnect.dll
+	messageEvent uintptr          // Auto-reset event SimConnect signals when messages arrive, 0 if unavailable
+	wakeEvent    uintptr          // Auto-reset event interrupting WaitForMessage, 0 if unavailable
+}
+
+// newDLLTransport creates a transport backed by the SimConnect.dll at dllPath.
+// The event handles live as long as the transport so waiters never see them closed.
+func newDLLTransport(dllPath string) Transport {
+	return &dllTransport{
+		dll:          syscall.NewLazyDLL(dllPath),
+		messageEvent: createEvent(),
+		wakeEvent:    createEvent(),
+	}
+}
+
+// createEvent creates an unnamed auto-reset Win32 event, returning 0 on failure
+func createEvent() uintptr {
+	// HANDLE CreateEventW(LPSECURITY_ATTRIBUTES lpEventAttributes, BOOL bManualReset, BOOL bInitialState, LPCWSTR lpName)
+	if err := procCreateEventW.Find(); err != nil {
+		return 0
+	}
+	handle, _, _ := procCreateEventW.Call(0, 0, 0, 0)
+	return handle
+}
+
+// hresultError converts the HRESULT returned by a SimConnect function into an error
+// Calls keep their uintptr(unsafe.Pointer(...)) conversions inside proc.Call so the
+// referenced memory stays alive for the duration of the call.
+func hresultError(function string, r1 uintptr) error {
+	hresult := uint32(r1)
+	if !IsHRESULTSuccess(hresult) {
+		return NewSimConnectError(function, hresult, GetHRESULTMessage(hresult))
+	}
+
+	return nil
+}
+
+// Open implements SimConnect_Open
+func (t *dllTransport) Open(name string) error {
+	// Convert name to null-terminated byte array
+	nameBytes, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return fmt.Errorf("failed to convert name to bytes: %v", err)
+	}
+
+	// HRESULT SimConnect_Open(HANDLE* phSimConnect, LPCSTR szName, HWND hWnd,
+	//                         DWORD UserEventWin32, HANDLE hEventHandle, DWORD ConfigIndex)
+	r1, _, _ := t.dll.NewProc("SimConnect_Open").Call(
+		uintptr(unsafe.Pointer(&t.handle)), // phSimConnect
+		uintptr(unsafe.Pointer(nameBytes)), // szName
+		0,                                  // hWnd (NULL)
+		0,                                  // UserEventWin32
+		t.messageEvent,                     // hEventHandle, signalled when messages arrive
+		uintptr(SIMCONNECT_OPEN_CONFIGINDEX_LOCAL), // ConfigIndex
+	)
+	return hresultError("SimConnect_Open", r1)
+}
+
+// Close implements SimConnect_Close
+func (t *dllTransport) Close() error {
+	// HRESULT SimConnect_Close(HANDLE hSimConnect)
+	r1, _, _ := t.dll.NewProc("SimConnect_Close").Call(t.handle)
+	if err := hresultError("SimConnect_Close", r1); err != nil {
+		return err
+	}
+
+	t.handle = 0
+	t.wake() // Release waiters, the connection is gone
+	return nil
+}
+
+// AddToDataDefinition implements SimConnect_AddToDataDefinition
+func (t *dllTransport) AddToDataDefinition(defineID DataDefinitionID, datumName, unitsName string, datumType SIMCONNECT_DATATYPE, epsilon float32, datumID uint32) error {
+	// Convert strings to null-terminated byte arrays
+	datumNameBytes, err := syscall.BytePtrFromString(datumName)
+	if err != nil {
+		return fmt.Errorf("failed to convert datum name to bytes: %v", err)
+	}
+
+	unitsNameBytes, err := syscall.BytePtrFromString(unitsName)
+	if err != nil {
+		return fmt.Errorf("failed to convert units name to bytes: %v", err)
+	}
+
+	// HRESULT SimConnect_AddToDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID,
+	//                                        const char* DatumName, const char* UnitsName,
+	//                                        SIMCONNECT_DATATYPE DatumType, float fEpsilon, DWORD DatumID)
+	r1, _, _ := t.dll.NewProc("SimConnect_AddToDataDefinition").Call(
+		t.handle,                                // hSimConnect
+		uintptr(defineID),                       // DefineID
+		uintptr(unsafe.Pointer(datumNameBytes)), // DatumName
+		uintptr(unsafe.Pointer(unitsNameBytes)), // UnitsName
+		uintptr(datumType),                      // DatumType
+		uintptr(math.Float32bits(epsilon)),      // fEpsilon (passed on the stack, so the raw bits are enough)
+		uintptr(datumID),                        // DatumID
+	)
+	return hresultError("SimConnect_AddToDataDefinition", r1)
+}
+
+// ClearDataDefinition implements SimConnect_ClearDataDefinition
+func (t *dllTransport) ClearDataDefinition(defineID DataDefinitionID) error {
+	// HRESULT SimConnect_ClearDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID)
+	r1, _, _ := t.dll.NewProc("SimConnect_ClearDataDefinition").Call(
+		t.handle,          // hSimConnect
+		uintptr(defineID), // DefineID
+	)
+	return hresultError("SimConnect_ClearDataDefinition", r1)
+}
+
+// RequestDataOnSimObject implements SimConnect_RequestDataOnSimObject
+func (t *dllTransport) RequestDataOnSimObject(requestID SimObjectDataRequestID, defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, period SIMCONNECT_PERIOD, flags SIMCONNECT_DATA_REQUEST_FLAG, origin, interval, limit uint32) error {
+	// HRESULT SimConnect_RequestDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID,
+	//                                           SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID,
+	//                                           SIMCONNECT_PERIOD Period, SIMCONNECT_DATA_REQUEST_FLAG Flags,
+	//                                           DWORD origin, DWORD interval, DWORD limit)
+	r1, _, _ := t.dll.NewProc("SimConnect_RequestDataOnSimObject").Call(
+		t.handle,           // hSimConnect
+		uintptr(requestID), // RequestID
+		uintptr(defineID),  // DefineID
+		uintptr(objectID),  // ObjectID
+		uintptr(period),    // Period
+		uintptr(flags),     // Flags
+		uintptr(origin),    // origin
+		uintptr(interval),  // interval
+		uintptr(limit),     // limit
+	)
+	return hresultError("SimConnect_RequestDataOnSimObject", r1)
+}
+
+// SetDataOnSimObject implements SimConnect_SetDataOnSimObject
+func (t *dllTransport) SetDataOnSimObject(defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, flags SIMCONNECT_DATA_SET_FLAG, arrayCount, unitSize uint32, data []byte) error {
+	// HRESULT SimConnect_SetDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID,
+	//                                       SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_SET_FLAG Flags,
+	//                                       DWORD ArrayCount, DWORD cbUnitSize, void* pDataSet)
+	r1, _, _ := t.dll.NewProc("SimConnect_SetDataOnSimObject").Call(
+		t.handle,                          // hSimConnect
+		uintptr(defineID),                 // DefineID
+		uintptr(objectID),                 // ObjectID
+		uintptr(flags),                    // Flags
+		uintptr(arrayCount),               // ArrayCount
+		uintptr(unitSize),                 // cbUnitSize
+		uintptr(unsafe.Pointer(&data[0])), // pDataSet
+	)
+	return hresultError("SimConnect_SetDataOnSimObject", r1)
+}
+
+// RequestSystemState implements SimConnect_RequestSystemState
+func (t *dllTransport) RequestSystemState(requestID DataRequestID, state string) error {
+	// Convert state string to null-terminated byte array
+	stateBytes, err := syscall.BytePtrFromString(state)
+	if err != nil {
+		return fmt.Errorf("failed to convert state to bytes: %v", err)
+	}
+
+	// HRESULT SimConnect_RequestSystemState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char* szState)
+	r1, _, _ := t.dll.NewProc("SimConnect_RequestSystemState").Call(
+		t.handle,                            // hSimConnect
+		uintptr(requestID),                  // RequestID
+		uintptr(unsafe.Pointer(stateBytes)), // szState
+	)
+	return hresultError("SimConnect_RequestSystemState", r1)
+}
+
+// MapClientEventToSimEvent implements SimConnect_MapClientEventToSimEvent
+func (t *dllTransport) MapClientEventToSimEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, eventName string) error {
+	// Convert event name to null-terminated byte array
+	eventNameBytes, err := syscall.BytePtrFromString(eventName)
+	if err != nil {
+		return fmt.Errorf("failed to convert event name to bytes: %v", err)
+	}
+
+	// HRESULT SimConnect_MapClientEventToSimEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char* EventName)
+	r1, _, _ := t.dll.NewProc("SimConnect_MapClientEventToSimEvent").Call(
+		t.handle,                                // hSimConnect
+		uintptr(eventID),                        // EventID
+		uintptr(unsafe.Pointer(eventNameBytes)), // EventName
+	)
+	return hresultError("SimConnect_MapClientEventToSimEvent", r1)
+}
+
+// TransmitClientEvent implements SimConnect_TransmitClientEvent
+func (t *dllTransport) TransmitClientEvent(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, data uint32, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG) error {
+	// HRESULT SimConnect_TransmitClientEvent(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_CLIENT_EVENT_ID EventID,
+	//                                        DWORD dwData, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_EVENT_FLAG Flags)
+	r1, _, _ := t.dll.NewProc("SimConnect_TransmitClientEvent").Call(
+		t.handle,          // hSimConnect
+		uintptr(objectID), // ObjectID
+		uintptr(eventID),  // EventID
+		uintptr(data),     // dwData
+		uintptr(groupID),  // GroupID (or priority with SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY)
+		uintptr(flags),    // Flags
+	)
+	return hresultError("SimConnect_TransmitClientEvent", r1)
+}
+
+// TransmitClientEvent_EX1 implements SimConnect_TransmitClientEvent_EX1
+func (t *dllTransport) TransmitClientEvent_EX1(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG, data [5]uint32) error {
+	proc, err := t.optionalProc("SimConnect_TransmitClientEvent_EX1")
+	if err != nil {
+		return err
+	}
+
+	// HRESULT SimConnect_TransmitClientEvent_EX1(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_CLIENT_EVENT_ID EventID,
+	//                                            SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_EVENT_FLAG Flags,
+	//                                            DWORD dwData0, DWORD dwData1, DWORD dwData2, DWORD dwData3, DWORD dwData4)
+	r1, _, _ := proc.Call(
+		t.handle,          // hSimConnect
+		uintptr(objectID), // ObjectID
+		uintptr(eventID),  // EventID
+		uintptr(groupID),  // GroupID (or priority with SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY)
+		uintptr(flags),    // Flags
+		uintptr(data[0]),  // dwData0
+		uintptr(data[1]),  // dwData1
+		uintptr(data[2]),  // dwData2
+		uintptr(data[3]),  // dwData3
+		uintptr(data[4]),  // dwData4
+	)
+	return hresultError("SimConnect_TransmitClientEvent_EX1", r1)
+}
+
+// AddClientEventToNotificationGroup implements SimConnect_AddClientEventToNotificationGroup
+func (t *dllTransport) AddClientEventToNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, maskable bool) error {
+	var bMaskable uintptr // Win32 BOOL
+	if maskable {
+		bMaskable = 1
+	}
+
+	// HRESULT SimConnect_AddClientEventToNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID,
+	//                                                      SIMCONNECT_CLIENT_EVENT_ID EventID, BOOL bMaskable)
+	r1, _, _ := t.dll.NewProc("SimConnect_AddClientEventToNotificationGroup").Call(
+		t.handle,         // hSimConnect
+		uintptr(groupID), // GroupID
+		uintptr(eventID), // EventID
+		bMaskable,        // bMaskable
+	)
+	return hresultError("SimConnect_AddClientEventToNotificationGroup", r1)
+}
+
+// RemoveClientEvent implements SimConnect_RemoveClientEvent
+func (t *dllTransport) RemoveClientEvent(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	// HRESULT SimConnect_RemoveClientEvent(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID)
+	r1, _, _ := t.dll.NewProc("SimConnect_RemoveClientEvent").Call(
+		t.handle,         // hSimConnect
+		uintptr(groupID), // GroupID
+		uintptr(eventID), // EventID
+	)
+	return hresultError("SimConnect_RemoveClientEvent", r1)
+}
+
+// SetNotificationGroupPriority implements SimConnect_SetNotificationGroupPriority
+func (t *dllTransport) SetNotificationGroupPriority(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error {
+	// HRESULT SimConnect_SetNotificationGroupPriority(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, DWORD uPriority)
+	r1, _, _ := t.dll.NewProc("SimConnect_SetNotificationGroupPriority").Call(
+		t.handle,          // hSimConnect
+		uintptr(groupID),  // GroupID
+		uintptr(priority), // uPriority
+	)
+	return hresultError("SimConnect_SetNotificationGroupPriority", r1)
+}
+
+// ClearNotificationGroup implements SimConnect_ClearNotificationGroup
+func (t *dllTransport) ClearNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID) error {
+	// HRESULT SimConnect_ClearNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID)
+	r1, _, _ := t.dll.NewProc("SimConnect_ClearNotificationGroup").Call(
+		t.handle,         // hSimConnect
+		uintptr(groupID), // GroupID
+	)
+	return hresultError("SimConnect_ClearNotificationGroup", r1)
+}
+
+// MapInputEventToClientEvent implements SimConnect_MapInputEventToClientEvent
+func (t *dllTransport) MapInputEventToClientEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string, downEventID SIMCONNECT_CLIENT_EVENT_ID, downValue uint32, upEventID SIMCONNECT_CLIENT_EVENT_ID, upValue uint32, maskable bool) error {
+	// Convert input definition to null-terminated byte array
+	inputDefinitionBytes, err := syscall.BytePtrFromString(inputDefinition)
+	if err != nil {
+		return fmt.Errorf("failed to convert input definition to bytes: %v", err)
+	}
+
+	var bMaskable uintptr // Win32 BOOL
+	if maskable {
+		bMaskable = 1
+	}
+
+	// HRESULT SimConnect_MapInputEventToClientEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char* szInputDefinition,
+	//                                               SIMCONNECT_CLIENT_EVENT_ID DownEventID, DWORD DownValue,
+	//                                               SIMCONNECT_CLIENT_EVENT_ID UpEventID, DWORD UpValue, BOOL bMaskable)
+	r1, _, _ := t.dll.NewProc("SimConnect_MapInputEventToClientEvent").Call(
+		t.handle,         // hSimConnect
+		uintptr(groupID), // GroupID
+		uintptr(unsafe.Pointer(inputDefinitionBytes)), // szInputDefinition
+		uintptr(downEventID),                          // DownEventID
+		uintptr(downValue),                            // DownValue
+		uintptr(upEventID),                            // UpEventID
+		uintptr(upValue),                              // UpValue
+		bMaskable,                                     // bMaskable
+	)
+	return hresultError("SimConnect_MapInputEventToClientEvent", r1)
+}
+
+// SetInputGroupPriority implements SimConnect_SetInputGroupPriority
+func (t *dllTransport) SetInputGroupPriority(groupID SIMCONNECT_INPUT_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error {
+	// HRESULT SimConnect_SetInputGroupPriority(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD uPriority)
+	r1, _, _ := t.dll.NewProc("SimConnect_SetInputGroupPriority").Call(
+		t.handle,          // hSimConnect
+		uintptr(groupID),  // GroupID
+		uintptr(priority), // uPriority
+	)
+	return hresultError("SimConnect_SetInputGroupPriority", r1)
+}
+
+// RemoveInputEvent implements SimConnect_RemoveInputEvent
+func (t *dllTransport) RemoveInputEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string) error {
+	// Convert input definition to null-terminated byte array
+	inputDefinitionBytes, err := syscall.BytePtrFromString(inputDefinition)
+	if err != nil {
+		return fmt.Errorf("failed to convert input definition to bytes: %v", err)
+	}
+
+	// HRESULT SimConnect_RemoveInputEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char* szInputDefinition)
+	r1, _, _ := t.dll.NewProc("SimConnect_RemoveInputEvent").Call(
+		t.handle,         // hSimConnect
+		uintptr(groupID), // GroupID
+		uintptr(unsafe.Pointer(inputDefinitionBytes)), // szInputDefinition
+	)
+	return hresultError("SimConnect_RemoveInputEvent", r1)
+}
+
+// ClearInputGroup implements SimConnect_ClearInputGroup
+func (t *dllTransport) ClearInputGroup(groupID SIMCONNECT_INPUT_GROUP_ID) error {
+	// HRESULT SimConnect_ClearInputGroup(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID)
+	r1, _, _ := t.dll.NewProc("SimConnect_ClearInputGroup").Call(
+		t.handle,         // hSimConnect
+		uintptr(groupID), // GroupID
+	)
+	return hresultError("SimConnect_ClearInputGroup", r1)
+}
+
+// SetInputGroupState implements SimConnect_SetInputGroupState
+func (t *dllTransport) SetInputGroupState(groupID SIMCONNECT_INPUT_GROUP_ID, state SIMCONNECT_STATE) error {
+	// HRESULT SimConnect_SetInputGroupState(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD dwState)
+	r1, _, _ := t.dll.NewProc("SimConnect_SetInputGroupState").Call(
+		t.handle,         // hSimConnect
+		uintptr(groupID), // GroupID
+		uintptr(state),   // dwState
+	)
+	return hresultError("SimConnect_SetInputGroupState", r1)
+}
+
+// RequestReservedKey implements SimConnect_RequestReservedKey
+func (t *dllTransport) RequestReservedKey(eventID SIMCONNECT_CLIENT_EVENT_ID, keyChoice1, keyChoice2, keyChoice3 string) error {
+	// Convert key choices to null-terminated byte arrays
+	var choices [3]*byte
+	for i, choice := range []string{keyChoice1, keyChoice2, keyChoice3} {
+		choiceBytes, err := syscall.BytePtrFromString(choice)
+		if err != nil {
+			return fmt.Errorf("failed to convert key choice to bytes: %v", err)
+		}
+		choices[i] = choiceBytes
+	}
+
+	// HRESULT SimConnect_RequestReservedKey(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID,
+	//                                       const char* szKeyChoice1, const char* szKeyChoice2, const char* szKeyChoice3)
+	r1, _, _ := t.dll.NewProc("SimConnect_RequestReservedKey").Call(
+		t.handle,                            // hSimConnect
+		uintptr(eventID),                    // EventID
+		uintptr(unsafe.Pointer(choices[0])), // szKeyChoice1
+		uintptr(unsafe.Pointer(choices[1])), // szKeyChoice2
+		uintptr(unsafe.Pointer(choices[2])), // szKeyChoice3
+	)
+	return hresultError("SimConnect_RequestReservedKey", r1)
+}
+
+// SubscribeToSystemEvent implements SimConnect_SubscribeToSystemEvent
+func (t *dllTransport) SubscribeToSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, systemEventName string) error {
+	// Convert system event name to null-terminated byte array
+	eventNameBytes, err := syscall.BytePtrFromString(systemEventName)
+	if err != nil {
+		return fmt.Errorf("failed to convert system event name to bytes: %v", err)
+	}
+
+	// HRESULT SimConnect_SubscribeToSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char* SystemEventName)
+	r1, _, _ := t.dll.NewProc("SimConnect_SubscribeToSystemEvent").Call(
+		t.handle,                                // hSimConnect
+		uintptr(eventID),                        // EventID
+		uintptr(unsafe.Pointer(eventNameBytes)), // SystemEventName
+	)
+	return hresultError("SimConnect_SubscribeToSystemEvent", r1)
+}
+
+// UnsubscribeFromSystemEvent implements SimConnect_UnsubscribeFromSystemEvent
+func (t *dllTransport) UnsubscribeFromSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	// HRESULT SimConnect_UnsubscribeFromSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID)
+	r1, _, _ := t.dll.NewProc("SimConnect_UnsubscribeFromSystemEvent").Call(
+		t.handle,         // hSimConnect
+		uintptr(eventID), // EventID
+	)
+	return hresultError("SimConnect_UnsubscribeFromSystemEvent", r1)
+}
+
+// SetSystemEventState implements SimConnect_SetSystemEventState
+func (t *dllTransport) SetSystemEventState(eventID SIMCONNECT_CLIENT_EVENT_ID, state SIMCONNECT_STATE) error {
+	// HRESULT SimConnect_SetSystemEventState(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, SIMCONNECT_STATE State)
+	r1, _, _ := t.dll.NewProc("SimConnect_SetSystemEventState").Call(
+		t.handle,         // hSimConnect
+		uintptr(eventID), // EventID
+		uintptr(state),   // State (ON/OFF)
+	)
+	return hresultError("SimConnect_SetSystemEventState", r1)
+}
+
+// EnumerateInputEvents implements SimConnect_EnumerateInputEvents
+func (t *dllTransport) EnumerateInputEvents(requestID DataRequestID) error {
+	proc, err := t.optionalProc("SimConnect_EnumerateInputEvents")
+	if err != nil {
+		return err
+	}
+
+	// HRESULT SimConnect_EnumerateInputEvents(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID)
+	r1, _, _ := proc.Call(
+		t.handle,           // hSimConnect
+		uintptr(requestID), // RequestID
+	)
+	return hresultError("SimConnect_EnumerateInputEvents", r1)
+}
+
+// GetInputEvent implements SimConnect_GetInputEvent
+func (t *dllTransport) GetInputEvent(requestID DataRequestID, hash uint64) error {
+	proc, err := t.optionalProc("SimConnect_GetInputEvent")
+	if err != nil {
+		return err
+	}
+
+	// HRESULT SimConnect_GetInputEvent(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, UINT64 Hash)
+	r1, _, _ := proc.Call(
+		t.handle,           // hSimConnect
+		uintptr(requestID), // RequestID
+		uintptr(hash),      // Hash, the MSFS 2024 SimConnect.dll is 64-bit only
+	)
+	return hresultError("SimConnect_GetInputEvent", r1)
+}
+
+// SetInputEvent implements SimConnect_SetInputEvent
+func (t *dllTransport) SetInputEvent(hash uint64, value []byte) error {
+	proc, err := t.optionalProc("SimConnect_SetInputEvent")
+	if err != nil {
+		return err
+	}
+
+	// HRESULT SimConnect_SetInputEvent(HANDLE hSimConnect, UINT64 Hash, DWORD cbUnitSize, void* Value)
+	r1, _, _ := proc.Call(
+		t.handle,                           // hSimConnect
+		uintptr(hash),                      // Hash
+		uintptr(len(value)),                // cbUnitSize
+		uintptr(unsafe.Pointer(&value[0])), // Value (double or NUL-terminated string)
+	)
+	return hresultError("SimConnect_SetInputEvent", r1)
+}
+
+// SubscribeInputEvent implements SimConnect_SubscribeInputEvent
+func (t *dllTransport) SubscribeInputEvent(hash uint64) error {
+	proc, err := t.optionalProc("SimConnect_SubscribeInputEvent")
+	if err != nil {
+		return err
+	}
+
+	// HRESULT SimConnect_SubscribeInputEvent(HANDLE hSimConnect, UINT64 Hash)
+	r1, _, _ := proc.Call(
+		t.handle,      // hSimConnect
+		uintptr(hash), // Hash
+	)
+	return hresultError("SimConnect_SubscribeInputEvent", r1)
+}
+
+// UnsubscribeInputEvent implements SimConnect_UnsubscribeInputEvent
+func (t *dllTransport) UnsubscribeInputEvent(hash uint64) error {
+	proc, err := t.optionalProc("SimConnect_UnsubscribeInputEvent")
+	if err != nil {
+		return err
+	}
+
+	// HRESULT SimConnect_UnsubscribeInputEvent(HANDLE hSimConnect, UINT64 Hash)
+	r1, _, _ := proc.Call(
+		t.handle,      // hSimConnect
+		uintptr(hash), // Hash
+	)
+	return hresultError("SimConnect_UnsubscribeInputEvent", r1)
+}
+
+// EnumerateInputEventParams implements SimConnect_EnumerateInputEventParams
+func (t *dllTransport) EnumerateInputEventParams(hash uint64) error {
+	proc, err := t.optionalProc("SimConnect_EnumerateInputEventParams")
+	if err != nil {
+		return err
+	}
+
+	// HRESULT SimConnect_EnumerateInputEventParams(HANDLE hSimConnect, UINT64 Hash)
+	r1, _, _ := proc.Call(
+		t.handle,      // hSimConnect
+		uintptr(hash), // Hash
+	)
+	return hresultError("SimConnect_EnumerateInputEventParams", r1)
+}
+
+// optionalProc returns a procedure only newer SimConnect.dll versions export,
+// reporting E_NOTIMPL instead of the panic calling a missing procedure would cause
+func (t *dllTransport) optionalProc(function string) (*syscall.LazyProc, error) {
+	proc := t.dll.NewProc(function)
+	if err := proc.Find(); err != nil {
+		return nil, NewSimConnectError(function, E_NOTIMPL, err.Error())
+	}
+	return proc, nil
+}
+
+// GetLastSentPacketID implements SimConnect_GetLastSentPacketID
+func (t *dllTransport) GetLastSentPacketID() (uint32, error) {
+	var sendID uint32
+
+	// HRESULT SimConnect_GetLastSentPacketID(HANDLE hSimConnect, DWORD* pdwSendID)
+	r1, _, _ := t.dll.NewProc("SimConnect_GetLastSentPacketID").Call(
+		t.handle,
+		uintptr(unsafe.Pointer(&sendID)), // pdwSendID
+	)
+	if err := hresultError("SimConnect_GetLastSentPacketID", r1); err != nil {
+		return 0, err
+	}
+
+	return sendID, nil
+}
+
+// GetNextDispatch implements SimConnect_GetNextDispatch
+func (t *dllTransport) GetNextDispatch() ([]byte, error) {
+	proc := t.dll.NewProc("SimConnect_GetNextDispatch")
+
+	var pData *byte
+	var cbData uint32
+
+	// HRESULT SimConnect_GetNextDispatch(HANDLE hSimConnect, SIMCONNECT_RECV** ppData, DWORD* pcbData)
+	r1, _, _ := proc.Call(
+		t.handle,                         // hSimConnect
+		uintptr(unsafe.Pointer(&pData)),  // ppData
+		uintptr(unsafe.Pointer(&cbData)), // pcbData
+	)
+
+	hresult := uint32(r1)
+
+	// Handle E_FAIL as "no data available" - this is normal behavior
+	if hresult == E_FAIL {
+		return nil, nil // No message available in queue
+	}
+
+	if !IsHRESULTSuccess(hresult) {
+		return nil, NewSimConnectError("SimConnect_GetNextDispatch", hresult, GetHRESULTMessage(hresult))
+	}
+
+	// Check if we have data
+	if pData == nil || cbData == 0 {
+		return nil, nil // No message available
+	}
+
+	// Copy the data from the SimConnect-managed memory to our own buffer
+	buffer := make([]byte, cbData)
+	copy(buffer, unsafe.Slice(pData, cbData))
+
+	return buffer, nil
+}
+
+// WaitForMessage blocks on the event handle passed to SimConnect_Open until SimConnect
+// signals new messages, Close is called or ctx is done
+func (t *dllTransport) WaitForMessage(ctx context.Context) error {
+	if t.messageEvent == 0 || t.wakeEvent == 0 {
+		return fmt.Errorf("event handles are not available")
+	}
+
+	stop := context.AfterFunc(ctx, t.wake)
+	defer stop()
+
+	// DWORD WaitForMultipleObjects(DWORD nCount, const HANDLE* lpHandles, BOOL bWaitAll, DWORD dwMilliseconds)
+	handles := [2]uintptr{t.messageEvent, t.wakeEvent}
+	r1, _, callErr := procWaitForMultipleObjects.Call(
+		uintptr(len(handles)),                // nCount
+		uintptr(unsafe.Pointer(&handles[0])), // lpHandles
+		0,                                    // bWaitAll (FALSE, wake on either event)
+		win32Infinite,                        // dwMilliseconds
+	)
+	if uint32(r1) == win32WaitFailed {
+		return fmt.Errorf("WaitForMultipleObjects failed: %v", callErr)
+	}
+
+	return ctx.Err()
+}
+
+// wake interrupts a WaitForMessage call
+func (t *dllTransport) wake() {
+	if t.wakeEvent != 0 {
+		procSetEvent.Call(t.wakeEvent)
+	}
+}
+
+// Handle returns the raw SimConnect handle
+func (t *dllTransport) Handle() uintptr {
+	return t.handle
+}
+
+// outputDebugString sends a message to the Windows debug console
+func outputDebugString(message string) error {
+	// Get the OutputDebugStringA function from kernel32.dll
+	outputDebugStringA := kernel32.NewProc("OutputDebugStringA")
+
+	// Convert message string to null-terminated byte array
+	messageBytes, err := syscall.BytePtrFromString(message)
+	if err != nil {
+		return fmt.Errorf("failed to convert message to bytes: %v", err)
+	}
+
+	// void OutputDebugStringA(LPCSTR lpOutputString)
+	outputDebugStringA.Call(uintptr(unsafe.Pointer(messageBytes)))
+	return nil
+}
@@ -1,6 +1,9 @@
 package client
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // SimConnectError represents a SimConnect-specific error
 type SimConnectError struct {
@@ -42,3 +45,37 @@ func GetHRESULTMessage(hresult uint32) string {
 		return fmt.Sprintf("Unknown error (0x%08X)", hresult)
 	}
 }
+
+// Malformed message kinds reported through MessageError
+var (
+	ErrMessageTruncated    = errors.New("message truncated")         // Buffer is shorter than the message layout
+	ErrMessageSizeMismatch = errors.New("message size mismatch")     // DwSize does not match the buffer
+	ErrMessageType         = errors.New("unexpected message type")   // Parser called for another SIMCONNECT_RECV_ID
+	ErrMessageInvalid      = errors.New("inconsistent message data") // Fields contradict each other or the buffer
+)
+
+// MessageError reports a malformed SimConnect message.
+// Use errors.Is with one of the ErrMessage* kinds to check the cause.
+type MessageError struct {
+	RecvID uint32 // SIMCONNECT_RECV_ID of the message, 0 if the header could not be read
+	Kind   error  // ErrMessageTruncated, ErrMessageSizeMismatch, ErrMessageType or ErrMessageInvalid
+	Detail string // Description of the problem
+}
+
+func (e *MessageError) Error() string {
+	return fmt.Sprintf("SimConnect message 0x%08X: %v: %s", e.RecvID, e.Kind, e.Detail)
+}
+
+// Unwrap returns the error kind
+func (e *MessageError) Unwrap() error {
+	return e.Kind
+}
+
+// newMessageError creates a new MessageError
+func newMessageError(recvID uint32, kind error, format string, args ...interface{}) *MessageError {
+	return &MessageError{
+		RecvID: recvID,
+		Kind:   kind,
+		Detail: fmt.Sprintf(format, args...),
+	}
+}
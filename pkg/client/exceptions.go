@@ -0,0 +1,232 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SIMCONNECT_EXCEPTION codes reported in SIMCONNECT_RECV_EXCEPTION
+type SIMCONNECT_EXCEPTION uint32
+
+const (
+	SIMCONNECT_EXCEPTION_NONE                              SIMCONNECT_EXCEPTION = 0
+	SIMCONNECT_EXCEPTION_ERROR                             SIMCONNECT_EXCEPTION = 1
+	SIMCONNECT_EXCEPTION_SIZE_MISMATCH                     SIMCONNECT_EXCEPTION = 2
+	SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID                   SIMCONNECT_EXCEPTION = 3
+	SIMCONNECT_EXCEPTION_UNOPENED                          SIMCONNECT_EXCEPTION = 4
+	SIMCONNECT_EXCEPTION_VERSION_MISMATCH                  SIMCONNECT_EXCEPTION = 5
+	SIMCONNECT_EXCEPTION_TOO_MANY_GROUPS                   SIMCONNECT_EXCEPTION = 6
+	SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED                 SIMCONNECT_EXCEPTION = 7
+	SIMCONNECT_EXCEPTION_TOO_MANY_EVENT_NAMES              SIMCONNECT_EXCEPTION = 8
+	SIMCONNECT_EXCEPTION_EVENT_ID_DUPLICATE                SIMCONNECT_EXCEPTION = 9
+	SIMCONNECT_EXCEPTION_TOO_MANY_MAPS                     SIMCONNECT_EXCEPTION = 10
+	SIMCONNECT_EXCEPTION_TOO_MANY_OBJECTS                  SIMCONNECT_EXCEPTION = 11
+	SIMCONNECT_EXCEPTION_TOO_MANY_REQUESTS                 SIMCONNECT_EXCEPTION = 12
+	SIMCONNECT_EXCEPTION_WEATHER_INVALID_PORT              SIMCONNECT_EXCEPTION = 13
+	SIMCONNECT_EXCEPTION_WEATHER_INVALID_METAR             SIMCONNECT_EXCEPTION = 14
+	SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION SIMCONNECT_EXCEPTION = 15
+	SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_CREATE_STATION  SIMCONNECT_EXCEPTION = 16
+	SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_REMOVE_STATION  SIMCONNECT_EXCEPTION = 17
+	SIMCONNECT_EXCEPTION_INVALID_DATA_TYPE                 SIMCONNECT_EXCEPTION = 18
+	SIMCONNECT_EXCEPTION_INVALID_DATA_SIZE                 SIMCONNECT_EXCEPTION = 19
+	SIMCONNECT_EXCEPTION_DATA_ERROR                        SIMCONNECT_EXCEPTION = 20
+	SIMCONNECT_EXCEPTION_INVALID_ARRAY                     SIMCONNECT_EXCEPTION = 21
+	SIMCONNECT_EXCEPTION_CREATE_OBJECT_FAILED              SIMCONNECT_EXCEPTION = 22
+	SIMCONNECT_EXCEPTION_LOAD_FLIGHTPLAN_FAILED            SIMCONNECT_EXCEPTION = 23
+	SIMCONNECT_EXCEPTION_OPERATION_INVALID_FOR_OBJECT_TYPE SIMCONNECT_EXCEPTION = 24
+	SIMCONNECT_EXCEPTION_ILLEGAL_OPERATION                 SIMCONNECT_EXCEPTION = 25
+	SIMCONNECT_EXCEPTION_ALREADY_SUBSCRIBED                SIMCONNECT_EXCEPTION = 26
+	SIMCONNECT_EXCEPTION_INVALID_ENUM                      SIMCONNECT_EXCEPTION = 27
+	SIMCONNECT_EXCEPTION_DEFINITION_ERROR                  SIMCONNECT_EXCEPTION = 28
+	SIMCONNECT_EXCEPTION_DUPLICATE_ID                      SIMCONNECT_EXCEPTION = 29
+	SIMCONNECT_EXCEPTION_DATUM_ID                          SIMCONNECT_EXCEPTION = 30
+	SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS                     SIMCONNECT_EXCEPTION = 31
+	SIMCONNECT_EXCEPTION_ALREADY_CREATED                   SIMCONNECT_EXCEPTION = 32
+	SIMCONNECT_EXCEPTION_OBJECT_OUTSIDE_REALITY_BUBBLE     SIMCONNECT_EXCEPTION = 33
+	SIMCONNECT_EXCEPTION_OBJECT_CONTAINER                  SIMCONNECT_EXCEPTION = 34
+	SIMCONNECT_EXCEPTION_OBJECT_AI                         SIMCONNECT_EXCEPTION = 35
+	SIMCONNECT_EXCEPTION_OBJECT_ATC                        SIMCONNECT_EXCEPTION = 36
+	SIMCONNECT_EXCEPTION_OBJECT_SCHEDULE                   SIMCONNECT_EXCEPTION = 37
+	SIMCONNECT_EXCEPTION_JETWAY_DATA                       SIMCONNECT_EXCEPTION = 38
+	SIMCONNECT_EXCEPTION_ACTION_NOT_FOUND                  SIMCONNECT_EXCEPTION = 39
+	SIMCONNECT_EXCEPTION_NOT_AN_ACTION                     SIMCONNECT_EXCEPTION = 40
+	SIMCONNECT_EXCEPTION_INCORRECT_ACTION_PARAMS           SIMCONNECT_EXCEPTION = 41
+	SIMCONNECT_EXCEPTION_GET_INPUT_EVENT_FAILED            SIMCONNECT_EXCEPTION = 42
+	SIMCONNECT_EXCEPTION_SET_INPUT_EVENT_FAILED            SIMCONNECT_EXCEPTION = 43
+)
+
+// exceptionNames maps exception codes to their SimConnect.h names without prefix
+var exceptionNames = map[SIMCONNECT_EXCEPTION]string{
+	SIMCONNECT_EXCEPTION_NONE:                              "NONE",
+	SIMCONNECT_EXCEPTION_ERROR:                             "ERROR",
+	SIMCONNECT_EXCEPTION_SIZE_MISMATCH:                     "SIZE_MISMATCH",
+	SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID:                   "UNRECOGNIZED_ID",
+	SIMCONNECT_EXCEPTION_UNOPENED:                          "UNOPENED",
+	SIMCONNECT_EXCEPTION_VERSION_MISMATCH:                  "VERSION_MISMATCH",
+	SIMCONNECT_EXCEPTION_TOO_MANY_GROUPS:                   "TOO_MANY_GROUPS",
+	SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED:                 "NAME_UNRECOGNIZED",
+	SIMCONNECT_EXCEPTION_TOO_MANY_EVENT_NAMES:              "TOO_MANY_EVENT_NAMES",
+	SIMCONNECT_EXCEPTION_EVENT_ID_DUPLICATE:                "EVENT_ID_DUPLICATE",
+	SIMCONNECT_EXCEPTION_TOO_MANY_MAPS:                     "TOO_MANY_MAPS",
+	SIMCONNECT_EXCEPTION_TOO_MANY_OBJECTS:                  "TOO_MANY_OBJECTS",
+	SIMCONNECT_EXCEPTION_TOO_MANY_REQUESTS:                 "TOO_MANY_REQUESTS",
+	SIMCONNECT_EXCEPTION_WEATHER_INVALID_PORT:              "WEATHER_INVALID_PORT",
+	SIMCONNECT_EXCEPTION_WEATHER_INVALID_METAR:             "WEATHER_INVALID_METAR",
+	SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION: "WEATHER_UNABLE_TO_GET_OBSERVATION",
+	SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_CREATE_STATION:  "WEATHER_UNABLE_TO_CREATE_STATION",
+	SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_REMOVE_STATION:  "WEATHER_UNABLE_TO_REMOVE_STATION",
+	SIMCONNECT_EXCEPTION_INVALID_DATA_TYPE:                 "INVALID_DATA_TYPE",
+	SIMCONNECT_EXCEPTION_INVALID_DATA_SIZE:                 "INVALID_DATA_SIZE",
+	SIMCONNECT_EXCEPTION_DATA_ERROR:                        "DATA_ERROR",
+	SIMCONNECT_EXCEPTION_INVALID_ARRAY:                     "INVALID_ARRAY",
+	SIMCONNECT_EXCEPTION_CREATE_OBJECT_FAILED:              "CREATE_OBJECT_FAILED",
+	SIMCONNECT_EXCEPTION_LOAD_FLIGHTPLAN_FAILED:            "LOAD_FLIGHTPLAN_FAILED",
+	SIMCONNECT_EXCEPTION_OPERATION_INVALID_FOR_OBJECT_TYPE: "OPERATION_INVALID_FOR_OBJECT_TYPE",
+	SIMCONNECT_EXCEPTION_ILLEGAL_OPERATION:                 "ILLEGAL_OPERATION",
+	SIMCONNECT_EXCEPTION_ALREADY_SUBSCRIBED:                "ALREADY_SUBSCRIBED",
+	SIMCONNECT_EXCEPTION_INVALID_ENUM:                      "INVALID_ENUM",
+	SIMCONNECT_EXCEPTION_DEFINITION_ERROR:                  "DEFINITION_ERROR",
+	SIMCONNECT_EXCEPTION_DUPLICATE_ID:                      "DUPLICATE_ID",
+	SIMCONNECT_EXCEPTION_DATUM_ID:                          "DATUM_ID",
+	SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS:                     "OUT_OF_BOUNDS",
+	SIMCONNECT_EXCEPTION_ALREADY_CREATED:                   "ALREADY_CREATED",
+	SIMCONNECT_EXCEPTION_OBJECT_OUTSIDE_REALITY_BUBBLE:     "OBJECT_OUTSIDE_REALITY_BUBBLE",
+	SIMCONNECT_EXCEPTION_OBJECT_CONTAINER:                  "OBJECT_CONTAINER",
+	SIMCONNECT_EXCEPTION_OBJECT_AI:                         "OBJECT_AI",
+	SIMCONNECT_EXCEPTION_OBJECT_ATC:                        "OBJECT_ATC",
+	SIMCONNECT_EXCEPTION_OBJECT_SCHEDULE:                   "OBJECT_SCHEDULE",
+	SIMCONNECT_EXCEPTION_JETWAY_DATA:                       "JETWAY_DATA",
+	SIMCONNECT_EXCEPTION_ACTION_NOT_FOUND:                  "ACTION_NOT_FOUND",
+	SIMCONNECT_EXCEPTION_NOT_AN_ACTION:                     "NOT_AN_ACTION",
+	SIMCONNECT_EXCEPTION_INCORRECT_ACTION_PARAMS:           "INCORRECT_ACTION_PARAMS",
+	SIMCONNECT_EXCEPTION_GET_INPUT_EVENT_FAILED:            "GET_INPUT_EVENT_FAILED",
+	SIMCONNECT_EXCEPTION_SET_INPUT_EVENT_FAILED:            "SET_INPUT_EVENT_FAILED",
+}
+
+// String returns the SimConnect name of the exception, e.g. "NAME_UNRECOGNIZED"
+func (e SIMCONNECT_EXCEPTION) String() string {
+	if name, exists := exceptionNames[e]; exists {
+		return name
+	}
+	return fmt.Sprintf("EXCEPTION_%d", uint32(e))
+}
+
+// sentPacketHistory is the number of outgoing calls remembered for exception correlation
+const sentPacketHistory = 256
+
+// SentPacket describes an outgoing SimConnect call, recorded so that a later
+// SIMCONNECT_RECV_EXCEPTION can be traced back to the operation that caused it
+type SentPacket struct {
+	SendID       uint32                           // Packet ID from SimConnect_GetLastSentPacketID
+	Operation    string                           // Client method, e.g. "AddToDataDefinition"
+	Detail       string                           // Arguments identifying the call, e.g. "'PLANE ALTITUDE'"
+	DefineID     DataDefinitionID                 // Data definition used by the call, 0 if none
+	RequestID    uint32                           // Request ID used by the call, 0 if none
+	EventID      SIMCONNECT_CLIENT_EVENT_ID       // Client event ID used by the call, 0 if none
+	GroupID      SIMCONNECT_NOTIFICATION_GROUP_ID // Notification group used by the call, 0 if none
+	InputGroupID SIMCONNECT_INPUT_GROUP_ID        // Input group used by the call, 0 if none
+	InputEvent   uint64                           // Input event hash used by the call, 0 if none
+	owner        interface{}                      // Manager that made the call, nil for direct calls
+}
+
+// ExceptionError is a SIMCONNECT_RECV_EXCEPTION matched with the call that caused it
+type ExceptionError struct {
+	Exception SIMCONNECT_EXCEPTION // Exception code
+	SendID    uint32               // Packet ID of the failing call
+	Index     uint32               // Index of the offending parameter, if known
+	Packet    *SentPacket          // Originating call, nil if it is no longer known
+}
+
+func (e *ExceptionError) Error() string {
+	if e.Packet == nil {
+		return fmt.Sprintf("SimConnect exception %s (send ID %d, parameter %d)", e.Exception, e.SendID, e.Index)
+	}
+	if e.Packet.Detail == "" {
+		return fmt.Sprintf("%s : %s", e.Packet.Operation, e.Exception)
+	}
+	return fmt.Sprintf("%s %s : %s", e.Packet.Operation, e.Packet.Detail, e.Exception)
+}
+
+// sentPackets is a fixed-size ring of recently sent packets
+type sentPackets struct {
+	mutex   sync.Mutex
+	packets [sentPacketHistory]SentPacket
+	next    int
+}
+
+// add records a sent packet, overwriting the oldest one
+func (s *sentPackets) add(packet SentPacket) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.packets[s.next] = packet
+	s.next = (s.next + 1) % sentPacketHistory
+}
+
+// lookup finds a recorded packet by send ID
+func (s *sentPackets) lookup(sendID uint32) (SentPacket, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, packet := range s.packets {
+		if packet.SendID == sendID && packet.Operation != "" {
+			return packet, true
+		}
+	}
+	return SentPacket{}, false
+}
+
+// reset forgets all packets, send IDs restart with every connection
+func (s *sentPackets) reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.packets = [sentPacketHistory]SentPacket{}
+	s.next = 0
+}
+
+// send performs a transport call and records its packet ID for exception correlation
+func (c *Client) send(packet SentPacket, call func() error) error {
+	// The call and GetLastSentPacketID run as one job on the worker so no other call interleaves
+	err := c.worker.call(func() error {
+		return c.record(packet, call)
+	})
+	c.observeError(err) // Outside the worker, state handlers may call the client
+	return err
+}
+
+// record performs a transport call and records its packet ID; it runs on the worker
+func (c *Client) record(packet SentPacket, call func() error) error {
+	if err := call(); err != nil {
+		return err
+	}
+
+	sendID, err := c.transport.GetLastSentPacketID()
+	if err != nil {
+		return nil // The call itself succeeded, only correlation is lost
+	}
+
+	packet.SendID = sendID
+	c.sent.add(packet)
+	return nil
+}
+
+// LookupSentPacket returns the recorded call with the given packet ID.
+// Only the most recent calls are remembered.
+func (c *Client) LookupSentPacket(sendID uint32) (SentPacket, bool) {
+	return c.sent.lookup(sendID)
+}
+
+// ExceptionError matches an exception message with the call that caused it
+func (c *Client) ExceptionError(exception *SIMCONNECT_RECV_EXCEPTION) *ExceptionError {
+	err := &ExceptionError{
+		Exception: exception.Exception,
+		SendID:    exception.SendID,
+		Index:     exception.Index,
+	}
+
+	if packet, exists := c.sent.lookup(exception.SendID); exists {
+		err.Packet = &packet
+	}
+	return err
+}
@@ -1,44 +1,131 @@
 package client
 
 import (
+	"context"
+	"encoding/binary"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
-	"unsafe"
 )
 
 // FlightVariable represents a simulation variable definition
 type FlightVariable struct {
-	Name     string    // Human-readable name
-	SimVar   string    // SimConnect variable name
-	Units    string    // Units of measurement
-	Value    float64   // Current value
-	Updated  time.Time // Last update time
-	Writable bool      // Whether this variable can be written to (added for SetData support)
+	Name     string              // Human-readable name
+	SimVar   string              // SimConnect variable name
+	Units    string              // Units of measurement
+	DataType SIMCONNECT_DATATYPE // Type requested from SimConnect
+	Value    float64             // Current value of numeric and boolean variables (0 or 1)
+	Data     interface{}         // Current typed value: float64, float32, int32, int64, bool, string or a SIMCONNECT_DATA_* structure
+	Updated  time.Time           // Last update time
+	Writable bool                // Whether this variable can be written to (added for SetData support)
 }
 
-// FlightDataManager manages real-time flight simulation data using separate data definitions
+// isBool reports whether the variable is a boolean, requested as a number with units "bool"
+func (v FlightVariable) isBool() bool {
+	return strings.EqualFold(v.Units, "bool") && v.DataType <= SIMCONNECT_DATATYPE_FLOAT64
+}
+
+// setData stores a decoded value in Data and, for numbers and booleans, in Value
+func (v *FlightVariable) setData(data interface{}) {
+	v.Value = 0
+	switch value := data.(type) {
+	case float64:
+		v.Value = value
+	case float32:
+		v.Value = float64(value)
+	case int32:
+		v.Value = float64(value)
+	case int64:
+		v.Value = float64(value)
+	}
+	if v.isBool() {
+		data = v.Value != 0
+	}
+	v.Data = data
+}
+
+// FlightDataManager manages real-time flight simulation data. Variables with the same update
+// period share one data definition and one request, so SimConnect sends them together in a
+// single message and all values of a group are read in the same simulation frame.
+// Variables can be added, removed and retuned while running; only the affected group is re-requested.
 type FlightDataManager struct {
 	client      *Client
 	variables   []FlightVariable
-	definitions []DataDefinitionID
-	requests    []SimObjectDataRequestID
+	groups      []*dataGroup     // Shared definitions, one per update period
+	details     []variableDetail // SimConnect bookkeeping, parallel to variables
+	nextDatumID uint32           // Datum ID of the next variable
 	mutex       sync.RWMutex
 	running     bool
-	stopChan    chan bool
+	closed      bool          // Close was called, the manager cannot be used anymore
+	onError     HandlerID     // Exception handler registered by NewFlightDataManager
+	replayID    HandlerID     // Replay registered by NewFlightDataManager
+	done        chan struct{} // Closed by Stop, created by Start
 	errorChan   chan error
 	dataCount   int64
 	errorCount  int64
 	lastUpdate  time.Time
 }
 
+// VariableOptions controls how a FlightDataManager variable is requested.
+// Variables with the same Period, Flags, Origin, Interval and Limit share a data definition and request.
+type VariableOptions struct {
+	DataType SIMCONNECT_DATATYPE          // Requested type, 0 for FLOAT64 (STRING256 with units "string")
+	Writable bool                         // Whether the variable can be written to
+	Period   SIMCONNECT_PERIOD            // Update period, SIMCONNECT_PERIOD_NEVER is replaced by SIMCONNECT_PERIOD_SECOND
+	Flags    SIMCONNECT_DATA_REQUEST_FLAG // CHANGED to send only changes, TAGGED to send only the changed variables
+	Origin   uint32                       // Periods to wait before the first transmission
+	Interval uint32                       // Periods to skip between transmissions
+	Limit    uint32                       // Number of transmissions, 0 for no limit
+	Epsilon  float32                      // Changes smaller than this do not count for the CHANGED flag
+}
+
+// DefaultVariableOptions returns read-only FLOAT64 updates once per second when the value changes
+func DefaultVariableOptions() VariableOptions {
+	return VariableOptions{
+		Period: SIMCONNECT_PERIOD_SECOND,
+		Flags:  SIMCONNECT_DATA_REQUEST_FLAG_CHANGED,
+	}
+}
+
+// requestOptions are the RequestDataOnSimObject parameters of a group
+type requestOptions struct {
+	period   SIMCONNECT_PERIOD
+	flags    SIMCONNECT_DATA_REQUEST_FLAG
+	origin   uint32
+	interval uint32
+	limit    uint32
+}
+
+// dataGroup is a data definition shared by all variables with the same request options
+type dataGroup struct {
+	options   requestOptions         // Parameters of the group's request
+	defineID  DataDefinitionID       // Shared data definition
+	requestID SimObjectDataRequestID // Request for the shared definition
+	variables []int                  // Indexes into FlightDataManager.variables, in definition order
+	handler   HandlerID              // Dispatcher handler of the request, registered while running
+}
+
+// variableDetail is the SimConnect bookkeeping of a variable
+type variableDetail struct {
+	datumID     uint32           // Datum ID in the group's definition, identifies TAGGED values
+	epsilon     float32          // Change threshold for the CHANGED flag
+	setDefineID DataDefinitionID // Single-variable definition for writing, 0 for read-only variables
+}
+
 // NewFlightDataManager creates a new flight data manager
 func NewFlightDataManager(client *Client) *FlightDataManager {
-	return &FlightDataManager{
+	fdm := &FlightDataManager{
 		client:    client,
-		stopChan:  make(chan bool),
 		errorChan: make(chan error, 10), // Buffered channel for errors
 	}
+
+	// Exceptions caused by our definitions and requests are reported on our error channel
+	fdm.onError = client.Dispatcher().HandleException(fdm.handleException)
+	// Definitions and requests are re-created when a Supervisor reconnects the client
+	fdm.replayID = client.addReplay(fdm.replay)
+	return fdm
 }
 
 // AddVariable adds a simulation variable to be tracked
@@ -46,91 +133,590 @@ func (fdm *FlightDataManager) AddVariable(name, simVar, units string) error {
 	return fdm.AddVariableWithWritable(name, simVar, units, false) // Default to read-only
 }
 
-// AddVariableWithWritable adds a simulation variable with write capability specification
+// AddVariableWithWritable adds a simulation variable with write capability specification.
+// Units "string" request a STRING256, every other variable is requested as FLOAT64.
 func (fdm *FlightDataManager) AddVariableWithWritable(name, simVar, units string, writable bool) error {
+	options := DefaultVariableOptions()
+	options.Writable = writable
+	return fdm.AddVariableWithOptions(name, simVar, units, options)
+}
+
+// AddVariableWithType adds a simulation variable requested as the given data type.
+// Its typed value is available from Data and the typed getters; numeric variables with
+// units "bool" are reported as bool.
+func (fdm *FlightDataManager) AddVariableWithType(name, simVar, units string, dataType SIMCONNECT_DATATYPE, writable bool) error {
+	options := DefaultVariableOptions()
+	options.DataType = dataType
+	options.Writable = writable
+	return fdm.AddVariableWithOptions(name, simVar, units, options)
+}
+
+// AddVariableWithPeriod adds a read-only simulation variable updated with the given period.
+// Variables with the same period are delivered together; the default is SIMCONNECT_PERIOD_SECOND.
+func (fdm *FlightDataManager) AddVariableWithPeriod(name, simVar, units string, period SIMCONNECT_PERIOD) error {
+	if period == SIMCONNECT_PERIOD_NEVER {
+		return fmt.Errorf("variable %s needs an update period", name)
+	}
+	options := DefaultVariableOptions()
+	options.Period = period
+	return fdm.AddVariableWithOptions(name, simVar, units, options)
+}
+
+// AddVariableWithOptions adds a simulation variable with its own data type, request parameters and epsilon.
+// It joins the group of variables with the same request parameters, or starts a new one. While the
+// manager is running the group's request is cancelled and issued again with the new layout; other
+// groups keep their updates.
+func (fdm *FlightDataManager) AddVariableWithOptions(name, simVar, units string, options VariableOptions) error {
+	options, err := resolveOptions(units, options)
+	if err != nil {
+		return fmt.Errorf("failed to add variable %s: %v", name, err)
+	}
+
 	fdm.mutex.Lock()
 	defer fdm.mutex.Unlock()
 
-	if fdm.running {
-		return fmt.Errorf("cannot add variables while data manager is running")
-	} // Create unique IDs for this variable with proper spacing to avoid conflicts
-	// According to Microsoft docs, RequestID should be unique for each request
-	// Using larger, more predictable gaps to ensure no collisions
-	index := len(fdm.variables)
-	defineID := DataDefinitionID(index + 1)
-	// Use even larger gaps for RequestIDs - start at 1000 and increment by 1000
-	// This ensures no conflicts and makes debugging easier
-	requestID := SimObjectDataRequestID(1000 + (index * 1000))
-
-	// Add to SimConnect data definition
-	if err := fdm.client.AddToDataDefinition(defineID, simVar, units, SIMCONNECT_DATATYPE_FLOAT64); err != nil {
+	// Create variable record
+	fdm.variables = append(fdm.variables, FlightVariable{
+		Name:   name,
+		SimVar: simVar,
+		Units:  units,
+	})
+	fdm.details = append(fdm.details, variableDetail{datumID: fdm.nextDatumID})
+
+	index := len(fdm.variables) - 1
+	if err := fdm.attach(index, options); err != nil {
+		fdm.variables = fdm.variables[:index]
+		fdm.details = fdm.details[:index]
 		return fmt.Errorf("failed to add variable %s: %v", name, err)
 	}
+	fdm.nextDatumID++
+	return nil
+}
+
+// RemoveVariable stops tracking a variable. Its group's definition is cleared and rebuilt without it;
+// while running, the group's request is cancelled and issued again, other groups keep their updates.
+// Variables added after it move down one index.
+func (fdm *FlightDataManager) RemoveVariable(name string) error {
+	fdm.mutex.Lock()
+	defer fdm.mutex.Unlock()
+
+	index := fdm.index(name)
+	if index < 0 {
+		return fmt.Errorf("variable '%s' not found", name)
+	}
 
-	// Create variable record
-	variable := FlightVariable{
-		Name:     name,
-		SimVar:   simVar,
-		Units:    units,
-		Value:    0.0,
-		Writable: writable,
-	} // Store in our collections
-	fdm.variables = append(fdm.variables, variable)
-	fdm.definitions = append(fdm.definitions, defineID)
-	fdm.requests = append(fdm.requests, requestID)
+	err := fdm.detach(index)
+
+	fdm.variables = append(fdm.variables[:index], fdm.variables[index+1:]...)
+	fdm.details = append(fdm.details[:index], fdm.details[index+1:]...)
+	for _, group := range fdm.groups {
+		for position, i := range group.variables {
+			if i > index {
+				group.variables[position] = i - 1
+			}
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("variable %s removed, but SimConnect was not updated: %v", name, err)
+	}
+	return nil
+}
+
+// RetuneVariable changes the data type, write access, request parameters and epsilon of a variable.
+// It moves to the group matching the new options, and while running only its old and new group are
+// requested again. The variable keeps its index; its value is reset until the next update.
+func (fdm *FlightDataManager) RetuneVariable(name string, options VariableOptions) error {
+	fdm.mutex.Lock()
+	defer fdm.mutex.Unlock()
+
+	index := fdm.index(name)
+	if index < 0 {
+		return fmt.Errorf("variable '%s' not found", name)
+	}
+
+	options, err := resolveOptions(fdm.variables[index].Units, options)
+	if err != nil {
+		return fmt.Errorf("failed to retune variable %s: %v", name, err)
+	}
+
+	previous := fdm.options(index)
+	detachErr := fdm.detach(index)
+	if err := fdm.attach(index, options); err != nil {
+		if restoreErr := fdm.attach(index, previous); restoreErr != nil {
+			return fmt.Errorf("failed to retune variable %s: %v (restoring it failed too: %v)", name, err, restoreErr)
+		}
+		return fmt.Errorf("failed to retune variable %s: %v", name, err)
+	}
+	if detachErr != nil {
+		return fmt.Errorf("variable %s retuned, but its previous group was not updated: %v", name, detachErr)
+	}
 	return nil
 }
 
-// Start begins real-time data collection
+// resolveOptions fills in the default data type and period and checks the data type is supported
+func resolveOptions(units string, options VariableOptions) (VariableOptions, error) {
+	if options.DataType == SIMCONNECT_DATATYPE_INVALID {
+		options.DataType = SIMCONNECT_DATATYPE_FLOAT64
+		if strings.EqualFold(units, "string") {
+			options.DataType = SIMCONNECT_DATATYPE_STRING256
+		}
+	}
+	if _, err := datumGoType(options.DataType); err != nil {
+		return options, err
+	}
+	if options.Period == SIMCONNECT_PERIOD_NEVER {
+		options.Period = SIMCONNECT_PERIOD_SECOND
+	}
+	return options, nil
+}
+
+// index returns the index of the named variable, -1 if there is none
+func (fdm *FlightDataManager) index(name string) int {
+	for i, variable := range fdm.variables {
+		if variable.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// options returns the options the variable at index is requested with
+func (fdm *FlightDataManager) options(index int) VariableOptions {
+	options := DefaultVariableOptions()
+	if group, _ := fdm.groupOf(index); group != nil {
+		options.Period = group.options.period
+		options.Flags = group.options.flags
+		options.Origin = group.options.origin
+		options.Interval = group.options.interval
+		options.Limit = group.options.limit
+	}
+	options.DataType = fdm.variables[index].DataType
+	options.Writable = fdm.variables[index].Writable
+	options.Epsilon = fdm.details[index].epsilon
+	return options
+}
+
+// attach applies options to the variable at index and adds it to the group with the same request
+// parameters, creating the group if needed. Writable variables also get a definition of their own.
+// While running, the group's definition is extended and its data requested again under a new request ID.
+// On failure nothing is left in SimConnect and the variable belongs to no group.
+func (fdm *FlightDataManager) attach(index int, options VariableOptions) error {
+	if fdm.closed {
+		return fmt.Errorf("data manager is closed")
+	}
+
+	variable := &fdm.variables[index]
+	detail := &fdm.details[index]
+
+	variable.DataType = options.DataType
+	variable.Writable = options.Writable
+	zero, _ := datumGoType(options.DataType)
+	variable.setData(reflect.Zero(zero).Interface())
+	variable.Updated = time.Time{}
+	detail.epsilon = options.Epsilon
+
+	key := requestOptions{
+		period:   options.Period,
+		flags:    options.Flags,
+		origin:   options.Origin,
+		interval: options.Interval,
+		limit:    options.Limit,
+	}
+	group := fdm.group(key)
+	created := group == nil
+	if created {
+		// Unique IDs from the client's registry, shared with other managers on this connection
+		group = &dataGroup{
+			options:   key,
+			defineID:  fdm.client.IDs().NewDefinitionID(),
+			requestID: fdm.client.IDs().NewRequestID(),
+		}
+	}
+
+	// Writable variables get a definition of their own so a write touches nothing else
+	if options.Writable {
+		detail.setDefineID = fdm.client.IDs().NewDefinitionID()
+		if err := fdm.define(detail.setDefineID, *variable, variableDetail{}); err != nil {
+			fdm.client.IDs().Release(IDDefinition, uint32(detail.setDefineID))
+			detail.setDefineID = 0
+			fdm.abandon(group, created)
+			return fmt.Errorf("failed to define writable variable: %v", err)
+		}
+	}
+
+	if fdm.running {
+		if !created {
+			// The payload layout changes, so the group's data is requested again once the variable is in
+			if err := fdm.cancel(group); err != nil {
+				fdm.dropSetDefinition(index)
+				return fmt.Errorf("failed to cancel data request: %v", err)
+			}
+			fdm.renew(group)
+		}
+
+		// Add to the group's SimConnect data definition
+		if err := fdm.define(group.defineID, *variable, *detail); err != nil {
+			fdm.dropSetDefinition(index)
+			fdm.abandon(group, created)
+			return err
+		}
+	}
+
+	group.variables = append(group.variables, index)
+	if created {
+		fdm.groups = append(fdm.groups, group)
+	}
+	if fdm.running {
+		if created {
+			group.handler = fdm.client.Dispatcher().HandleRequest(uint32(group.requestID), fdm.dataHandler(group))
+		}
+		fdm.resume(group)
+	}
+	return nil
+}
+
+// abandon undoes the group changes of a failed attach: a new group's IDs are released,
+// an existing running group is requested again with its unchanged layout
+func (fdm *FlightDataManager) abandon(group *dataGroup, created bool) {
+	if created {
+		fdm.client.IDs().Release(IDDefinition, uint32(group.defineID))
+		fdm.client.IDs().Release(IDRequest, uint32(group.requestID))
+	} else if fdm.running {
+		fdm.resume(group)
+	}
+}
+
+// detach removes the variable at index from its group and drops its write definition. While running,
+// the group's definition is cleared and rebuilt without the variable; an emptied group is released.
+// The bookkeeping is updated even when SimConnect calls fail, so a reconnect replays the new layout;
+// the first error is returned.
+func (fdm *FlightDataManager) detach(index int) error {
+	var result error
+	record := func(err error) {
+		if err != nil && result == nil {
+			result = err
+		}
+	}
+
+	record(fdm.dropSetDefinition(index))
+
+	group, position := fdm.groupOf(index)
+	if group == nil {
+		return result // Refused by SimConnect when it was added
+	}
+	group.variables = append(group.variables[:position:position], group.variables[position+1:]...)
+
+	if fdm.running {
+		record(fdm.cancel(group))
+		record(fdm.client.clearDataDefinition(fdm, group.defineID))
+	}
+
+	if len(group.variables) == 0 {
+		fdm.release(group)
+		return result
+	}
+
+	if fdm.running {
+		for _, i := range group.variables {
+			record(fdm.define(group.defineID, fdm.variables[i], fdm.details[i]))
+		}
+		fdm.renew(group)
+		record(fdm.request(group))
+	}
+	return result
+}
+
+// dropSetDefinition clears and releases the write definition of the variable at index, if it has one
+func (fdm *FlightDataManager) dropSetDefinition(index int) error {
+	setDefineID := fdm.details[index].setDefineID
+	if setDefineID == 0 {
+		return nil
+	}
+	fdm.details[index].setDefineID = 0
+	defer fdm.client.IDs().Release(IDDefinition, uint32(setDefineID))
+	return fdm.client.clearDataDefinition(fdm, setDefineID)
+}
+
+// groupOf returns the group of the variable at index and its position in the group, nil if it has none
+func (fdm *FlightDataManager) groupOf(index int) (*dataGroup, int) {
+	for _, group := range fdm.groups {
+		for position, i := range group.variables {
+			if i == index {
+				return group, position
+			}
+		}
+	}
+	return nil, -1
+}
+
+// group returns the group with the given request options, nil if there is none yet
+func (fdm *FlightDataManager) group(options requestOptions) *dataGroup {
+	for _, group := range fdm.groups {
+		if group.options == options {
+			return group
+		}
+	}
+	return nil
+}
+
+// release removes an empty group, unregisters its handler and returns its IDs to the registry
+func (fdm *FlightDataManager) release(group *dataGroup) {
+	for i, g := range fdm.groups {
+		if g == group {
+			fdm.groups = append(fdm.groups[:i:i], fdm.groups[i+1:]...)
+			break
+		}
+	}
+	if group.handler != 0 {
+		fdm.client.Dispatcher().RemoveHandler(group.handler)
+		group.handler = 0
+	}
+	fdm.client.IDs().Release(IDDefinition, uint32(group.defineID))
+	fdm.client.IDs().Release(IDRequest, uint32(group.requestID))
+	group.requestID = 0 // Messages still being dispatched no longer match
+}
+
+// cancel stops a group's request with SIMCONNECT_PERIOD_NEVER
+func (fdm *FlightDataManager) cancel(group *dataGroup) error {
+	return fdm.client.requestDataOnSimObject(fdm, group.requestID, group.defineID, SIMCONNECT_OBJECT_ID_USER,
+		SIMCONNECT_PERIOD_NEVER, SIMCONNECT_DATA_REQUEST_FLAG_DEFAULT, 0, 0, 0)
+}
+
+// renew moves a group to a new request ID, so messages already sent for its cancelled request
+// are neither routed to it nor decoded with its new layout. While running the handler moves along.
+func (fdm *FlightDataManager) renew(group *dataGroup) {
+	dispatcher := fdm.client.Dispatcher()
+	if group.handler != 0 {
+		dispatcher.RemoveHandler(group.handler)
+		group.handler = 0
+	}
+	fdm.client.IDs().Release(IDRequest, uint32(group.requestID))
+
+	group.requestID = fdm.client.IDs().NewRequestID()
+	if fdm.running {
+		group.handler = dispatcher.HandleRequest(uint32(group.requestID), fdm.dataHandler(group))
+	}
+}
+
+// resume requests a running group's data after its definition changed. A failure is reported
+// on the error channel; a Supervisor issues the request again after reconnecting.
+func (fdm *FlightDataManager) resume(group *dataGroup) {
+	if err := fdm.request(group); err != nil {
+		fdm.reportErrorLocked(fmt.Errorf("failed to request data for %d variables: %v", len(group.variables), err))
+	}
+}
+
+// Start begins real-time data collection. It creates the data definition of every group,
+// routes the groups' data to their variables and requests it. A stopped manager can be started again.
 func (fdm *FlightDataManager) Start() error {
 	fdm.mutex.Lock()
 	defer fdm.mutex.Unlock()
 
+	if fdm.closed {
+		return fmt.Errorf("data manager is closed")
+	}
 	if fdm.running {
 		return fmt.Errorf("data manager is already running")
 	}
 
 	if len(fdm.variables) == 0 {
 		return fmt.Errorf("no variables added")
-	} // Request data for all variables using optimized settings based on Microsoft SimConnect documentation
-	// Using SIMCONNECT_PERIOD_SECOND for consistent 1Hz updates and CHANGED flag to reduce unnecessary data transmission
-	// This combination provides the best performance for flight data monitoring applications
-	for i, requestID := range fdm.requests {
-		if err := fdm.client.RequestDataOnSimObjectWithFlags(
-			requestID,
-			fdm.definitions[i],
-			SIMCONNECT_OBJECT_ID_USER,
-			SIMCONNECT_PERIOD_SECOND,
-			SIMCONNECT_DATA_REQUEST_FLAG_CHANGED,
-			0, // origin (unused for SECOND period)
-			0, // interval (unused for SECOND period)
-			0, // limit (unused for SECOND period)
-		); err != nil {
-			return fmt.Errorf("failed to request data for variable %s: %v", fdm.variables[i].Name, err)
-		}
-		fmt.Printf("DEBUG: Requested data for %s with RequestID %d, DefineID %d using PERIOD_SECOND + CHANGED flag\n",
-			fdm.variables[i].Name, requestID, fdm.definitions[i])
 	}
 
 	fdm.running = true
+	for i, group := range fdm.groups {
+		if err := fdm.open(group); err != nil {
+			// Undo the groups opened so far, including the failed one
+			fdm.running = false
+			for _, group := range fdm.groups[:i+1] {
+				fdm.close(group)
+			}
+			return fmt.Errorf("failed to request data for %d variables: %v", len(group.variables), err)
+		}
+	}
 
-	// Start background data collection
-	go fdm.dataCollectionLoop()
+	fdm.done = make(chan struct{})
+	fdm.client.Dispatcher().Start()
 
 	return nil
 }
 
-// Stop stops real-time data collection
+// Run starts data collection and blocks until ctx is done or Stop is called elsewhere.
+// It returns the Start error, ctx.Err() once stopped because of ctx, or nil after Stop.
+func (fdm *FlightDataManager) Run(ctx context.Context) error {
+	if err := fdm.Start(); err != nil {
+		return err
+	}
+
+	fdm.mutex.RLock()
+	done := fdm.done
+	fdm.mutex.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		fdm.Stop()
+		return ctx.Err()
+	case <-done:
+		return nil
+	}
+}
+
+// Stop stops real-time data collection. Every request is cancelled with SIMCONNECT_PERIOD_NEVER
+// and the groups' data definitions are cleared; write definitions stay so SetVariable keeps working.
+// When Stop returns no handler is updating variables any more, and messages SimConnect sent before
+// the cancellation are dropped. Stop must not be called from a dispatcher handler.
 func (fdm *FlightDataManager) Stop() {
 	fdm.mutex.Lock()
-	defer fdm.mutex.Unlock()
-
 	if !fdm.running {
+		fdm.mutex.Unlock()
 		return
 	}
 
 	fdm.running = false
-	fdm.stopChan <- true
+	for _, group := range fdm.groups {
+		if err := fdm.close(group); err != nil {
+			fdm.reportErrorLocked(fmt.Errorf("failed to cancel data request for %d variables: %v", len(group.variables), err))
+		}
+	}
+	done := fdm.done
+	fdm.mutex.Unlock()
+
+	// Released outside the lock, a handler may still be waiting for it
+	fdm.client.Dispatcher().Stop()
+	close(done)
+}
+
+// Close stops the manager, clears its write definitions in SimConnect, returns its IDs to the
+// registry and unregisters its exception handler and replay from the client. A closed manager
+// cannot be used anymore; closing it twice is a no-op. Close must not be called from a dispatcher handler.
+func (fdm *FlightDataManager) Close() error {
+	fdm.Stop()
+
+	fdm.mutex.Lock()
+	defer fdm.mutex.Unlock()
+
+	if fdm.closed {
+		return nil
+	}
+	fdm.closed = true
+
+	fdm.client.Dispatcher().RemoveHandler(fdm.onError)
+	fdm.client.removeReplay(fdm.replayID)
+
+	// Stop cleared the groups' definitions, only the write definitions are left
+	var result error
+	for i, detail := range fdm.details {
+		if detail.setDefineID == 0 {
+			continue
+		}
+		if !fdm.client.IsOpen() {
+			fdm.client.IDs().Release(IDDefinition, uint32(detail.setDefineID))
+			fdm.details[i].setDefineID = 0
+			continue
+		}
+		if err := fdm.dropSetDefinition(i); err != nil && result == nil {
+			result = fmt.Errorf("failed to clear write definition of %s: %v", fdm.variables[i].Name, err)
+		}
+	}
+	for len(fdm.groups) > 0 {
+		fdm.release(fdm.groups[0])
+	}
+	return result
+}
+
+// Wait blocks until the manager is stopped; it returns right away when it is not running
+func (fdm *FlightDataManager) Wait() {
+	fdm.mutex.RLock()
+	done := fdm.done
+	running := fdm.running
+	fdm.mutex.RUnlock()
+
+	if running {
+		<-done
+	}
+}
+
+// open creates a group's data definition, routes its data to its variables and requests it
+func (fdm *FlightDataManager) open(group *dataGroup) error {
+	for _, index := range group.variables {
+		if err := fdm.define(group.defineID, fdm.variables[index], fdm.details[index]); err != nil {
+			return fmt.Errorf("failed to define variable %s: %v", fdm.variables[index].Name, err)
+		}
+	}
+	group.handler = fdm.client.Dispatcher().HandleRequest(uint32(group.requestID), fdm.dataHandler(group))
+	return fdm.request(group)
+}
+
+// close cancels a group's request, clears its data definition and moves it to a new request ID,
+// so messages still queued for the cancelled request are dropped. Without a connection there is
+// nothing to cancel; SimConnect discarded the definitions and requests with it.
+func (fdm *FlightDataManager) close(group *dataGroup) error {
+	var err error
+	if fdm.client.IsOpen() {
+		err = fdm.cancel(group)
+		if clearErr := fdm.client.clearDataDefinition(fdm, group.defineID); err == nil {
+			err = clearErr
+		}
+	}
+	fdm.renew(group)
+	return err
+}
+
+// define adds a variable to a SimConnect data definition with its epsilon and datum ID
+func (fdm *FlightDataManager) define(defineID DataDefinitionID, variable FlightVariable, detail variableDetail) error {
+	units := variable.Units
+	if variable.DataType > SIMCONNECT_DATATYPE_FLOAT64 {
+		units = "" // Strings and structures have no units
+	}
+	return fdm.client.addToDataDefinition(fdm, defineID, variable.SimVar, units, variable.DataType, detail.epsilon, detail.datumID)
+}
+
+// request asks SimConnect to send a group's data with the group's request options
+func (fdm *FlightDataManager) request(group *dataGroup) error {
+	return fdm.client.requestDataOnSimObject(
+		fdm,
+		group.requestID,
+		group.defineID,
+		SIMCONNECT_OBJECT_ID_USER,
+		group.options.period,
+		group.options.flags,
+		group.options.origin,
+		group.options.interval,
+		group.options.limit,
+	)
+}
+
+// replay re-creates the write definitions, and the data definitions and requests while running, on a new connection
+func (fdm *FlightDataManager) replay() error {
+	fdm.mutex.RLock()
+	defer fdm.mutex.RUnlock()
+
+	for i, detail := range fdm.details {
+		if detail.setDefineID == 0 {
+			continue
+		}
+		if err := fdm.define(detail.setDefineID, fdm.variables[i], variableDetail{}); err != nil {
+			return fmt.Errorf("failed to re-add writable variable %s: %v", fdm.variables[i].Name, err)
+		}
+	}
+
+	if !fdm.running {
+		return nil
+	}
+
+	for _, group := range fdm.groups {
+		for _, index := range group.variables {
+			variable := fdm.variables[index]
+			if err := fdm.define(group.defineID, variable, fdm.details[index]); err != nil {
+				return fmt.Errorf("failed to re-add variable %s: %v", variable.Name, err)
+			}
+		}
+		if err := fdm.request(group); err != nil {
+			return fmt.Errorf("failed to re-request data for %d variables: %v", len(group.variables), err)
+		}
+	}
+	return nil
 }
 
 // GetVariable returns the current value of a variable by name
@@ -147,6 +733,106 @@ func (fdm *FlightDataManager) GetVariable(name string) (FlightVariable, bool) {
 	return FlightVariable{}, false
 }
 
+// GetFloat returns the value of a numeric or boolean variable as float64
+func (fdm *FlightDataManager) GetFloat(name string) (float64, error) {
+	variable, err := fdm.typed(name)
+	if err != nil {
+		return 0, err
+	}
+	switch variable.Data.(type) {
+	case float64, float32, int32, int64, bool:
+		return variable.Value, nil
+	}
+	return 0, fmt.Errorf("variable '%s' is %s, not numeric", name, variable.DataType)
+}
+
+// GetInt returns the value of a numeric or boolean variable as int64; INT64 values keep all 64 bits
+func (fdm *FlightDataManager) GetInt(name string) (int64, error) {
+	variable, err := fdm.typed(name)
+	if err != nil {
+		return 0, err
+	}
+	switch data := variable.Data.(type) {
+	case int64:
+		return data, nil
+	case float64, float32, int32, bool:
+		return int64(variable.Value), nil
+	}
+	return 0, fmt.Errorf("variable '%s' is %s, not numeric", name, variable.DataType)
+}
+
+// GetBool returns whether a numeric or boolean variable is non-zero
+func (fdm *FlightDataManager) GetBool(name string) (bool, error) {
+	value, err := fdm.GetFloat(name)
+	return value != 0, err
+}
+
+// GetString returns the value of a variable requested as one of the STRING data types
+func (fdm *FlightDataManager) GetString(name string) (string, error) {
+	variable, err := fdm.typed(name)
+	if err != nil {
+		return "", err
+	}
+	if data, ok := variable.Data.(string); ok {
+		return data, nil
+	}
+	return "", fmt.Errorf("variable '%s' is %s, not a string", name, variable.DataType)
+}
+
+// GetLatLonAlt returns the value of a variable requested as SIMCONNECT_DATATYPE_LATLONALT
+func (fdm *FlightDataManager) GetLatLonAlt(name string) (SIMCONNECT_DATA_LATLONALT, error) {
+	var value SIMCONNECT_DATA_LATLONALT
+	return value, fdm.getStruct(name, &value)
+}
+
+// GetXYZ returns the value of a variable requested as SIMCONNECT_DATATYPE_XYZ
+func (fdm *FlightDataManager) GetXYZ(name string) (SIMCONNECT_DATA_XYZ, error) {
+	var value SIMCONNECT_DATA_XYZ
+	return value, fdm.getStruct(name, &value)
+}
+
+// GetPBH returns the value of a variable requested as SIMCONNECT_DATATYPE_PBH
+func (fdm *FlightDataManager) GetPBH(name string) (SIMCONNECT_DATA_PBH, error) {
+	var value SIMCONNECT_DATA_PBH
+	return value, fdm.getStruct(name, &value)
+}
+
+// GetInitPosition returns the value of a variable requested as SIMCONNECT_DATATYPE_INITPOSITION
+func (fdm *FlightDataManager) GetInitPosition(name string) (SIMCONNECT_DATA_INITPOSITION, error) {
+	var value SIMCONNECT_DATA_INITPOSITION
+	return value, fdm.getStruct(name, &value)
+}
+
+// GetWaypoint returns the value of a variable requested as SIMCONNECT_DATATYPE_WAYPOINT
+func (fdm *FlightDataManager) GetWaypoint(name string) (SIMCONNECT_DATA_WAYPOINT, error) {
+	var value SIMCONNECT_DATA_WAYPOINT
+	return value, fdm.getStruct(name, &value)
+}
+
+// typed returns the named variable or a not found error
+func (fdm *FlightDataManager) typed(name string) (FlightVariable, error) {
+	variable, found := fdm.GetVariable(name)
+	if !found {
+		return FlightVariable{}, fmt.Errorf("variable '%s' not found", name)
+	}
+	return variable, nil
+}
+
+// getStruct stores the value of a structure variable in target, a pointer to the matching SIMCONNECT_DATA_* type
+func (fdm *FlightDataManager) getStruct(name string, target interface{}) error {
+	variable, err := fdm.typed(name)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(variable.Data)
+	if !value.IsValid() || value.Type() != reflect.TypeOf(target).Elem() {
+		return fmt.Errorf("variable '%s' is %s, not %s", name, variable.DataType, reflect.TypeOf(target).Elem().Name())
+	}
+	reflect.ValueOf(target).Elem().Set(value)
+	return nil
+}
+
 // GetAllVariables returns all current variable values
 func (fdm *FlightDataManager) GetAllVariables() []FlightVariable {
 	fdm.mutex.RLock()
@@ -177,118 +863,143 @@ func (fdm *FlightDataManager) IsRunning() bool {
 	return fdm.running
 }
 
-// dataCollectionLoop runs in a separate goroutine to collect data
-func (fdm *FlightDataManager) dataCollectionLoop() {
-	for {
-		select {
-		case <-fdm.stopChan:
+// dataHandler returns the dispatcher handler updating a group's variables from its combined payload
+func (fdm *FlightDataManager) dataHandler(group *dataGroup) MessageHandler {
+	return func(data []byte) {
+		message, simData, err := ParseSimObjectData(data)
+		if err != nil {
+			fdm.reportError(err)
 			return
-		default:
-			fdm.collectData()
-			time.Sleep(50 * time.Millisecond) // Limit frequency
 		}
-	}
-}
 
-// collectData collects a single round of data from SimConnect
-func (fdm *FlightDataManager) collectData() {
-	data, err := fdm.client.GetRawDispatch()
-	if err != nil {
-		return
-	}
-	if data == nil {
-		return
-	}
-
-	msgType, err := ParseMessageType(data)
-	if err != nil {
-		fdm.errorCount++
-		select {
-		case fdm.errorChan <- err:
-		default: // Channel full, drop error
+		fdm.mutex.Lock()
+		if message.DwRequestID != uint32(group.requestID) {
+			fdm.mutex.Unlock()
+			return // Sent for a request cancelled while this message was being dispatched
 		}
-		return
-	}
 
-	if msgType == SIMCONNECT_RECV_ID_SIMOBJECT_DATA {
-		header, simData, err := ParseSimObjectData(data)
+		indexes, values, err := fdm.decode(group, simData)
 		if err != nil {
-			fdm.errorCount++
-			select {
-			case fdm.errorChan <- err:
-			default: // Channel full, drop error
-			}
+			fdm.mutex.Unlock()
+			fdm.reportError(err)
 			return
 		}
+		defer fdm.mutex.Unlock()
 
-		if len(simData) < 8 {
-			return
+		now := time.Now()
+		for position, index := range indexes {
+			// Update the variable directly in the slice
+			fdm.variables[index].setData(values[position])
+			fdm.variables[index].Updated = now
+			fdm.dataCount++
 		}
-		// Find the variable this data corresponds to
-		requestID := SimObjectDataRequestID(header.DwRequestID)
-		fdm.mutex.Lock()
+		fdm.lastUpdate = now
+	}
+}
 
-		// Find the variable index by requestID
-		variableIndex := -1
-		for i, reqID := range fdm.requests {
-			if reqID == requestID {
-				variableIndex = i
+// decode splits a group's payload into values and the indexes of their variables.
+// Untagged data holds every variable in definition order; TAGGED data holds only the
+// changed ones, each preceded by its datum ID. STRINGV values have no fixed size.
+func (fdm *FlightDataManager) decode(group *dataGroup, simData []byte) ([]int, []interface{}, error) {
+	tagged := group.options.flags&SIMCONNECT_DATA_REQUEST_FLAG_TAGGED != 0
+
+	var indexes []int
+	var values []interface{}
+	offset := 0
+	for position := 0; ; position++ {
+		var index int
+		if tagged {
+			if offset == len(simData) {
 				break
 			}
+			if len(simData)-offset < 4 {
+				return nil, nil, newMessageError(SIMCONNECT_RECV_ID_SIMOBJECT_DATA, ErrMessageTruncated,
+					"datum ID at offset %d needs 4 bytes, have %d", offset, len(simData)-offset)
+			}
+			datumID := binary.LittleEndian.Uint32(simData[offset:])
+			index = fdm.datumIndex(group, datumID)
+			if index < 0 {
+				return nil, nil, newMessageError(SIMCONNECT_RECV_ID_SIMOBJECT_DATA, ErrMessageInvalid,
+					"unknown datum ID %d at offset %d", datumID, offset)
+			}
+			offset += 4
+		} else {
+			if position == len(group.variables) {
+				break
+			}
+			index = group.variables[position]
 		}
 
-		// Debug logging to track what's happening
-		if variableIndex >= 0 {
-			// Successfully found variable
-		} else {
-			// This indicates a RequestID mismatch - log it for debugging
-			fmt.Printf("DEBUG: Received unknown RequestID %d, known RequestIDs: %v\n", requestID, fdm.requests)
+		value, size, err := readDatum(fdm.variables[index].DataType, simData[offset:])
+		if err != nil {
+			return nil, nil, newMessageError(SIMCONNECT_RECV_ID_SIMOBJECT_DATA, ErrMessageTruncated,
+				"variable %s at offset %d: %v", fdm.variables[index].Name, offset, err)
 		}
-		if variableIndex >= 0 && variableIndex < len(fdm.variables) {
-			value := *(*float64)(unsafe.Pointer(&simData[0]))
-			// Update the variable directly in the slice
-			fdm.variables[variableIndex].Value = value
-			fdm.variables[variableIndex].Updated = time.Now()
-			fdm.dataCount++
-			fdm.lastUpdate = time.Now()
+		indexes = append(indexes, index)
+		values = append(values, value)
+		offset += size
+	}
+	return indexes, values, nil
+}
 
-			// Debug: Log which variable was updated
-			fmt.Printf("DEBUG: Updated %s = %.2f (RequestID: %d)\n",
-				fdm.variables[variableIndex].Name, value, requestID)
+// datumIndex returns the index of the group's variable with a datum ID, -1 if there is none
+func (fdm *FlightDataManager) datumIndex(group *dataGroup, datumID uint32) int {
+	for _, index := range group.variables {
+		if fdm.details[index].datumID == datumID {
+			return index
 		}
-		fdm.mutex.Unlock()
 	}
+	return -1
 }
 
-// SetVariable sets the value of a simulation variable by name
-func (fdm *FlightDataManager) SetVariable(name string, value float64) error {
-	fdm.mutex.RLock()
-	defer fdm.mutex.RUnlock()
+// handleException claims exceptions caused by this manager's calls, recognised by the owner
+// recorded with the sent packet. This covers requests the manager has since renewed or released.
+// A variable SimConnect refused to add is left out of its group, since it has no place in the payload.
+func (fdm *FlightDataManager) handleException(err *ExceptionError) bool {
+	if err.Packet == nil || err.Packet.owner != fdm {
+		return false
+	}
 
-	// Find the variable
-	variableIndex := -1
-	for i, variable := range fdm.variables {
-		if variable.Name == name {
-			variableIndex = i
+	if err.Packet.Operation == "AddToDataDefinition" {
+		fdm.mutex.Lock()
+		for _, group := range fdm.groups {
+			if err.Packet.DefineID != group.defineID {
+				continue
+			}
+			for position, index := range group.variables {
+				if err.Packet.Detail == fmt.Sprintf("'%s'", fdm.variables[index].SimVar) {
+					group.variables = append(group.variables[:position:position], group.variables[position+1:]...)
+					break
+				}
+			}
 			break
 		}
+		fdm.mutex.Unlock()
 	}
 
-	if variableIndex == -1 {
-		return fmt.Errorf("variable '%s' not found", name)
-	}
+	fdm.reportError(err)
+	return true
+}
 
-	// Check if variable is writable
-	if !fdm.variables[variableIndex].Writable {
-		return fmt.Errorf("variable '%s' is not writable", name)
+// reportError counts an error and sends it to the error channel without blocking
+func (fdm *FlightDataManager) reportError(err error) {
+	fdm.mutex.Lock()
+	defer fdm.mutex.Unlock()
+	fdm.reportErrorLocked(err)
+}
+
+// reportErrorLocked is reportError for callers already holding the mutex
+func (fdm *FlightDataManager) reportErrorLocked(err error) {
+	fdm.errorCount++
+	select {
+	case fdm.errorChan <- err:
+	default: // Channel full, drop error
 	}
+}
 
-	// Use the SetFloat64OnSimObject method with the variable's data definition
-	return fdm.client.SetFloat64OnSimObject(
-		fdm.definitions[variableIndex],
-		SIMCONNECT_OBJECT_ID_USER,
-		value,
-	)
+// SetVariable sets the value of a simulation variable by name
+func (fdm *FlightDataManager) SetVariable(name string, value float64) error {
+	return fdm.set(name, value)
 }
 
 // SetVariableByIndex sets the value using the variable index (more efficient for repeated operations)
@@ -299,16 +1010,73 @@ func (fdm *FlightDataManager) SetVariableByIndex(index int, value float64) error
 	if index < 0 || index >= len(fdm.variables) {
 		return fmt.Errorf("variable index %d out of range [0-%d]", index, len(fdm.variables)-1)
 	}
+	return fdm.setIndex(index, value)
+}
+
+// SetInt sets an integer variable, or a numeric one converted from value
+func (fdm *FlightDataManager) SetInt(name string, value int64) error {
+	return fdm.set(name, value)
+}
+
+// SetBool sets a boolean variable as 1 or 0
+func (fdm *FlightDataManager) SetBool(name string, value bool) error {
+	return fdm.set(name, value)
+}
+
+// SetString sets a variable requested as one of the STRING data types
+func (fdm *FlightDataManager) SetString(name string, value string) error {
+	return fdm.set(name, value)
+}
+
+// SetLatLonAlt sets a variable requested as SIMCONNECT_DATATYPE_LATLONALT
+func (fdm *FlightDataManager) SetLatLonAlt(name string, value SIMCONNECT_DATA_LATLONALT) error {
+	return fdm.set(name, value)
+}
+
+// SetXYZ sets a variable requested as SIMCONNECT_DATATYPE_XYZ
+func (fdm *FlightDataManager) SetXYZ(name string, value SIMCONNECT_DATA_XYZ) error {
+	return fdm.set(name, value)
+}
+
+// SetPBH sets a variable requested as SIMCONNECT_DATATYPE_PBH
+func (fdm *FlightDataManager) SetPBH(name string, value SIMCONNECT_DATA_PBH) error {
+	return fdm.set(name, value)
+}
+
+// SetInitPosition sets a variable requested as SIMCONNECT_DATATYPE_INITPOSITION, e.g. "Initial Position"
+func (fdm *FlightDataManager) SetInitPosition(name string, value SIMCONNECT_DATA_INITPOSITION) error {
+	return fdm.set(name, value)
+}
+
+// SetWaypoint sets a variable requested as SIMCONNECT_DATATYPE_WAYPOINT
+func (fdm *FlightDataManager) SetWaypoint(name string, value SIMCONNECT_DATA_WAYPOINT) error {
+	return fdm.set(name, value)
+}
+
+// set writes a value to the named variable
+func (fdm *FlightDataManager) set(name string, value interface{}) error {
+	fdm.mutex.RLock()
+	defer fdm.mutex.RUnlock()
+
+	index := fdm.index(name)
+	if index < 0 {
+		return fmt.Errorf("variable '%s' not found", name)
+	}
+	return fdm.setIndex(index, value)
+}
+
+// setIndex encodes a value as the variable's data type and writes it through the variable's own definition
+func (fdm *FlightDataManager) setIndex(index int, value interface{}) error {
+	variable := fdm.variables[index]
 
 	// Check if variable is writable
-	if !fdm.variables[index].Writable {
-		return fmt.Errorf("variable '%s' is not writable", fdm.variables[index].Name)
+	if !variable.Writable {
+		return fmt.Errorf("variable '%s' is not writable", variable.Name)
 	}
 
-	// Use the SetFloat64OnSimObject method with the variable's data definition
-	return fdm.client.SetFloat64OnSimObject(
-		fdm.definitions[index],
-		SIMCONNECT_OBJECT_ID_USER,
-		value,
-	)
+	data, err := appendDatum(nil, variable.DataType, value)
+	if err != nil {
+		return fmt.Errorf("variable '%s': %v", variable.Name, err)
+	}
+	return fdm.client.setDataOnSimObject(fdm, fdm.details[index].setDefineID, SIMCONNECT_OBJECT_ID_USER, 0, data)
 }
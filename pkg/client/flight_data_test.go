@@ -0,0 +1,491 @@
+package client_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// newFlightServer returns a server with the simvars used by the FlightDataManager tests
+func newFlightServer() *simtest.Server {
+	server := simtest.NewServer()
+	server.SetSimVar("PLANE ALTITUDE", 1500.0)
+	server.SetSimVar("AIRSPEED INDICATED", 110.0)
+	return server
+}
+
+// exceptionFor waits for the exception of sendID on errs
+func exceptionFor(t *testing.T, errs <-chan error, sendID uint32) *client.ExceptionError {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-errs:
+			var exception *client.ExceptionError
+			if errors.As(err, &exception) && exception.SendID == sendID {
+				return exception
+			}
+		case <-timeout:
+			t.Fatalf("no exception for send ID %d", sendID)
+			return nil
+		}
+	}
+}
+
+// dataRequests returns the RequestDataOnSimObject calls made through transport, without cancellations
+func dataRequests(transport *client.MemoryTransport) []client.TransportCall {
+	var requests []client.TransportCall
+	for _, call := range transport.CallsTo("SimConnect_RequestDataOnSimObject") {
+		if call.Args[3] != client.SIMCONNECT_PERIOD_NEVER {
+			requests = append(requests, call)
+		}
+	}
+	return requests
+}
+
+// pushData answers request with a data block holding count values and waits until fdm decoded them
+func pushData(t *testing.T, fdm *client.FlightDataManager, transport *client.MemoryTransport, request client.TransportCall, count int, block []byte) {
+	t.Helper()
+	before, _, _ := fdm.GetStats()
+	transport.Push(simtest.EncodeSimObjectData(
+		request.Args[0].(client.SimObjectDataRequestID),
+		client.SIMCONNECT_OBJECT_ID_USER,
+		request.Args[1].(client.DataDefinitionID),
+		request.Args[4].(client.SIMCONNECT_DATA_REQUEST_FLAG),
+		uint32(count),
+		block,
+	))
+	waitFor(t, "decoded values", func() bool {
+		dataCount, _, _ := fdm.GetStats()
+		return dataCount == before+int64(count)
+	})
+}
+
+// pushInvalidData answers request with a data block fdm cannot decode and returns the reported error
+func pushInvalidData(t *testing.T, fdm *client.FlightDataManager, transport *client.MemoryTransport, request client.TransportCall, block []byte) error {
+	t.Helper()
+	transport.Push(simtest.EncodeSimObjectData(
+		request.Args[0].(client.SimObjectDataRequestID),
+		client.SIMCONNECT_OBJECT_ID_USER,
+		request.Args[1].(client.DataDefinitionID),
+		request.Args[4].(client.SIMCONNECT_DATA_REQUEST_FLAG),
+		1,
+		block,
+	))
+	select {
+	case err := <-fdm.GetErrors():
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("no decode error reported")
+		return nil
+	}
+}
+
+// appendFloat64 appends a FLOAT64 datum
+func appendFloat64(block []byte, value float64) []byte {
+	return binary.LittleEndian.AppendUint64(block, math.Float64bits(value))
+}
+
+// valuesOf returns the Value of each named variable
+func valuesOf(t *testing.T, fdm *client.FlightDataManager, names ...string) []float64 {
+	t.Helper()
+	values := make([]float64, len(names))
+	for i, name := range names {
+		variable, found := fdm.GetVariable(name)
+		if !found {
+			t.Fatalf("variable %s not found", name)
+		}
+		values[i] = variable.Value
+	}
+	return values
+}
+
+func TestFlightDataManagerDecodesBatchedPayload(t *testing.T) {
+	simClient, transport := newMemoryClient(t)
+	fdm := client.NewFlightDataManager(simClient)
+	defer fdm.Close()
+
+	for _, variable := range [][3]string{
+		{"Altitude", "PLANE ALTITUDE", "feet"},
+		{"Airspeed", "AIRSPEED INDICATED", "knots"},
+		{"Heading", "PLANE HEADING DEGREES MAGNETIC", "degrees"},
+	} {
+		if err := fdm.AddVariable(variable[0], variable[1], variable[2]); err != nil {
+			t.Fatalf("AddVariable: %v", err)
+		}
+	}
+	if err := fdm.AddVariableWithPeriod("Bank", "PLANE BANK DEGREES", "degrees", client.SIMCONNECT_PERIOD_SIM_FRAME); err != nil {
+		t.Fatalf("AddVariableWithPeriod: %v", err)
+	}
+	if err := fdm.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// One request per update period, each definition holding its variables in order
+	requests := dataRequests(transport)
+	if len(requests) != 2 {
+		t.Fatalf("%d data requests, want 2", len(requests))
+	}
+	perSecond, perFrame := requests[0], requests[1]
+	if perSecond.Args[3] != client.SIMCONNECT_PERIOD_SECOND || perFrame.Args[3] != client.SIMCONNECT_PERIOD_SIM_FRAME {
+		t.Fatalf("requested periods %v and %v", perSecond.Args[3], perFrame.Args[3])
+	}
+	var defined []string
+	for _, call := range transport.CallsTo("SimConnect_AddToDataDefinition") {
+		if call.Args[0] == perSecond.Args[1] {
+			defined = append(defined, call.Args[1].(string))
+		}
+	}
+	if want := []string{"PLANE ALTITUDE", "AIRSPEED INDICATED", "PLANE HEADING DEGREES MAGNETIC"}; !reflect.DeepEqual(defined, want) {
+		t.Errorf("shared definition holds %v, want %v", defined, want)
+	}
+
+	// Each value of a combined payload reaches its own variable
+	pushData(t, fdm, transport, perSecond, 3, appendFloat64(appendFloat64(appendFloat64(nil, 1500), 110), 270))
+	pushData(t, fdm, transport, perFrame, 1, appendFloat64(nil, -5))
+	if got, want := valuesOf(t, fdm, "Altitude", "Airspeed", "Heading", "Bank"), []float64{1500, 110, 270, -5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("values %v, want %v", got, want)
+	}
+
+	// A payload too short for the definition is reported and changes nothing
+	err := pushInvalidData(t, fdm, transport, perSecond, appendFloat64(appendFloat64(nil, 1600), 120))
+	if !errors.Is(err, client.ErrMessageTruncated) {
+		t.Errorf("error %v, want %v", err, client.ErrMessageTruncated)
+	}
+	if got, want := valuesOf(t, fdm, "Altitude", "Airspeed", "Heading"), []float64{1500, 110, 270}; !reflect.DeepEqual(got, want) {
+		t.Errorf("values %v after a truncated payload, want %v", got, want)
+	}
+}
+
+// writeDefinition returns the definition created for writing simVar, the first one it was added to
+func writeDefinition(t *testing.T, transport *client.MemoryTransport, simVar string) client.DataDefinitionID {
+	t.Helper()
+	for _, call := range transport.CallsTo("SimConnect_AddToDataDefinition") {
+		if call.Args[1] == simVar {
+			return call.Args[0].(client.DataDefinitionID)
+		}
+	}
+	t.Fatalf("%s was never defined", simVar)
+	return 0
+}
+
+func TestFlightDataManagerTypedValues(t *testing.T) {
+	simClient, transport := newMemoryClient(t)
+	fdm := client.NewFlightDataManager(simClient)
+	defer fdm.Close()
+
+	writable := func(dataType client.SIMCONNECT_DATATYPE) client.VariableOptions {
+		options := client.DefaultVariableOptions()
+		options.DataType = dataType
+		options.Writable = true
+		return options
+	}
+	variables := []struct {
+		name, simVar, units string
+		options             client.VariableOptions
+	}{
+		{"Title", "TITLE", "string", writable(0)},
+		{"Gear", "GEAR HANDLE POSITION", "bool", writable(client.SIMCONNECT_DATATYPE_INT32)},
+		{"Counter", "EVENT COUNTER", "number", writable(client.SIMCONNECT_DATATYPE_INT64)},
+		{"Position", "STRUCT LATLONALT", "", writable(client.SIMCONNECT_DATATYPE_LATLONALT)},
+		{"Altitude", "PLANE ALTITUDE", "feet", writable(0)},
+		{"Airspeed", "AIRSPEED INDICATED", "knots", client.DefaultVariableOptions()},
+	}
+	for _, v := range variables {
+		if err := fdm.AddVariableWithOptions(v.name, v.simVar, v.units, v.options); err != nil {
+			t.Fatalf("AddVariableWithOptions %s: %v", v.name, err)
+		}
+	}
+	if err := fdm.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	position := client.SIMCONNECT_DATA_LATLONALT{Latitude: 50.1, Longitude: 14.26, Altitude: 1247}
+	counter := int64(1<<53 + 1) // Not representable as float64
+	title := make([]byte, 256)
+	copy(title, "Cessna 172")
+	block := append([]byte(nil), title...)
+	block = binary.LittleEndian.AppendUint32(block, 1)
+	block = binary.LittleEndian.AppendUint64(block, uint64(counter))
+	block, _ = binary.Append(block, binary.LittleEndian, position)
+	block = appendFloat64(appendFloat64(block, 1500), 110)
+	pushData(t, fdm, transport, dataRequests(transport)[0], len(variables), block)
+
+	// Typed getters
+	if value, err := fdm.GetString("Title"); err != nil || value != "Cessna 172" {
+		t.Errorf("GetString = %q, %v", value, err)
+	}
+	if value, err := fdm.GetBool("Gear"); err != nil || !value {
+		t.Errorf("GetBool = %v, %v", value, err)
+	}
+	if variable, _ := fdm.GetVariable("Gear"); variable.Data != true {
+		t.Errorf("Data of a bool variable is %#v, want true", variable.Data)
+	}
+	if value, err := fdm.GetInt("Counter"); err != nil || value != counter {
+		t.Errorf("GetInt = %d, %v, want %d", value, err, counter)
+	}
+	if value, err := fdm.GetLatLonAlt("Position"); err != nil || value != position {
+		t.Errorf("GetLatLonAlt = %+v, %v", value, err)
+	}
+	if value, err := fdm.GetFloat("Altitude"); err != nil || value != 1500 {
+		t.Errorf("GetFloat = %v, %v", value, err)
+	}
+	if value, err := fdm.GetInt("Airspeed"); err != nil || value != 110 {
+		t.Errorf("GetInt of a float = %d, %v", value, err)
+	}
+
+	// Getters of another type fail
+	conversions := map[string]func() error{
+		"GetString of a number":   func() error { _, err := fdm.GetString("Altitude"); return err },
+		"GetFloat of a string":    func() error { _, err := fdm.GetFloat("Title"); return err },
+		"GetInt of a structure":   func() error { _, err := fdm.GetInt("Position"); return err },
+		"GetXYZ of a LATLONALT":   func() error { _, err := fdm.GetXYZ("Position"); return err },
+		"GetLatLonAlt of a float": func() error { _, err := fdm.GetLatLonAlt("Altitude"); return err },
+		"GetFloat of no variable": func() error { _, err := fdm.GetFloat("Missing"); return err },
+	}
+	for name, get := range conversions {
+		if err := get(); err == nil {
+			t.Errorf("%s succeeded", name)
+		}
+	}
+
+	// Setters encode the variable's type and write through its own definition
+	positionBlock, _ := binary.Append(nil, binary.LittleEndian, position)
+	writes := []struct {
+		name   string
+		set    func() error
+		simVar string
+		data   []byte
+	}{
+		{"SetString", func() error { return fdm.SetString("Title", "Cessna 172") }, "TITLE", title},
+		{"SetBool", func() error { return fdm.SetBool("Gear", true) }, "GEAR HANDLE POSITION", binary.LittleEndian.AppendUint32(nil, 1)},
+		{"SetInt", func() error { return fdm.SetInt("Counter", counter) }, "EVENT COUNTER", binary.LittleEndian.AppendUint64(nil, uint64(counter))},
+		{"SetLatLonAlt", func() error { return fdm.SetLatLonAlt("Position", position) }, "STRUCT LATLONALT", positionBlock},
+		{"SetVariableByIndex", func() error { return fdm.SetVariableByIndex(4, 2000) }, "PLANE ALTITUDE", appendFloat64(nil, 2000)},
+	}
+	for _, w := range writes {
+		if err := w.set(); err != nil {
+			t.Errorf("%s: %v", w.name, err)
+			continue
+		}
+		calls := transport.CallsTo("SimConnect_SetDataOnSimObject")
+		call := calls[len(calls)-1]
+		if defineID := writeDefinition(t, transport, w.simVar); call.Args[0] != defineID {
+			t.Errorf("%s wrote definition %v, want %v", w.name, call.Args[0], defineID)
+		}
+		if data := call.Args[5].([]byte); !reflect.DeepEqual(data, w.data) {
+			t.Errorf("%s wrote %v, want %v", w.name, data, w.data)
+		}
+	}
+
+	// Values that do not fit the type or the variable are refused before anything is sent
+	writesBefore := len(transport.CallsTo("SimConnect_SetDataOnSimObject"))
+	refused := map[string]func() error{
+		"SetString of a number":      func() error { return fdm.SetString("Altitude", "high") },
+		"SetInt of a string":         func() error { return fdm.SetInt("Title", 1) },
+		"SetXYZ of a LATLONALT":      func() error { return fdm.SetXYZ("Position", client.SIMCONNECT_DATA_XYZ{}) },
+		"SetString too long":         func() error { return fdm.SetString("Title", string(make([]byte, 256))) },
+		"SetVariable of a read-only": func() error { return fdm.SetVariable("Airspeed", 120) },
+		"SetVariableByIndex range":   func() error { return fdm.SetVariableByIndex(len(variables), 1) },
+		"SetBool of no variable":     func() error { return fdm.SetBool("Missing", true) },
+	}
+	for name, set := range refused {
+		if err := set(); err == nil {
+			t.Errorf("%s succeeded", name)
+		}
+	}
+	if writes := len(transport.CallsTo("SimConnect_SetDataOnSimObject")); writes != writesBefore {
+		t.Errorf("%d refused writes were sent", writes-writesBefore)
+	}
+}
+
+func TestFlightDataManagerPerVariableOptions(t *testing.T) {
+	simClient, transport := newMemoryClient(t)
+	fdm := client.NewFlightDataManager(simClient)
+	defer fdm.Close()
+
+	attitude := client.VariableOptions{
+		Period:  client.SIMCONNECT_PERIOD_SIM_FRAME,
+		Flags:   client.SIMCONNECT_DATA_REQUEST_FLAG_CHANGED | client.SIMCONNECT_DATA_REQUEST_FLAG_TAGGED,
+		Epsilon: 0.5,
+	}
+	limited := client.VariableOptions{Period: client.SIMCONNECT_PERIOD_SECOND, Origin: 2, Interval: 3, Limit: 4}
+	variables := []struct {
+		name, simVar string
+		options      client.VariableOptions
+	}{
+		{"Pitch", "PLANE PITCH DEGREES", attitude},
+		{"Bank", "PLANE BANK DEGREES", attitude},
+		{"Fuel", "FUEL TOTAL QUANTITY", client.DefaultVariableOptions()},
+		{"Oil", "GENERAL ENG OIL TEMPERATURE:1", limited},
+	}
+	for _, v := range variables {
+		if err := fdm.AddVariableWithOptions(v.name, v.simVar, "degrees", v.options); err != nil {
+			t.Fatalf("AddVariableWithOptions %s: %v", v.name, err)
+		}
+	}
+	if err := fdm.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// Each set of options gets its own request with the options passed through
+	requests := dataRequests(transport)
+	if len(requests) != 3 {
+		t.Fatalf("%d data requests, want 3", len(requests))
+	}
+	wantArgs := [][]interface{}{
+		{client.SIMCONNECT_PERIOD_SIM_FRAME, attitude.Flags, uint32(0), uint32(0), uint32(0)},
+		{client.SIMCONNECT_PERIOD_SECOND, client.SIMCONNECT_DATA_REQUEST_FLAG_CHANGED, uint32(0), uint32(0), uint32(0)},
+		{client.SIMCONNECT_PERIOD_SECOND, client.SIMCONNECT_DATA_REQUEST_FLAG_DEFAULT, uint32(2), uint32(3), uint32(4)},
+	}
+	for i, request := range requests {
+		if got := request.Args[3:]; !reflect.DeepEqual(got, wantArgs[i]) {
+			t.Errorf("request %d with period, flags, origin, interval and limit %v, want %v", i, got, wantArgs[i])
+		}
+	}
+
+	// Epsilons reach the definition, datum IDs tell the tagged variables apart
+	datumIDs := make(map[string]uint32)
+	for _, call := range transport.CallsTo("SimConnect_AddToDataDefinition") {
+		if call.Args[0] != requests[0].Args[1] {
+			continue
+		}
+		if epsilon := call.Args[4].(float32); epsilon != 0.5 {
+			t.Errorf("%s defined with epsilon %v, want 0.5", call.Args[1], epsilon)
+		}
+		datumIDs[call.Args[1].(string)] = call.Args[5].(uint32)
+	}
+	pitchID, bankID := datumIDs["PLANE PITCH DEGREES"], datumIDs["PLANE BANK DEGREES"]
+	if pitchID == bankID {
+		t.Fatalf("pitch and bank share datum ID %d", pitchID)
+	}
+
+	// TAGGED data holds only the changed variables, each after its datum ID
+	tagged := func(datumID uint32, value float64) []byte {
+		return appendFloat64(binary.LittleEndian.AppendUint32(nil, datumID), value)
+	}
+	pushData(t, fdm, transport, requests[0], 1, tagged(bankID, 10))
+	if pitch, _ := fdm.GetVariable("Pitch"); !pitch.Updated.IsZero() {
+		t.Error("Pitch updated by data tagged for Bank")
+	}
+	pushData(t, fdm, transport, requests[0], 2, append(tagged(pitchID, 3), tagged(bankID, 4)...))
+	if got, want := valuesOf(t, fdm, "Pitch", "Bank"), []float64{3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("values %v, want %v", got, want)
+	}
+
+	// Unknown datum IDs and truncated tagged payloads are reported and change nothing
+	invalid := []struct {
+		name  string
+		block []byte
+		want  error
+	}{
+		{"unknown datum ID", append(tagged(pitchID, 5), tagged(pitchID+bankID+1, 6)...), client.ErrMessageInvalid},
+		{"truncated datum ID", append(tagged(pitchID, 5), 0, 0), client.ErrMessageTruncated},
+		{"truncated value", tagged(bankID, 6)[:8], client.ErrMessageTruncated},
+	}
+	for _, tt := range invalid {
+		if err := pushInvalidData(t, fdm, transport, requests[0], tt.block); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if got, want := valuesOf(t, fdm, "Pitch", "Bank"), []float64{3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("values %v after invalid payloads, want %v", got, want)
+	}
+}
+
+func TestFlightDataManagerClaimsExceptionsOfReleasedRequests(t *testing.T) {
+	server := newFlightServer()
+	simClient := openClient(t, server)
+
+	fdm := client.NewFlightDataManager(simClient)
+	defer fdm.Close()
+	if err := fdm.AddVariable("Altitude", "PLANE ALTITUDE", "feet"); err != nil {
+		t.Fatalf("AddVariable: %v", err)
+	}
+	if err := fdm.AddVariableWithPeriod("Airspeed", "AIRSPEED INDICATED", "knots", client.SIMCONNECT_PERIOD_SIM_FRAME); err != nil {
+		t.Fatalf("AddVariableWithPeriod: %v", err)
+	}
+	if err := fdm.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	requestSendID := server.LastSendID() // Request of the Airspeed group
+
+	// Releases the Airspeed group together with its request ID
+	if err := fdm.RemoveVariable("Airspeed"); err != nil {
+		t.Fatalf("RemoveVariable: %v", err)
+	}
+
+	server.InjectException(uint32(client.SIMCONNECT_EXCEPTION_ERROR), requestSendID, 1)
+	exception := exceptionFor(t, fdm.GetErrors(), requestSendID)
+	if exception.Packet == nil || exception.Packet.Operation != "RequestDataOnSimObject" {
+		t.Errorf("exception matched with %+v", exception.Packet)
+	}
+}
+
+func TestFlightDataManagerClose(t *testing.T) {
+	server := newFlightServer()
+	simClient := openClient(t, server)
+	simClient.Dispatcher().Start()
+	defer simClient.Dispatcher().Stop()
+
+	fdm := client.NewFlightDataManager(simClient)
+	options := client.DefaultVariableOptions()
+	options.Writable = true
+	if err := fdm.AddVariableWithOptions("Altitude", "PLANE ALTITUDE", "feet", options); err != nil {
+		t.Fatalf("AddVariableWithOptions: %v", err)
+	}
+	if err := fdm.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if len(server.Requests()) == 0 {
+		t.Fatal("no data requested")
+	}
+
+	if err := fdm.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if fdm.IsRunning() {
+		t.Error("manager still running after Close")
+	}
+	for defineID := client.DataDefinitionID(1); defineID < 16; defineID++ {
+		if len(server.Definition(defineID)) != 0 {
+			t.Errorf("definition %d still registered after Close", defineID)
+		}
+	}
+	if err := fdm.Start(); err == nil {
+		t.Error("Start after Close succeeded")
+	}
+	if err := fdm.AddVariable("Airspeed", "AIRSPEED INDICATED", "knots"); err == nil {
+		t.Error("AddVariable after Close succeeded")
+	}
+	if err := fdm.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// Exceptions for the closed manager's calls are no longer claimed by it
+	sendID := server.LastSendID()
+	server.InjectException(uint32(client.SIMCONNECT_EXCEPTION_ERROR), sendID, 1)
+	exceptionFor(t, simClient.Dispatcher().GetErrors(), sendID)
+
+	// A reconnect must not replay the closed manager
+	supervisor := client.NewSupervisor(simClient, client.ReconnectConfig{InitialDelay: time.Millisecond})
+	if err := supervisor.Start(); err != nil {
+		t.Fatalf("supervisor Start: %v", err)
+	}
+	defer supervisor.Stop()
+
+	server.Quit()
+	waitFor(t, "reconnect", func() bool {
+		reconnects, _ := supervisor.GetStats()
+		return reconnects == 1
+	})
+	if len(server.Requests()) != 0 {
+		t.Error("closed manager was replayed after reconnect")
+	}
+}
@@ -0,0 +1,113 @@
+package client_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// Native fuzz targets for DecodeMessage and the parsers. go test runs the seed corpus,
+// go test -fuzz explores further, e.g.:
+//
+//	go test -fuzz FuzzDecodeMessage -fuzztime 1m ./pkg/client
+
+// recvHeaderSize is the size of the SIMCONNECT_RECV header preceding every message body
+const recvHeaderSize = 12
+
+// validMessages returns one well-formed message of each type the simtest server sends
+func validMessages() [][]byte {
+	return [][]byte{
+		simtest.EncodeOpen("go-simconnect"),
+		simtest.EncodeQuit(),
+		simtest.EncodeException(uint32(client.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED), 7, 1),
+		simtest.EncodeEvent(1, 2, 3),
+		simtest.EncodeEventEx1(1, 2, [5]uint32{1, 2, 3, 4, 5}),
+		simtest.EncodeReservedKey("CTRL", "Y"),
+		simtest.EncodeEventFilename(4, 0, `SimObjects\Airplanes\Asobo_C172\aircraft.cfg`),
+		simtest.EncodeEventObjectAddRemove(5, 42, 1),
+		simtest.EncodeEventFrame(6, 60, 1),
+		simtest.EncodeSystemState(8, 1, 0.5, "flights/default.flt"),
+		simtest.EncodeSimObjectData(9, 0, 10, 0, 2, make([]byte, 16)),
+		simtest.EncodeEnumerateInputEvents(11, 0, 1, []client.SIMCONNECT_INPUT_EVENT_DESCRIPTOR{
+			{Name: "AUTOPILOT_AP_MASTER", Hash: 0x1122334455667788, Type: client.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE},
+			{Name: "COM1_STANDBY", Hash: 0x0102030405060708, Type: client.SIMCONNECT_INPUT_EVENT_TYPE_STRING},
+		}),
+		simtest.EncodeGetInputEvent(12, 1.0),
+		simtest.EncodeGetInputEvent(13, "122.800"),
+		simtest.EncodeSubscribeInputEvent(0x1122334455667788, 0.0),
+		simtest.EncodeEnumerateInputEventParams(0x1122334455667788, "FLOAT64;STRING"),
+	}
+}
+
+// FuzzDecodeMessage decodes arbitrary buffers including the header
+func FuzzDecodeMessage(f *testing.F) {
+	for _, data := range validMessages() {
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		message, err := client.DecodeMessage(data)
+		if err != nil {
+			if message != nil {
+				t.Fatalf("message returned together with error %v", err)
+			}
+			return
+		}
+		if int(message.Header().DwSize) != len(data) {
+			t.Fatalf("decoded size %d, buffer has %d bytes", message.Header().DwSize, len(data))
+		}
+	})
+}
+
+// FuzzParsers runs every exported parser on arbitrary buffers
+func FuzzParsers(f *testing.F) {
+	for _, data := range validMessages() {
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		client.ParseMessageType(data)
+		client.ParseSimObjectData(data)
+		client.ParseEvent(data)
+		client.ParseEventEx1(data)
+		client.ParseEventFilename(data)
+		client.ParseEventObjectAddRemove(data)
+		client.ParseEventFrame(data)
+		client.ParseSystemState(data)
+		client.ParseException(data)
+		client.ParseEnumerateInputEvents(data)
+		client.ParseGetInputEvent(data)
+		client.ParseSubscribeInputEvent(data)
+		client.ParseEnumerateInputEventParams(data)
+	})
+}
+
+// FuzzMessageBody wraps arbitrary bodies in a valid header, so the fuzzer concentrates on
+// the message body of each SIMCONNECT_RECV_ID
+func FuzzMessageBody(f *testing.F) {
+	for _, data := range validMessages() {
+		f.Add(binary.LittleEndian.Uint32(data[8:]), data[recvHeaderSize:])
+	}
+	// Types the simtest server does not send
+	for recvID := uint32(0); recvID <= client.SIMCONNECT_RECV_ID_FLOW_EVENT; recvID++ {
+		f.Add(recvID, make([]byte, 64))
+	}
+
+	f.Fuzz(func(t *testing.T, recvID uint32, body []byte) {
+		data := make([]byte, recvHeaderSize+len(body))
+		binary.LittleEndian.PutUint32(data[0:], uint32(len(data)))
+		binary.LittleEndian.PutUint32(data[4:], 4)
+		binary.LittleEndian.PutUint32(data[8:], recvID)
+		copy(data[recvHeaderSize:], body)
+
+		message, err := client.DecodeMessage(data)
+		if err != nil {
+			return
+		}
+		if message.Header().DwID != recvID {
+			t.Fatalf("decoded message type %d, want %d", message.Header().DwID, recvID)
+		}
+	})
+}
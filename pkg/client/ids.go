@@ -0,0 +1,156 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+)
+
+// IDKind identifies one of the ID namespaces of a SimConnect connection
+type IDKind int
+
+// ID namespaces handed out by the IDRegistry
+const (
+	IDDefinition        IDKind = iota // Data definition IDs (DataDefinitionID)
+	IDRequest                         // Request IDs shared by data, system state and facility requests
+	IDEvent                           // Client event IDs (SIMCONNECT_CLIENT_EVENT_ID)
+	IDNotificationGroup               // Notification group IDs (SIMCONNECT_NOTIFICATION_GROUP_ID)
+	IDInputGroup                      // Input group IDs (SIMCONNECT_INPUT_GROUP_ID)
+	IDClientData                      // Client data area IDs (SIMCONNECT_CLIENT_DATA_ID)
+	idKindCount
+)
+
+// String returns the name of the ID namespace
+func (k IDKind) String() string {
+	switch k {
+	case IDDefinition:
+		return "definition"
+	case IDRequest:
+		return "request"
+	case IDEvent:
+		return "event"
+	case IDNotificationGroup:
+		return "notification group"
+	case IDInputGroup:
+		return "input group"
+	case IDClientData:
+		return "client data"
+	default:
+		return fmt.Sprintf("IDKind(%d)", int(k))
+	}
+}
+
+// idPool tracks the IDs of one namespace
+type idPool struct {
+	last  uint32              // Last ID handed out, allocation continues after it
+	inUse map[uint32]struct{} // Allocated or reserved IDs
+}
+
+// IDRegistry hands out definition, request, event, group and client data IDs that are unique
+// per client, so managers and direct callers can share one connection.
+// Released IDs are only reused once the namespace wraps around, so late messages for
+// a released ID are not mistaken for messages of its next owner.
+type IDRegistry struct {
+	mutex sync.Mutex          // Thread safety
+	pools [idKindCount]idPool // ID pools by kind
+}
+
+// newIDRegistry creates an empty registry
+func newIDRegistry() *IDRegistry {
+	r := &IDRegistry{}
+	for i := range r.pools {
+		r.pools[i].inUse = make(map[uint32]struct{})
+	}
+	return r
+}
+
+// Allocate returns an unused ID of the given kind; IDs start at 1
+func (r *IDRegistry) Allocate(kind IDKind) uint32 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	pool := r.pool(kind)
+	for {
+		pool.last++
+		if pool.last == 0 {
+			continue // 0 is never handed out
+		}
+		if _, used := pool.inUse[pool.last]; !used {
+			pool.inUse[pool.last] = struct{}{}
+			return pool.last
+		}
+	}
+}
+
+// Reserve marks an ID chosen by the caller as used.
+// It fails if the ID was already allocated or reserved.
+func (r *IDRegistry) Reserve(kind IDKind, id uint32) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	pool := r.pool(kind)
+	if _, used := pool.inUse[id]; used {
+		return fmt.Errorf("%s ID %d is already in use", kind, id)
+	}
+	pool.inUse[id] = struct{}{}
+	return nil
+}
+
+// Release returns an ID to the registry; releasing an unused ID is a no-op
+func (r *IDRegistry) Release(kind IDKind, id uint32) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.pool(kind).inUse, id)
+}
+
+// InUse reports whether an ID is allocated or reserved
+func (r *IDRegistry) InUse(kind IDKind, id uint32) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	_, used := r.pool(kind).inUse[id]
+	return used
+}
+
+// markUsed records an ID used directly on the client so it is not handed out later
+func (r *IDRegistry) markUsed(kind IDKind, id uint32) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.pool(kind).inUse[id] = struct{}{}
+}
+
+// pool returns the pool of a kind; the caller holds the mutex
+func (r *IDRegistry) pool(kind IDKind) *idPool {
+	if kind < 0 || kind >= idKindCount {
+		panic(fmt.Sprintf("client: invalid ID kind %d", int(kind)))
+	}
+	return &r.pools[kind]
+}
+
+// NewDefinitionID allocates a data definition ID
+func (r *IDRegistry) NewDefinitionID() DataDefinitionID {
+	return DataDefinitionID(r.Allocate(IDDefinition))
+}
+
+// NewRequestID allocates a request ID for data, system state or facility requests
+func (r *IDRegistry) NewRequestID() SimObjectDataRequestID {
+	return SimObjectDataRequestID(r.Allocate(IDRequest))
+}
+
+// NewEventID allocates a client event ID
+func (r *IDRegistry) NewEventID() SIMCONNECT_CLIENT_EVENT_ID {
+	return SIMCONNECT_CLIENT_EVENT_ID(r.Allocate(IDEvent))
+}
+
+// NewNotificationGroupID allocates a notification group ID
+func (r *IDRegistry) NewNotificationGroupID() SIMCONNECT_NOTIFICATION_GROUP_ID {
+	return SIMCONNECT_NOTIFICATION_GROUP_ID(r.Allocate(IDNotificationGroup))
+}
+
+// NewInputGroupID allocates an input group ID
+func (r *IDRegistry) NewInputGroupID() SIMCONNECT_INPUT_GROUP_ID {
+	return SIMCONNECT_INPUT_GROUP_ID(r.Allocate(IDInputGroup))
+}
+
+// NewClientDataID allocates a client data area ID
+func (r *IDRegistry) NewClientDataID() SIMCONNECT_CLIENT_DATA_ID {
+	return SIMCONNECT_CLIENT_DATA_ID(r.Allocate(IDClientData))
+}
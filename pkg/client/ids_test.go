@@ -0,0 +1,110 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+)
+
+func TestIDRegistry(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ids *client.IDRegistry) []uint32 // Returns the IDs handed out
+		want []uint32
+	}{
+		{
+			name: "allocation starts at 1",
+			run: func(ids *client.IDRegistry) []uint32 {
+				return []uint32{ids.Allocate(client.IDRequest), ids.Allocate(client.IDRequest)}
+			},
+			want: []uint32{1, 2},
+		},
+		{
+			name: "kinds are independent",
+			run: func(ids *client.IDRegistry) []uint32 {
+				return []uint32{
+					uint32(ids.NewDefinitionID()),
+					uint32(ids.NewRequestID()),
+					uint32(ids.NewEventID()),
+					uint32(ids.NewNotificationGroupID()),
+					uint32(ids.NewInputGroupID()),
+					uint32(ids.NewClientDataID()),
+				}
+			},
+			want: []uint32{1, 1, 1, 1, 1, 1},
+		},
+		{
+			name: "reserved IDs are skipped",
+			run: func(ids *client.IDRegistry) []uint32 {
+				ids.Reserve(client.IDEvent, 2)
+				ids.Reserve(client.IDEvent, 3)
+				return []uint32{ids.Allocate(client.IDEvent), ids.Allocate(client.IDEvent)}
+			},
+			want: []uint32{1, 4},
+		},
+		{
+			name: "released IDs are not reused before wrapping",
+			run: func(ids *client.IDRegistry) []uint32 {
+				first := ids.Allocate(client.IDDefinition)
+				ids.Release(client.IDDefinition, first)
+				return []uint32{first, ids.Allocate(client.IDDefinition)}
+			},
+			want: []uint32{1, 2},
+		},
+		{
+			name: "reserving a released ID",
+			run: func(ids *client.IDRegistry) []uint32 {
+				first := ids.Allocate(client.IDInputGroup)
+				ids.Release(client.IDInputGroup, first)
+				if err := ids.Reserve(client.IDInputGroup, first); err != nil {
+					return nil
+				}
+				return []uint32{first}
+			},
+			want: []uint32{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := client.NewClientWithTransport("test", client.NewMemoryTransport()).IDs()
+			if got := tt.run(ids); !equalIDs(got, tt.want) {
+				t.Errorf("IDs %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIDRegistryInUse(t *testing.T) {
+	ids := client.NewClientWithTransport("test", client.NewMemoryTransport()).IDs()
+
+	id := ids.Allocate(client.IDRequest)
+	if !ids.InUse(client.IDRequest, id) {
+		t.Errorf("allocated ID %d not in use", id)
+	}
+	if ids.InUse(client.IDEvent, id) {
+		t.Errorf("request ID %d in use as event ID", id)
+	}
+	if err := ids.Reserve(client.IDRequest, id); err == nil {
+		t.Errorf("reserved allocated ID %d again", id)
+	}
+
+	ids.Release(client.IDRequest, id)
+	if ids.InUse(client.IDRequest, id) {
+		t.Errorf("released ID %d still in use", id)
+	}
+	ids.Release(client.IDRequest, id) // Releasing an unused ID is a no-op
+}
+
+// equalIDs reports whether two ID lists are equal
+func equalIDs(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,412 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// InputBinding describes what a key or joystick input triggers
+type InputBinding struct {
+	SimEvent  string              // Sim event triggered when the input is pressed, e.g. "GEAR_TOGGLE"; empty for none
+	OnDown    SystemEventCallback // Called when the input is pressed
+	OnUp      SystemEventCallback // Called when the input is released, nil to ignore releases
+	DownValue uint32              // Data of the press, e.g. the parameter of SimEvent
+	UpValue   uint32              // Data of the release
+	Maskable  bool                // Withhold the input from lower priority input groups and the simulator's own bindings
+}
+
+// inputBinding is a bound input with its client events
+type inputBinding struct {
+	input   string                     // Input definition as passed to Bind
+	binding InputBinding               // What the input triggers
+	downID  SIMCONNECT_CLIENT_EVENT_ID // Client event transmitted on press
+	upID    SIMCONNECT_CLIENT_EVENT_ID // Client event transmitted on release, SIMCONNECT_UNUSED without OnUp
+}
+
+// InputBindingManager binds key and joystick inputs such as "shift+ctrl+u" or "joystick:0:button:3"
+// to sim events and Go callbacks through an input group, without changing the simulator's control
+// profiles. Callbacks are delivered through the event dispatch of a SystemEventManager, which must
+// be running. Bindings are re-created when a Supervisor reconnects the client.
+type InputBindingManager struct {
+	client      *Client                          // SimConnect client
+	events      *SystemEventManager              // Delivers the bound client events to callbacks
+	mutex       sync.RWMutex                     // Thread safety
+	inputGroup  SIMCONNECT_INPUT_GROUP_ID        // Input group of all bindings
+	notifyGroup SIMCONNECT_NOTIFICATION_GROUP_ID // Notification group of client events with callbacks
+	priority    SIMCONNECT_GROUP_PRIORITY        // Input group priority
+	state       SIMCONNECT_STATE                 // Input group state
+	bindings    map[string]*inputBinding         // Bindings by upper-case input definition
+	closed      bool                             // Close was called, the manager cannot be used anymore
+	onError     HandlerID                        // Exception handler registered by NewInputBindingManager
+	replayID    HandlerID                        // Replay registered by NewInputBindingManager
+	errorChan   chan error                       // Error notifications
+}
+
+// NewInputBindingManager creates a new InputBindingManager instance delivering callbacks through events.
+// Its input group is turned on with SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE, so maskable bindings take effect.
+func NewInputBindingManager(client *Client, events *SystemEventManager) *InputBindingManager {
+	ibm := &InputBindingManager{
+		client:      client,
+		events:      events,
+		inputGroup:  client.IDs().NewInputGroupID(),
+		notifyGroup: client.IDs().NewNotificationGroupID(),
+		priority:    SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE,
+		state:       SIMCONNECT_STATE_ON,
+		bindings:    make(map[string]*inputBinding),
+		errorChan:   make(chan error, 10), // Buffered channel for non-blocking errors
+	}
+
+	// Exceptions caused by our groups and events are reported on our error channel
+	ibm.onError = client.Dispatcher().HandleException(ibm.handleException)
+	// Bindings are re-created when a Supervisor reconnects the client
+	ibm.replayID = client.addReplay(ibm.replay)
+	return ibm
+}
+
+// Bind binds an input definition, e.g. "shift+ctrl+u" or "joystick:0:button:3", to a sim event and/or callbacks
+func (ibm *InputBindingManager) Bind(input string, binding InputBinding) error {
+	if input == "" {
+		return fmt.Errorf("input definition is empty")
+	}
+	if binding.SimEvent == "" && binding.OnDown == nil && binding.OnUp == nil {
+		return fmt.Errorf("binding of input %s triggers neither a sim event nor a callback", input)
+	}
+
+	ibm.mutex.Lock()
+	defer ibm.mutex.Unlock()
+
+	if ibm.closed {
+		return fmt.Errorf("input binding manager is closed")
+	}
+	if !ibm.client.IsOpen() {
+		return fmt.Errorf("SimConnect client is not open")
+	}
+
+	key := strings.ToUpper(input)
+	if _, exists := ibm.bindings[key]; exists {
+		return fmt.Errorf("input %s is already bound", input)
+	}
+
+	// Assign new event IDs from the client's registry
+	b := &inputBinding{
+		input:   input,
+		binding: binding,
+		downID:  ibm.client.IDs().NewEventID(),
+		upID:    SIMCONNECT_UNUSED,
+	}
+	if binding.OnUp != nil {
+		b.upID = ibm.client.IDs().NewEventID()
+	}
+
+	first := len(ibm.bindings) == 0
+	if err := ibm.define(b, first); err != nil {
+		ibm.release(b)
+		return fmt.Errorf("failed to bind input %s: %v", input, err)
+	}
+
+	if binding.OnDown != nil {
+		ibm.events.bind(b.downID, input, binding.OnDown)
+	}
+	if binding.OnUp != nil {
+		ibm.events.bind(b.upID, input, binding.OnUp)
+	}
+	ibm.bindings[key] = b
+	return nil
+}
+
+// Unbind removes the binding of an input. Every removal is attempted and the binding is dropped
+// even when one fails, as retrying could not remove what is already gone; the failures are returned.
+func (ibm *InputBindingManager) Unbind(input string) error {
+	ibm.mutex.Lock()
+	defer ibm.mutex.Unlock()
+
+	key := strings.ToUpper(input)
+	b, exists := ibm.bindings[key]
+	if !exists {
+		return fmt.Errorf("input %s is not bound", input)
+	}
+
+	err := ibm.undefine(b)
+	delete(ibm.bindings, key)
+	ibm.release(b)
+	if err != nil {
+		return fmt.Errorf("input %s unbound, but SimConnect was not fully updated: %w", input, err)
+	}
+	return nil
+}
+
+// Clear removes all bindings. Like Unbind, the bindings are dropped even when clearing a group fails.
+func (ibm *InputBindingManager) Clear() error {
+	ibm.mutex.Lock()
+	defer ibm.mutex.Unlock()
+	return ibm.clear()
+}
+
+// Close removes all bindings like Clear, returns the input and notification group IDs to the
+// registry and unregisters the exception handler and replay from the client. A closed manager
+// cannot bind inputs anymore; closing it twice is a no-op. Close must not be called from a
+// dispatcher handler.
+func (ibm *InputBindingManager) Close() error {
+	ibm.mutex.Lock()
+	defer ibm.mutex.Unlock()
+
+	if ibm.closed {
+		return nil
+	}
+	ibm.closed = true
+
+	ibm.client.Dispatcher().RemoveHandler(ibm.onError)
+	ibm.client.removeReplay(ibm.replayID)
+
+	// Without a connection there is nothing to clear, SimConnect dropped the groups with it
+	var err error
+	if ibm.client.IsOpen() {
+		err = ibm.clear()
+	} else {
+		for key, b := range ibm.bindings {
+			delete(ibm.bindings, key)
+			ibm.release(b)
+		}
+	}
+
+	ibm.client.IDs().Release(IDInputGroup, uint32(ibm.inputGroup))
+	ibm.client.IDs().Release(IDNotificationGroup, uint32(ibm.notifyGroup))
+	return err
+}
+
+// clear removes all bindings; the caller holds the lock
+func (ibm *InputBindingManager) clear() error {
+	if len(ibm.bindings) == 0 {
+		return nil
+	}
+
+	var errs []error
+	if err := ibm.client.ClearInputGroup(ibm.inputGroup); err != nil {
+		errs = append(errs, fmt.Errorf("failed to clear input group: %w", err))
+	}
+	if ibm.hasCallbacks() {
+		if err := ibm.client.ClearNotificationGroup(ibm.notifyGroup); err != nil {
+			errs = append(errs, fmt.Errorf("failed to clear notification group: %w", err))
+		}
+	}
+
+	for key, b := range ibm.bindings {
+		delete(ibm.bindings, key)
+		ibm.release(b)
+	}
+	return errors.Join(errs...)
+}
+
+// SetEnabled turns all bindings on or off without removing them
+func (ibm *InputBindingManager) SetEnabled(enabled bool) error {
+	ibm.mutex.Lock()
+	defer ibm.mutex.Unlock()
+
+	state := SIMCONNECT_STATE_OFF
+	if enabled {
+		state = SIMCONNECT_STATE_ON
+	}
+
+	// SimConnect creates the input group with its first binding
+	if len(ibm.bindings) > 0 {
+		if err := ibm.client.SetInputGroupState(ibm.inputGroup, state); err != nil {
+			return fmt.Errorf("failed to set input group state: %v", err)
+		}
+	}
+	ibm.state = state
+	return nil
+}
+
+// SetPriority sets the priority of the bindings relative to other input groups.
+// Maskable bindings need SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE or lower.
+func (ibm *InputBindingManager) SetPriority(priority SIMCONNECT_GROUP_PRIORITY) error {
+	ibm.mutex.Lock()
+	defer ibm.mutex.Unlock()
+
+	// SimConnect creates the input group with its first binding
+	if len(ibm.bindings) > 0 {
+		if err := ibm.client.SetInputGroupPriority(ibm.inputGroup, priority); err != nil {
+			return fmt.Errorf("failed to set input group priority: %v", err)
+		}
+	}
+	ibm.priority = priority
+	return nil
+}
+
+// IsEnabled returns whether the bindings are turned on
+func (ibm *InputBindingManager) IsEnabled() bool {
+	ibm.mutex.RLock()
+	defer ibm.mutex.RUnlock()
+	return ibm.state == SIMCONNECT_STATE_ON
+}
+
+// GetBindings returns a copy of the bindings by input definition
+func (ibm *InputBindingManager) GetBindings() map[string]InputBinding {
+	ibm.mutex.RLock()
+	defer ibm.mutex.RUnlock()
+
+	result := make(map[string]InputBinding, len(ibm.bindings))
+	for _, b := range ibm.bindings {
+		result[b.input] = b.binding
+	}
+	return result
+}
+
+// GetErrors returns the error channel for monitoring runtime errors
+func (ibm *InputBindingManager) GetErrors() <-chan error {
+	return ibm.errorChan
+}
+
+// define maps a binding's client events and input; the first binding also configures the
+// input group, which SimConnect creates with its first input. When a step fails, the input and
+// notification group entries added so far are removed again. The caller holds the lock.
+func (ibm *InputBindingManager) define(b *inputBinding, first bool) (err error) {
+	var notified []SIMCONNECT_CLIENT_EVENT_ID // Client events added to the notification group
+	mapped := false                           // Whether the input is mapped in the input group
+	defer func() {
+		if err == nil {
+			return
+		}
+		if mapped {
+			if undoErr := ibm.client.RemoveInputEvent(ibm.inputGroup, b.input); undoErr != nil {
+				ibm.reportError(fmt.Errorf("failed to remove input %s after a failed binding: %v", b.input, undoErr))
+			}
+		}
+		for _, eventID := range notified {
+			if undoErr := ibm.client.RemoveClientEvent(ibm.notifyGroup, eventID); undoErr != nil {
+				ibm.reportError(fmt.Errorf("failed to remove client event %d after a failed binding: %v", eventID, undoErr))
+			}
+		}
+	}()
+
+	if err := ibm.client.MapClientEventToSimEvent(b.downID, b.binding.SimEvent); err != nil {
+		return err
+	}
+	if b.binding.OnDown != nil {
+		if err := ibm.notify(b.downID); err != nil {
+			return err
+		}
+		notified = append(notified, b.downID)
+	}
+	if b.binding.OnUp != nil {
+		if err := ibm.client.MapClientEventToSimEvent(b.upID, ""); err != nil {
+			return err
+		}
+		if err := ibm.notify(b.upID); err != nil {
+			return err
+		}
+		notified = append(notified, b.upID)
+	}
+
+	if err := ibm.client.MapInputEventToClientEvent(ibm.inputGroup, b.input, b.downID, b.binding.DownValue, b.upID, b.binding.UpValue, b.binding.Maskable); err != nil {
+		return err
+	}
+	mapped = true
+
+	if first {
+		if err := ibm.client.SetInputGroupPriority(ibm.inputGroup, ibm.priority); err != nil {
+			return err
+		}
+		if err := ibm.client.SetInputGroupState(ibm.inputGroup, ibm.state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// undefine removes a binding's input and client events from their groups. Every removal is
+// attempted; the failures are returned together. The caller holds the lock.
+func (ibm *InputBindingManager) undefine(b *inputBinding) error {
+	var errs []error
+	if err := ibm.client.RemoveInputEvent(ibm.inputGroup, b.input); err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove input: %w", err))
+	}
+	if b.binding.OnDown != nil {
+		if err := ibm.client.RemoveClientEvent(ibm.notifyGroup, b.downID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove press event: %w", err))
+		}
+	}
+	if b.binding.OnUp != nil {
+		if err := ibm.client.RemoveClientEvent(ibm.notifyGroup, b.upID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove release event: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notify adds a client event to the notification group, so the client receives it
+func (ibm *InputBindingManager) notify(eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	if err := ibm.client.AddClientEventToNotificationGroup(ibm.notifyGroup, eventID, false); err != nil {
+		return err
+	}
+	return ibm.client.SetNotificationGroupPriority(ibm.notifyGroup, SIMCONNECT_GROUP_PRIORITY_HIGHEST)
+}
+
+// release stops delivering a binding's events and returns its event IDs to the registry
+func (ibm *InputBindingManager) release(b *inputBinding) {
+	ibm.events.unbind(b.downID)
+	ibm.client.IDs().Release(IDEvent, uint32(b.downID))
+	if b.upID != SIMCONNECT_UNUSED {
+		ibm.events.unbind(b.upID)
+		ibm.client.IDs().Release(IDEvent, uint32(b.upID))
+	}
+}
+
+// hasCallbacks reports whether any binding uses the notification group; the caller holds the lock
+func (ibm *InputBindingManager) hasCallbacks() bool {
+	for _, b := range ibm.bindings {
+		if b.binding.OnDown != nil || b.binding.OnUp != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// replay re-creates all bindings on a new connection
+func (ibm *InputBindingManager) replay() error {
+	ibm.mutex.RLock()
+	defer ibm.mutex.RUnlock()
+
+	first := true
+	for _, b := range ibm.bindings {
+		if err := ibm.define(b, first); err != nil {
+			return fmt.Errorf("failed to re-bind input %s: %v", b.input, err)
+		}
+		first = false
+	}
+	return nil
+}
+
+// handleException claims exceptions caused by this manager's groups and client events
+func (ibm *InputBindingManager) handleException(err *ExceptionError) bool {
+	if err.Packet == nil {
+		return false
+	}
+
+	owned := err.Packet.InputGroupID == ibm.inputGroup || err.Packet.GroupID == ibm.notifyGroup
+	if !owned && err.Packet.EventID != 0 {
+		ibm.mutex.RLock()
+		for _, b := range ibm.bindings {
+			if err.Packet.EventID == b.downID || err.Packet.EventID == b.upID {
+				owned = true
+				break
+			}
+		}
+		ibm.mutex.RUnlock()
+	}
+
+	if owned {
+		ibm.reportError(err)
+	}
+	return owned
+}
+
+// reportError sends an error to the error channel without blocking
+func (ibm *InputBindingManager) reportError(err error) {
+	select {
+	case ibm.errorChan <- err:
+	default: // Channel full, skip this error
+	}
+}
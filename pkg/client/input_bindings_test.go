@@ -0,0 +1,147 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// newInputBindings returns an InputBindingManager on a client of server
+func newInputBindings(t *testing.T, server *simtest.Server) *client.InputBindingManager {
+	t.Helper()
+	simClient := openClient(t, server)
+	return client.NewInputBindingManager(simClient, client.NewSystemEventManager(simClient))
+}
+
+// notifiedEvents counts the client events in all notification groups of server
+func notifiedEvents(server *simtest.Server) int {
+	count := 0
+	for _, group := range server.NotificationGroups() {
+		count += len(group.Events)
+	}
+	return count
+}
+
+func TestInputBindingFailedBindIsUndone(t *testing.T) {
+	server := simtest.NewServer()
+	bindings := newInputBindings(t, server)
+	callback := func(client.SystemEventData) {}
+
+	tests := []struct {
+		name     string
+		function string
+	}{
+		{"input mapping fails", "SimConnect_MapInputEventToClientEvent"},
+		{"input group setup fails", "SimConnect_SetInputGroupState"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.FailCall(tt.function, errors.New("refused"))
+			defer server.FailCall(tt.function, nil)
+
+			err := bindings.Bind("shift+ctrl+u", client.InputBinding{OnDown: callback, OnUp: callback})
+			if err == nil {
+				t.Fatal("Bind succeeded")
+			}
+			if n := notifiedEvents(server); n != 0 {
+				t.Errorf("%d client events left in notification groups", n)
+			}
+			for groupID, group := range server.InputGroups() {
+				if len(group.Inputs) != 0 {
+					t.Errorf("input group %d still maps %v", groupID, group.Inputs)
+				}
+			}
+			if len(bindings.GetBindings()) != 0 {
+				t.Error("failed binding was kept")
+			}
+		})
+	}
+}
+
+func TestInputBindingUnbindFinishesRemovals(t *testing.T) {
+	server := simtest.NewServer()
+	bindings := newInputBindings(t, server)
+	callback := func(client.SystemEventData) {}
+
+	if err := bindings.Bind("shift+ctrl+u", client.InputBinding{OnDown: callback, OnUp: callback}); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	refused := errors.New("refused")
+	server.FailCall("SimConnect_RemoveClientEvent", refused)
+	err := bindings.Unbind("shift+ctrl+u")
+	server.FailCall("SimConnect_RemoveClientEvent", nil)
+
+	if !errors.Is(err, refused) {
+		t.Fatalf("Unbind error %v, want the RemoveClientEvent failure", err)
+	}
+	if len(bindings.GetBindings()) != 0 {
+		t.Error("binding kept after Unbind")
+	}
+	for groupID, group := range server.InputGroups() {
+		if len(group.Inputs) != 0 {
+			t.Errorf("input group %d still maps %v", groupID, group.Inputs)
+		}
+	}
+
+	// The input can be bound again
+	if err := bindings.Bind("shift+ctrl+u", client.InputBinding{OnDown: callback}); err != nil {
+		t.Errorf("Bind after Unbind: %v", err)
+	}
+}
+
+func TestInputBindingManagerClose(t *testing.T) {
+	server := simtest.NewServer()
+	simClient := openClient(t, server)
+	simClient.Dispatcher().Start()
+	defer simClient.Dispatcher().Stop()
+
+	bindings := client.NewInputBindingManager(simClient, client.NewSystemEventManager(simClient))
+	callback := func(client.SystemEventData) {}
+	if err := bindings.Bind("shift+ctrl+u", client.InputBinding{SimEvent: "GEAR_TOGGLE", OnDown: callback}); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	var inputGroup client.SIMCONNECT_INPUT_GROUP_ID
+	for groupID := range server.InputGroups() {
+		inputGroup = groupID
+	}
+	var notifyGroup client.SIMCONNECT_NOTIFICATION_GROUP_ID
+	for groupID := range server.NotificationGroups() {
+		notifyGroup = groupID
+	}
+	if inputGroup == 0 || notifyGroup == 0 {
+		t.Fatalf("input group %d, notification group %d, want both created", inputGroup, notifyGroup)
+	}
+
+	if err := bindings.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if groups := server.InputGroups(); len(groups) != 0 {
+		t.Errorf("input groups %v still registered after Close", groups)
+	}
+	if n := notifiedEvents(server); n != 0 {
+		t.Errorf("%d client events left in notification groups", n)
+	}
+	if simClient.IDs().InUse(client.IDInputGroup, uint32(inputGroup)) {
+		t.Errorf("input group ID %d still in use after Close", inputGroup)
+	}
+	if simClient.IDs().InUse(client.IDNotificationGroup, uint32(notifyGroup)) {
+		t.Errorf("notification group ID %d still in use after Close", notifyGroup)
+	}
+	if err := bindings.Bind("shift+ctrl+u", client.InputBinding{SimEvent: "GEAR_TOGGLE"}); err == nil {
+		t.Error("Bind after Close succeeded")
+	}
+	if err := bindings.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// Exceptions for the closed manager's input group are no longer claimed by it
+	if err := simClient.ClearInputGroup(inputGroup); err != nil {
+		t.Fatalf("ClearInputGroup: %v", err)
+	}
+	sendID := server.LastSendID()
+	server.InjectException(uint32(client.SIMCONNECT_EXCEPTION_ERROR), sendID, 1)
+	exceptionFor(t, simClient.Dispatcher().GetErrors(), sendID)
+}
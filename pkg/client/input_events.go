@@ -0,0 +1,683 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// inputEventRefreshTimeout bounds the re-enumeration triggered by the AircraftLoaded system event
+const inputEventRefreshTimeout = 10 * time.Second
+
+// InputEventInfo describes an input event of the loaded aircraft
+type InputEventInfo struct {
+	Name string                      // Input event name, e.g. "AUTOPILOT_AP_MASTER"
+	Hash uint64                      // Hash identifying the input event in SimConnect calls
+	Type SIMCONNECT_INPUT_EVENT_TYPE // Value type, DOUBLE or STRING
+}
+
+// InputEventValue is the value of an input event delivered to subscription callbacks
+type InputEventValue struct {
+	InputEventInfo             // Input event the value belongs to
+	Value          interface{} // float64 or string depending on Type
+}
+
+// InputEventCallback is a function type for input event subscription callbacks
+type InputEventCallback func(value InputEventValue)
+
+// inputEventTable holds the input events of one aircraft
+type inputEventTable struct {
+	byName map[string]InputEventInfo // Input events by upper-case name
+	byHash map[uint64]InputEventInfo // Input events by hash
+}
+
+// InputEventManager reads, writes and subscribes to the Input Events (B-events) MSFS 2024 aircraft
+// expose their cockpit controls through, by name. The input events of each aircraft are enumerated
+// once and cached by the aircraft's path. While running, the manager follows the AircraftLoaded
+// system event: it switches to the new aircraft's input events and moves subscriptions over.
+// Input Events need the MSFS 2024 SimConnect.dll; other backends fail with E_NOTIMPL.
+//
+// Methods taking a context may query the loaded aircraft, enumerate its input events or wait for an
+// answer, all of which is delivered by the dispatcher's goroutine. They must not be called from a
+// dispatcher callback of any component, e.g. an input event, system event or flight data callback:
+// the callback blocks that goroutine and the call would wait until ctx is done.
+type InputEventManager struct {
+	client        *Client                       // SimConnect client
+	mutex         sync.RWMutex                  // Thread safety
+	refreshMutex  sync.Mutex                    // Serialises aircraft switches
+	aircraft      string                        // Path of the aircraft the current input events belong to
+	tables        map[string]*inputEventTable   // Input events by aircraft path
+	subscriptions map[string]InputEventCallback // Subscription callbacks by upper-case name
+	active        map[uint64]string             // Subscribed hashes of the current aircraft, by upper-case name
+	loadedEvent   SIMCONNECT_CLIENT_EVENT_ID    // AircraftLoaded subscription while running
+	running       bool                          // Manager state
+	handlers      []HandlerID                   // Dispatcher handlers registered while running
+	closed        bool                          // Close was called, the manager cannot be used anymore
+	onError       HandlerID                     // Exception handler registered by NewInputEventManager
+	replayID      HandlerID                     // Replay registered by NewInputEventManager
+	errorChan     chan error                    // Error notifications
+}
+
+// NewInputEventManager creates a new InputEventManager instance
+func NewInputEventManager(client *Client) *InputEventManager {
+	iem := &InputEventManager{
+		client:        client,
+		tables:        make(map[string]*inputEventTable),
+		subscriptions: make(map[string]InputEventCallback),
+		active:        make(map[uint64]string),
+		errorChan:     make(chan error, 10), // Buffered channel for non-blocking errors
+	}
+
+	// Exceptions caused by our subscriptions and writes are reported on our error channel
+	iem.onError = client.Dispatcher().HandleException(iem.handleException)
+	// Subscriptions are re-created when a Supervisor reconnects the client
+	iem.replayID = client.addReplay(iem.replay)
+	return iem
+}
+
+// Start follows aircraft changes and delivers subscription updates until Stop is called
+func (iem *InputEventManager) Start() error {
+	iem.mutex.Lock()
+	defer iem.mutex.Unlock()
+
+	if iem.closed {
+		return fmt.Errorf("InputEventManager is closed")
+	}
+	if iem.running {
+		return fmt.Errorf("InputEventManager is already running")
+	}
+
+	if !iem.client.IsOpen() {
+		return fmt.Errorf("SimConnect client is not open")
+	}
+
+	eventID := iem.client.IDs().NewEventID()
+	if err := iem.client.SubscribeToSystemEvent(eventID, SystemEventAircraftLoaded); err != nil {
+		iem.client.IDs().Release(IDEvent, uint32(eventID))
+		return fmt.Errorf("failed to subscribe to event '%s': %v", SystemEventAircraftLoaded, err)
+	}
+
+	dispatcher := iem.client.Dispatcher()
+	iem.handlers = append(iem.handlers,
+		dispatcher.HandleEvent(eventID, iem.handleAircraftLoaded),
+		dispatcher.HandleMessageType(SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT, iem.handleValue),
+	)
+	iem.loadedEvent = eventID
+	iem.running = true
+	dispatcher.Start()
+
+	return nil
+}
+
+// Run starts the manager, blocks until ctx is done and stops it again.
+// It returns the Start error, or ctx.Err() once stopped.
+func (iem *InputEventManager) Run(ctx context.Context) error {
+	if err := iem.Start(); err != nil {
+		return err
+	}
+	defer iem.Stop()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Stop halts following aircraft changes and delivering subscription updates
+func (iem *InputEventManager) Stop() {
+	iem.mutex.Lock()
+	if !iem.running {
+		iem.mutex.Unlock()
+		return
+	}
+
+	dispatcher := iem.client.Dispatcher()
+	for _, id := range iem.handlers {
+		dispatcher.RemoveHandler(id)
+	}
+	if iem.client.IsOpen() {
+		if err := iem.client.UnsubscribeFromSystemEvent(iem.loadedEvent); err != nil {
+			iem.reportError(fmt.Errorf("failed to unsubscribe from event '%s': %v", SystemEventAircraftLoaded, err))
+		}
+	}
+	iem.client.IDs().Release(IDEvent, uint32(iem.loadedEvent))
+	iem.loadedEvent = 0
+	iem.handlers = nil
+	iem.running = false
+	iem.mutex.Unlock()
+
+	// Released outside the lock, a handler may still be waiting for it
+	dispatcher.Stop()
+}
+
+// Close stops the manager, unsubscribes from its input events and unregisters its exception
+// handler and replay from the client. A closed manager cannot be used anymore; closing it twice
+// is a no-op. Close must not be called from a dispatcher handler.
+func (iem *InputEventManager) Close() error {
+	iem.Stop()
+
+	iem.mutex.Lock()
+	defer iem.mutex.Unlock()
+
+	if iem.closed {
+		return nil
+	}
+	iem.closed = true
+
+	iem.client.Dispatcher().RemoveHandler(iem.onError)
+	iem.client.removeReplay(iem.replayID)
+
+	// Without a connection there is nothing to unsubscribe, SimConnect dropped the subscriptions with it
+	var result error
+	for hash, key := range iem.active {
+		if !iem.client.IsOpen() {
+			break
+		}
+		if err := iem.client.unsubscribeInputEvent(iem, hash); err != nil && result == nil {
+			result = fmt.Errorf("failed to unsubscribe from input event %s: %v", key, err)
+		}
+	}
+	iem.active = make(map[uint64]string)
+	iem.subscriptions = make(map[string]InputEventCallback)
+	return result
+}
+
+// IsRunning returns whether the manager is currently running
+func (iem *InputEventManager) IsRunning() bool {
+	iem.mutex.RLock()
+	defer iem.mutex.RUnlock()
+	return iem.running
+}
+
+// Refresh queries the loaded aircraft and enumerates its input events again, replacing the cached
+// ones. Without a running manager it is the way to pick up an aircraft change.
+// It waits for the dispatcher and must not be called from a dispatcher callback.
+func (iem *InputEventManager) Refresh(ctx context.Context) error {
+	state, err := iem.client.QuerySystemState(ctx, SystemStateAircraftLoaded)
+	if err != nil {
+		return fmt.Errorf("failed to query the loaded aircraft: %v", err)
+	}
+	return iem.load(ctx, state.(SystemStatePath).Path, true)
+}
+
+// Aircraft returns the path of the aircraft the current input events belong to, empty until loaded
+func (iem *InputEventManager) Aircraft() string {
+	iem.mutex.RLock()
+	defer iem.mutex.RUnlock()
+	return iem.aircraft
+}
+
+// GetInputEvents returns the input events of the loaded aircraft ordered by name,
+// enumerating them first if they are not cached yet
+func (iem *InputEventManager) GetInputEvents(ctx context.Context) ([]InputEventInfo, error) {
+	if err := iem.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	iem.mutex.RLock()
+	defer iem.mutex.RUnlock()
+
+	table := iem.tables[iem.aircraft]
+	events := make([]InputEventInfo, 0, len(table.byName))
+	for _, info := range table.byName {
+		events = append(events, info)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+	return events, nil
+}
+
+// Lookup returns the input event of the loaded aircraft with the given name. When the input events
+// of the loaded aircraft are not known yet it enumerates them like Refresh, so it must not be
+// called from a dispatcher callback.
+func (iem *InputEventManager) Lookup(ctx context.Context, name string) (InputEventInfo, error) {
+	if err := iem.ensure(ctx); err != nil {
+		return InputEventInfo{}, err
+	}
+
+	iem.mutex.RLock()
+	defer iem.mutex.RUnlock()
+	return iem.lookup(name)
+}
+
+// Get reads the value of an input event, a float64 or a string depending on its type
+func (iem *InputEventManager) Get(ctx context.Context, name string) (interface{}, error) {
+	info, err := iem.Lookup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID := uint32(iem.client.IDs().NewRequestID())
+	defer iem.client.IDs().Release(IDRequest, requestID)
+
+	var value interface{}
+	dispatcher := iem.client.Dispatcher()
+	err = iem.await(ctx,
+		func(receive MessageHandler) HandlerID { return dispatcher.HandleRequest(requestID, receive) },
+		func(packet *SentPacket) bool {
+			return packet.Operation == "GetInputEvent" && packet.RequestID == requestID
+		},
+		func() error { return iem.client.GetInputEvent(DataRequestID(requestID), info.Hash) },
+		func(data []byte) (bool, error) {
+			recv, err := ParseGetInputEvent(data)
+			if err != nil {
+				return false, err
+			}
+			value = recv.Value
+			return true, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input event %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// Set writes the value of an input event: a float64 for DOUBLE input events, a string for
+// STRING input events. SimConnect does not confirm writes; exceptions are reported on GetErrors.
+func (iem *InputEventManager) Set(ctx context.Context, name string, value interface{}) error {
+	info, err := iem.Lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	switch value.(type) {
+	case float64:
+		if info.Type != SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE {
+			return fmt.Errorf("input event %s takes a string value", name)
+		}
+	case string:
+		if info.Type != SIMCONNECT_INPUT_EVENT_TYPE_STRING {
+			return fmt.Errorf("input event %s takes a float64 value", name)
+		}
+	default:
+		return fmt.Errorf("unsupported input event value type %T, use float64 or string", value)
+	}
+
+	if err := iem.client.setInputEvent(iem, info.Hash, value); err != nil {
+		return fmt.Errorf("failed to set input event %s: %v", name, err)
+	}
+	return nil
+}
+
+// Params returns the parameter types of an input event as reported by SimConnect, separated by ';'
+func (iem *InputEventManager) Params(ctx context.Context, name string) (string, error) {
+	info, err := iem.Lookup(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	var params string
+	dispatcher := iem.client.Dispatcher()
+	err = iem.await(ctx,
+		func(receive MessageHandler) HandlerID {
+			return dispatcher.HandleMessageType(SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS, receive)
+		},
+		func(packet *SentPacket) bool {
+			return packet.Operation == "EnumerateInputEventParams" && packet.InputEvent == info.Hash
+		},
+		func() error { return iem.client.EnumerateInputEventParams(info.Hash) },
+		func(data []byte) (bool, error) {
+			recv, err := ParseEnumerateInputEventParams(data)
+			if err != nil {
+				return false, err
+			}
+			if recv.Hash != info.Hash {
+				return false, nil // Answer to another call
+			}
+			params = recv.Value
+			return true, nil
+		})
+	if err != nil {
+		return "", fmt.Errorf("failed to enumerate parameters of input event %s: %w", name, err)
+	}
+	return params, nil
+}
+
+// Subscribe delivers value changes of an input event to callback while the manager runs.
+// The subscription follows aircraft changes by name; it is inactive while the loaded aircraft
+// has no input event of that name. Callbacks run in order on the dispatcher's goroutine and
+// must return quickly. Subscribe may enumerate like Lookup, so it must not be called from a
+// dispatcher callback, and neither must any other method taking a context; hand such work to
+// another goroutine.
+func (iem *InputEventManager) Subscribe(ctx context.Context, name string, callback InputEventCallback) error {
+	if callback == nil {
+		return fmt.Errorf("callback for input event %s is nil", name)
+	}
+
+	info, err := iem.Lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	iem.mutex.Lock()
+	defer iem.mutex.Unlock()
+
+	if iem.closed {
+		return fmt.Errorf("InputEventManager is closed") // Closed in the meantime
+	}
+	key := strings.ToUpper(name)
+	if _, exists := iem.subscriptions[key]; exists {
+		return fmt.Errorf("input event %s is already subscribed", name)
+	}
+
+	if err := iem.client.subscribeInputEvent(iem, info.Hash); err != nil {
+		return fmt.Errorf("failed to subscribe to input event %s: %v", name, err)
+	}
+
+	iem.subscriptions[key] = callback
+	iem.active[info.Hash] = key
+	return nil
+}
+
+// Unsubscribe removes the subscription of an input event
+func (iem *InputEventManager) Unsubscribe(name string) error {
+	iem.mutex.Lock()
+	defer iem.mutex.Unlock()
+
+	key := strings.ToUpper(name)
+	if _, exists := iem.subscriptions[key]; !exists {
+		return fmt.Errorf("input event %s is not subscribed", name)
+	}
+
+	for hash, subscribed := range iem.active {
+		if subscribed != key {
+			continue
+		}
+		if err := iem.client.unsubscribeInputEvent(iem, hash); err != nil {
+			return fmt.Errorf("failed to unsubscribe from input event %s: %v", name, err)
+		}
+		delete(iem.active, hash)
+	}
+
+	delete(iem.subscriptions, key)
+	return nil
+}
+
+// GetSubscriptions returns the names of the subscribed input events
+func (iem *InputEventManager) GetSubscriptions() []string {
+	iem.mutex.RLock()
+	defer iem.mutex.RUnlock()
+
+	names := make([]string, 0, len(iem.subscriptions))
+	for key := range iem.subscriptions {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetErrors returns the error channel for monitoring runtime errors
+func (iem *InputEventManager) GetErrors() <-chan error {
+	return iem.errorChan
+}
+
+// ensure loads the input events of the loaded aircraft unless they are known
+func (iem *InputEventManager) ensure(ctx context.Context) error {
+	iem.mutex.RLock()
+	_, known := iem.tables[iem.aircraft]
+	closed := iem.closed
+	iem.mutex.RUnlock()
+
+	if closed {
+		return fmt.Errorf("InputEventManager is closed")
+	}
+	if known {
+		return nil
+	}
+	return iem.Refresh(ctx)
+}
+
+// load makes the input events of an aircraft current, enumerating them unless they are cached
+// (or force is set), and moves the subscriptions over to the aircraft's hashes
+func (iem *InputEventManager) load(ctx context.Context, aircraft string, force bool) error {
+	iem.refreshMutex.Lock()
+	defer iem.refreshMutex.Unlock()
+
+	iem.mutex.RLock()
+	table, cached := iem.tables[aircraft]
+	iem.mutex.RUnlock()
+
+	if !cached || force {
+		events, err := iem.enumerate(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to enumerate input events of %s: %v", aircraft, err)
+		}
+		table = &inputEventTable{
+			byName: make(map[string]InputEventInfo, len(events)),
+			byHash: make(map[uint64]InputEventInfo, len(events)),
+		}
+		for _, info := range events {
+			table.byName[strings.ToUpper(info.Name)] = info
+			table.byHash[info.Hash] = info
+		}
+	}
+
+	iem.mutex.Lock()
+	defer iem.mutex.Unlock()
+
+	iem.tables[aircraft] = table
+	iem.aircraft = aircraft
+	return iem.resubscribe(table)
+}
+
+// resubscribe moves subscriptions to the hashes of table; the caller holds the lock
+func (iem *InputEventManager) resubscribe(table *inputEventTable) error {
+	var failed error
+	for hash, key := range iem.active {
+		if info, exists := table.byName[key]; exists && info.Hash == hash {
+			continue // Same input event on the new aircraft
+		}
+		if err := iem.client.unsubscribeInputEvent(iem, hash); err != nil && failed == nil {
+			failed = fmt.Errorf("failed to unsubscribe from input event %s: %v", key, err)
+		}
+		delete(iem.active, hash)
+	}
+
+	for key := range iem.subscriptions {
+		info, exists := table.byName[key]
+		if !exists {
+			continue // Not available on this aircraft
+		}
+		if _, subscribed := iem.active[info.Hash]; subscribed {
+			continue
+		}
+		if err := iem.client.subscribeInputEvent(iem, info.Hash); err != nil {
+			if failed == nil {
+				failed = fmt.Errorf("failed to subscribe to input event %s: %v", key, err)
+			}
+			continue
+		}
+		iem.active[info.Hash] = key
+	}
+	return failed
+}
+
+// enumerate requests the input events of the loaded aircraft and collects every part of the answer
+func (iem *InputEventManager) enumerate(ctx context.Context) ([]InputEventInfo, error) {
+	requestID := uint32(iem.client.IDs().NewRequestID())
+	defer iem.client.IDs().Release(IDRequest, requestID)
+
+	var events []InputEventInfo
+	received := make(map[uint32]bool)
+	dispatcher := iem.client.Dispatcher()
+	err := iem.await(ctx,
+		func(receive MessageHandler) HandlerID { return dispatcher.HandleRequest(requestID, receive) },
+		func(packet *SentPacket) bool {
+			return packet.Operation == "EnumerateInputEvents" && packet.RequestID == requestID
+		},
+		func() error { return iem.client.EnumerateInputEvents(DataRequestID(requestID)) },
+		func(data []byte) (bool, error) {
+			recv, err := ParseEnumerateInputEvents(data)
+			if err != nil {
+				return false, err
+			}
+			for _, descriptor := range recv.List {
+				events = append(events, InputEventInfo{Name: descriptor.Name, Hash: descriptor.Hash, Type: descriptor.Type})
+			}
+			received[recv.EntryNumber] = true
+			return uint32(len(received)) >= recv.OutOf, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// await sends a call and waits until receive reports its answer complete, SimConnect raises an
+// exception for the call matched by owns, or ctx is done. receive runs on the dispatcher goroutine.
+func (iem *InputEventManager) await(ctx context.Context, handle func(MessageHandler) HandlerID, owns func(*SentPacket) bool, send func() error, receive func(data []byte) (bool, error)) error {
+	done := make(chan struct{})
+	failures := make(chan error, 1)
+
+	var once sync.Once
+	dispatcher := iem.client.Dispatcher()
+	dataHandler := handle(func(data []byte) {
+		complete, err := receive(data)
+		if err != nil {
+			select {
+			case failures <- err:
+			default:
+			}
+			return
+		}
+		if complete {
+			once.Do(func() { close(done) })
+		}
+	})
+	defer dispatcher.RemoveHandler(dataHandler)
+
+	exceptionHandler := dispatcher.HandleException(func(err *ExceptionError) bool {
+		if err.Packet == nil || !owns(err.Packet) {
+			return false
+		}
+		select {
+		case failures <- err:
+		default:
+		}
+		return true
+	})
+	defer dispatcher.RemoveHandler(exceptionHandler)
+
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	if err := send(); err != nil {
+		return err
+	}
+
+	select {
+	case <-done:
+		return nil
+	case err := <-failures:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// lookup finds an input event of the current aircraft; the caller holds the lock
+func (iem *InputEventManager) lookup(name string) (InputEventInfo, error) {
+	info, exists := iem.tables[iem.aircraft].byName[strings.ToUpper(name)]
+	if !exists {
+		return InputEventInfo{}, fmt.Errorf("aircraft %s has no input event %s", iem.aircraft, name)
+	}
+	return info, nil
+}
+
+// handleAircraftLoaded switches to the input events of a newly loaded aircraft. Enumerating waits
+// for the dispatcher, so it runs in its own goroutine.
+func (iem *InputEventManager) handleAircraftLoaded(data []byte) {
+	event, err := ParseEventFilename(data)
+	if err != nil {
+		iem.reportError(fmt.Errorf("error parsing event data: %v", err))
+		return
+	}
+
+	aircraft := cStringToGoString(event.SzFileName[:])
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), inputEventRefreshTimeout)
+		defer cancel()
+
+		if err := iem.load(ctx, aircraft, false); err != nil {
+			iem.reportError(err)
+		}
+	}()
+}
+
+// handleValue is the dispatcher handler for SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT messages
+func (iem *InputEventManager) handleValue(data []byte) {
+	recv, err := ParseSubscribeInputEvent(data)
+	if err != nil {
+		iem.reportError(fmt.Errorf("error parsing input event: %v", err))
+		return
+	}
+
+	iem.mutex.RLock()
+	key, exists := iem.active[recv.Hash]
+	callback := iem.subscriptions[key]
+	var info InputEventInfo
+	if exists {
+		info = iem.tables[iem.aircraft].byHash[recv.Hash]
+	}
+	iem.mutex.RUnlock()
+
+	if !exists || callback == nil {
+		return // Input event of another component
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			iem.reportError(fmt.Errorf("input event callback panic: %v", r))
+		}
+	}()
+	callback(InputEventValue{InputEventInfo: info, Value: recv.Value})
+}
+
+// replay re-subscribes to the AircraftLoaded system event and the active input events on a new connection
+func (iem *InputEventManager) replay() error {
+	iem.mutex.RLock()
+	defer iem.mutex.RUnlock()
+
+	if iem.running {
+		if err := iem.client.SubscribeToSystemEvent(iem.loadedEvent, SystemEventAircraftLoaded); err != nil {
+			return fmt.Errorf("failed to re-subscribe to event '%s': %v", SystemEventAircraftLoaded, err)
+		}
+	}
+	for hash, key := range iem.active {
+		if err := iem.client.subscribeInputEvent(iem, hash); err != nil {
+			return fmt.Errorf("failed to re-subscribe to input event %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// handleException claims exceptions caused by this manager's subscriptions and writes, recognised
+// by the owner recorded with the call, and by its AircraftLoaded subscription
+func (iem *InputEventManager) handleException(err *ExceptionError) bool {
+	if err.Packet == nil {
+		return false
+	}
+
+	owned := err.Packet.owner == iem
+	if !owned {
+		switch err.Packet.Operation {
+		case "SubscribeToSystemEvent", "UnsubscribeFromSystemEvent":
+			iem.mutex.RLock()
+			owned = iem.loadedEvent != 0 && err.Packet.EventID == iem.loadedEvent
+			iem.mutex.RUnlock()
+		}
+	}
+
+	if owned {
+		iem.reportError(err)
+	}
+	return owned
+}
+
+// reportError sends an error to the error channel without blocking
+func (iem *InputEventManager) reportError(err error) {
+	select {
+	case iem.errorChan <- err:
+	default: // Channel full, skip this error
+	}
+}
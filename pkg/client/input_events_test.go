@@ -0,0 +1,302 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// newInputEvents returns an InputEventManager on a client of server and a context for its calls
+func newInputEvents(t *testing.T, server *simtest.Server) (*client.InputEventManager, *client.Client, context.Context) {
+	t.Helper()
+	simClient := openClient(t, server)
+	iem := client.NewInputEventManager(simClient)
+	t.Cleanup(func() { iem.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return iem, simClient, ctx
+}
+
+// inputEventNames returns the names of events
+func inputEventNames(events []client.InputEventInfo) []string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = event.Name
+	}
+	return names
+}
+
+func TestInputEventManagerCollectsMultiPartEnumeration(t *testing.T) {
+	server := simtest.NewServer()
+	// More input events than the server sends in one message
+	var events []simtest.InputEvent
+	var want []string
+	for i := 0; i < 120; i++ {
+		name := fmt.Sprintf("INPUT_%03d", i)
+		events = append(events, simtest.InputEvent{Name: name})
+		want = append(want, name)
+	}
+	server.LoadAircraft("Airbus A320", events...)
+	iem, _, ctx := newInputEvents(t, server)
+
+	got, err := iem.GetInputEvents(ctx)
+	if err != nil {
+		t.Fatalf("GetInputEvents: %v", err)
+	}
+	if names := inputEventNames(got); !reflect.DeepEqual(names, want) {
+		t.Fatalf("%d input events %v, want %d", len(names), names, len(want))
+	}
+	for _, info := range got {
+		if info.Hash != simtest.InputEventHash(info.Name) || info.Type != client.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE {
+			t.Errorf("input event %+v, want hash %#x of a DOUBLE", info, simtest.InputEventHash(info.Name))
+		}
+	}
+	if aircraft := iem.Aircraft(); aircraft != "Airbus A320" {
+		t.Errorf("aircraft %q, want Airbus A320", aircraft)
+	}
+}
+
+func TestInputEventManagerCachesInputEventsPerAircraft(t *testing.T) {
+	server := simtest.NewServer()
+	server.LoadAircraft("A", simtest.InputEvent{Name: "A_FIRST"})
+	iem, _, ctx := newInputEvents(t, server)
+
+	names := func() []string {
+		t.Helper()
+		events, err := iem.GetInputEvents(ctx)
+		if err != nil {
+			t.Fatalf("GetInputEvents: %v", err)
+		}
+		return inputEventNames(events)
+	}
+	if got := names(); !reflect.DeepEqual(got, []string{"A_FIRST"}) {
+		t.Fatalf("input events %v, want [A_FIRST]", got)
+	}
+
+	// Answered from the cache, not enumerated again
+	server.SetInputEvents(simtest.InputEvent{Name: "A_CHANGED"})
+	if got := names(); !reflect.DeepEqual(got, []string{"A_FIRST"}) {
+		t.Errorf("input events %v, want the cached [A_FIRST]", got)
+	}
+
+	if err := iem.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	server.LoadAircraft("B", simtest.InputEvent{Name: "B_FIRST"})
+	waitFor(t, "aircraft B", func() bool { return iem.Aircraft() == "B" })
+	if got := names(); !reflect.DeepEqual(got, []string{"B_FIRST"}) {
+		t.Errorf("input events %v, want [B_FIRST]", got)
+	}
+
+	// Returning to a known aircraft uses its cached input events
+	server.LoadAircraft("A", simtest.InputEvent{Name: "A_RELOADED"})
+	waitFor(t, "aircraft A", func() bool { return iem.Aircraft() == "A" })
+	if got := names(); !reflect.DeepEqual(got, []string{"A_FIRST"}) {
+		t.Errorf("input events %v, want the cached [A_FIRST]", got)
+	}
+
+	// Refresh replaces the cached input events
+	if err := iem.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := names(); !reflect.DeepEqual(got, []string{"A_RELOADED"}) {
+		t.Errorf("input events %v after Refresh, want [A_RELOADED]", got)
+	}
+}
+
+func TestInputEventManagerMovesSubscriptionsOnAircraftLoaded(t *testing.T) {
+	server := simtest.NewServer()
+	server.LoadAircraft("A", simtest.InputEvent{Name: "AUTOPILOT_AP_MASTER", Hash: 0xA1})
+	iem, _, ctx := newInputEvents(t, server)
+
+	values := make(chan client.InputEventValue, 4)
+	if err := iem.Subscribe(ctx, "autopilot_ap_master", func(value client.InputEventValue) { values <- value }); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err := iem.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if got := server.InputEventSubscriptions(); !reflect.DeepEqual(got, []uint64{0xA1}) {
+		t.Fatalf("server subscriptions %#x, want [0xa1]", got)
+	}
+
+	// The same input event has another hash on the new aircraft
+	server.LoadAircraft("B", simtest.InputEvent{Name: "AUTOPILOT_AP_MASTER", Hash: 0xB1})
+	waitFor(t, "subscription on aircraft B", func() bool {
+		return reflect.DeepEqual(server.InputEventSubscriptions(), []uint64{0xB1})
+	})
+
+	server.SetInputEventValue("AUTOPILOT_AP_MASTER", 1.0)
+	select {
+	case value := <-values:
+		if value.Hash != 0xB1 || value.Value != 1.0 {
+			t.Errorf("received %+v, want 1 for hash 0xb1", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("value change on aircraft B not delivered")
+	}
+
+	// The subscription is kept, but inactive, while the aircraft lacks the input event
+	server.LoadAircraft("C", simtest.InputEvent{Name: "COM1_STANDBY"})
+	waitFor(t, "unsubscribe on aircraft C", func() bool { return len(server.InputEventSubscriptions()) == 0 })
+	if got := iem.GetSubscriptions(); !reflect.DeepEqual(got, []string{"AUTOPILOT_AP_MASTER"}) {
+		t.Errorf("subscriptions %v, want [AUTOPILOT_AP_MASTER]", got)
+	}
+}
+
+func TestInputEventManagerSetChecksValueTypes(t *testing.T) {
+	server := simtest.NewServer()
+	server.LoadAircraft("A",
+		simtest.InputEvent{Name: "AUTOPILOT_AP_MASTER", Value: 0.0},
+		simtest.InputEvent{Name: "COM1_STANDBY", Value: "122.800"},
+	)
+	iem, _, ctx := newInputEvents(t, server)
+
+	tests := []struct {
+		name  string
+		event string
+		value interface{}
+		valid bool
+	}{
+		{"float64 into DOUBLE", "AUTOPILOT_AP_MASTER", 1.0, true},
+		{"string into STRING", "COM1_STANDBY", "121.500", true},
+		{"string into DOUBLE", "AUTOPILOT_AP_MASTER", "1", false},
+		{"float64 into STRING", "COM1_STANDBY", 121.5, false},
+		{"unsupported type", "AUTOPILOT_AP_MASTER", 1, false},
+		{"unknown input event", "LANDING_LIGHTS", 1.0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := server.InputEventValue(tt.event)
+			err := iem.Set(ctx, tt.event, tt.value)
+			after, _ := server.InputEventValue(tt.event)
+
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("Set: %v", err)
+				}
+				if after != tt.value {
+					t.Errorf("server value %v, want %v", after, tt.value)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Set succeeded")
+			}
+			if after != before {
+				t.Errorf("server value changed from %v to %v", before, after)
+			}
+		})
+	}
+}
+
+func TestInputEventManagerRoutesExceptionsToTheirCall(t *testing.T) {
+	server := simtest.NewServer()
+	server.LoadAircraft("A", simtest.InputEvent{Name: "AUTOPILOT_AP_MASTER"}, simtest.InputEvent{Name: "COM1_STANDBY", Value: "122.800"})
+	iem, simClient, ctx := newInputEvents(t, server)
+	if _, err := iem.GetInputEvents(ctx); err != nil {
+		t.Fatalf("GetInputEvents: %v", err)
+	}
+	if err := iem.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// The aircraft loses its input events behind the manager's back, every call now fails
+	server.SetInputEvents()
+
+	// Get and Params fail with the exception of their own call
+	_, err := iem.Get(ctx, "AUTOPILOT_AP_MASTER")
+	var exception *client.ExceptionError
+	if !errors.As(err, &exception) || exception.Packet == nil || exception.Packet.Operation != "GetInputEvent" {
+		t.Errorf("Get error %v, want the GetInputEvent exception", err)
+	}
+	_, err = iem.Params(ctx, "COM1_STANDBY")
+	if !errors.As(err, &exception) || exception.Packet == nil || exception.Packet.Operation != "EnumerateInputEventParams" {
+		t.Errorf("Params error %v, want the EnumerateInputEventParams exception", err)
+	}
+	select {
+	case err := <-iem.GetErrors():
+		t.Errorf("awaited exception also reported: %v", err)
+	case err := <-simClient.Dispatcher().GetErrors():
+		t.Errorf("awaited exception also reported: %v", err)
+	default:
+	}
+
+	// Writes of the manager are reported on its error channel
+	if err := iem.Set(ctx, "AUTOPILOT_AP_MASTER", 1.0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	exception = exceptionFor(t, iem.GetErrors(), server.LastSendID())
+	if exception.Packet == nil || exception.Packet.Operation != "SetInputEvent" {
+		t.Errorf("exception matched with %+v", exception.Packet)
+	}
+
+	// A write of the same input event by someone else is not claimed by the manager
+	if err := simClient.SetInputEvent(simtest.InputEventHash("AUTOPILOT_AP_MASTER"), 1.0); err != nil {
+		t.Fatalf("SetInputEvent: %v", err)
+	}
+	exceptionFor(t, simClient.Dispatcher().GetErrors(), server.LastSendID())
+}
+
+func TestInputEventManagerClose(t *testing.T) {
+	server := simtest.NewServer()
+	server.LoadAircraft("A", simtest.InputEvent{Name: "AUTOPILOT_AP_MASTER"})
+	iem, simClient, ctx := newInputEvents(t, server)
+	simClient.Dispatcher().Start()
+	defer simClient.Dispatcher().Stop()
+
+	if err := iem.Subscribe(ctx, "AUTOPILOT_AP_MASTER", func(client.InputEventValue) {}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err := iem.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if err := iem.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if iem.IsRunning() {
+		t.Error("manager still running after Close")
+	}
+	if subscriptions := server.InputEventSubscriptions(); len(subscriptions) != 0 {
+		t.Errorf("input event subscriptions %#x left after Close", subscriptions)
+	}
+	if subscriptions := server.Subscriptions(); len(subscriptions) != 0 {
+		t.Errorf("system event subscriptions %v left after Close", subscriptions)
+	}
+	if err := iem.Start(); err == nil {
+		t.Error("Start after Close succeeded")
+	}
+	if err := iem.Subscribe(ctx, "AUTOPILOT_AP_MASTER", func(client.InputEventValue) {}); err == nil {
+		t.Error("Subscribe after Close succeeded")
+	}
+	if err := iem.Set(ctx, "AUTOPILOT_AP_MASTER", 1.0); err == nil {
+		t.Error("Set after Close succeeded")
+	}
+	if err := iem.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// A reconnect must not replay the closed manager
+	supervisor := client.NewSupervisor(simClient, client.ReconnectConfig{InitialDelay: time.Millisecond})
+	if err := supervisor.Start(); err != nil {
+		t.Fatalf("supervisor Start: %v", err)
+	}
+	defer supervisor.Stop()
+
+	server.Quit()
+	waitFor(t, "reconnect", func() bool {
+		reconnects, _ := supervisor.GetStats()
+		return reconnects == 1
+	})
+	if subscriptions := server.InputEventSubscriptions(); len(subscriptions) != 0 {
+		t.Errorf("closed manager re-subscribed %#x after reconnect", subscriptions)
+	}
+}
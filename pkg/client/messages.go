@@ -0,0 +1,905 @@
+package client
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Message is a decoded SimConnect message returned by DecodeMessage.
+// Type-switch on the concrete *SIMCONNECT_RECV_* type to access its fields.
+type Message interface {
+	Header() SIMCONNECT_RECV
+}
+
+// Header returns the SIMCONNECT_RECV header shared by all messages
+func (r SIMCONNECT_RECV) Header() SIMCONNECT_RECV {
+	return r
+}
+
+// SIMCONNECT_RECV_EXCEPTION structure for errors reported by the server
+type SIMCONNECT_RECV_EXCEPTION struct {
+	SIMCONNECT_RECV                      // Inherited base structure
+	Exception       SIMCONNECT_EXCEPTION // Exception code
+	SendID          uint32               // Packet ID of the call that caused the exception
+	Index           uint32               // Index of the offending parameter, if known
+}
+
+// SIMCONNECT_RECV_OPEN structure sent once the connection is established
+type SIMCONNECT_RECV_OPEN struct {
+	SIMCONNECT_RECV                   // Inherited base structure
+	ApplicationName         string    // Name of the simulator
+	ApplicationVersionMajor uint32    // Simulator version
+	ApplicationVersionMinor uint32    // Simulator version
+	ApplicationBuildMajor   uint32    // Simulator build
+	ApplicationBuildMinor   uint32    // Simulator build
+	SimConnectVersionMajor  uint32    // SimConnect version
+	SimConnectVersionMinor  uint32    // SimConnect version
+	SimConnectBuildMajor    uint32    // SimConnect build
+	SimConnectBuildMinor    uint32    // SimConnect build
+	Reserved                [2]uint32 // Reserved
+}
+
+// SIMCONNECT_RECV_QUIT structure sent when the simulator shuts down
+type SIMCONNECT_RECV_QUIT struct {
+	SIMCONNECT_RECV // Inherited base structure
+}
+
+// SIMCONNECT_RECV_SIMOBJECT_DATA_BYTYPE structure answering RequestDataOnSimObjectType
+type SIMCONNECT_RECV_SIMOBJECT_DATA_BYTYPE struct {
+	SIMCONNECT_RECV_SIMOBJECT_DATA // Same layout as SIMOBJECT_DATA
+}
+
+// SIMCONNECT_RECV_CLIENT_DATA structure answering RequestClientData
+type SIMCONNECT_RECV_CLIENT_DATA struct {
+	SIMCONNECT_RECV_SIMOBJECT_DATA // Same layout as SIMOBJECT_DATA, DwObjectID is unused
+}
+
+// SIMCONNECT_RECV_WEATHER_OBSERVATION structure carrying a METAR string (FSX only)
+type SIMCONNECT_RECV_WEATHER_OBSERVATION struct {
+	SIMCONNECT_RECV        // Inherited base structure
+	RequestID       uint32 // Client defined request ID
+	Metar           string // METAR observation
+}
+
+// SIMCONNECT_RECV_CLOUD_STATE structure carrying a cloud density grid (FSX only)
+type SIMCONNECT_RECV_CLOUD_STATE struct {
+	SIMCONNECT_RECV        // Inherited base structure
+	RequestID       uint32 // Client defined request ID
+	ArraySize       uint32 // Number of bytes in Data
+	Data            []byte // Cloud density values
+}
+
+// SIMCONNECT_RECV_ASSIGNED_OBJECT_ID structure answering AI object creation
+type SIMCONNECT_RECV_ASSIGNED_OBJECT_ID struct {
+	SIMCONNECT_RECV        // Inherited base structure
+	RequestID       uint32 // Client defined request ID
+	ObjectID        uint32 // Assigned object ID
+}
+
+// SIMCONNECT_RECV_RESERVED_KEY structure answering RequestReservedKey
+type SIMCONNECT_RECV_RESERVED_KEY struct {
+	SIMCONNECT_RECV        // Inherited base structure
+	ChoiceReserved  string // Key choice that was reserved
+	ReservedKey     string // Key that was reserved
+}
+
+// SIMCONNECT_RECV_CUSTOM_ACTION structure for legacy mission actions
+type SIMCONNECT_RECV_CUSTOM_ACTION struct {
+	SIMCONNECT_RECV_EVENT          // Event base structure
+	InstanceID            [16]byte // GUID of the action instance
+	WaitForCompletion     uint32   // Whether the mission waits for completion
+	Payload               string   // Action payload
+}
+
+// SIMCONNECT_RECV_EVENT_WEATHER_MODE structure, Data holds the weather mode (FSX only)
+type SIMCONNECT_RECV_EVENT_WEATHER_MODE struct {
+	SIMCONNECT_RECV_EVENT // Event base structure
+}
+
+// SIMCONNECT_RECV_EVENT_MULTIPLAYER structure for multiplayer session events
+type SIMCONNECT_RECV_EVENT_MULTIPLAYER struct {
+	SIMCONNECT_RECV_EVENT // Event base structure
+}
+
+// SIMCONNECT_DATA_RACE_RESULT structure describing one racer
+type SIMCONNECT_DATA_RACE_RESULT struct {
+	NumberOfRacers uint32   // Number of racers
+	MissionGUID    [16]byte // Mission GUID
+	PlayerName     string   // Racer name
+	SessionType    string   // Session type
+	Aircraft       string   // Aircraft title
+	PlayerRole     string   // Player role
+	TotalTime      float64  // Total time in seconds
+	PenaltyTime    float64  // Penalty time in seconds
+	IsDisqualified uint32   // Non-zero if disqualified
+}
+
+// SIMCONNECT_RECV_EVENT_RACE_END structure sent when a racer finishes
+type SIMCONNECT_RECV_EVENT_RACE_END struct {
+	SIMCONNECT_RECV_EVENT                             // Event base structure
+	RacerNumber           uint32                      // Index of the racer
+	RacerData             SIMCONNECT_DATA_RACE_RESULT // Race result
+}
+
+// SIMCONNECT_RECV_EVENT_RACE_LAP structure sent when a racer completes a lap
+type SIMCONNECT_RECV_EVENT_RACE_LAP struct {
+	SIMCONNECT_RECV_EVENT                             // Event base structure
+	LapIndex              uint32                      // Index of the lap
+	RacerData             SIMCONNECT_DATA_RACE_RESULT // Race result
+}
+
+// SIMCONNECT_RECV_EVENT_EX1 structure for events carrying up to five parameters
+type SIMCONNECT_RECV_EVENT_EX1 struct {
+	SIMCONNECT_RECV           // Inherited base structure
+	GroupID         uint32    // Notification group ID
+	EventID         uint32    // Client event ID
+	Data            [5]uint32 // Event parameters
+}
+
+// SIMCONNECT_RECV_LIST_TEMPLATE header shared by all list messages
+type SIMCONNECT_RECV_LIST_TEMPLATE struct {
+	SIMCONNECT_RECV        // Inherited base structure
+	RequestID       uint32 // Client defined request ID
+	ArraySize       uint32 // Number of entries in this message
+	EntryNumber     uint32 // Index of this message in the answer
+	OutOf           uint32 // Number of messages in the answer
+}
+
+// SIMCONNECT_DATA_FACILITY_AIRPORT structure describing an airport
+type SIMCONNECT_DATA_FACILITY_AIRPORT struct {
+	Ident     string  // ICAO identifier
+	Region    string  // Region code
+	Latitude  float64 // Degrees
+	Longitude float64 // Degrees
+	Altitude  float64 // Meters
+}
+
+// SIMCONNECT_DATA_FACILITY_WAYPOINT structure describing a waypoint
+type SIMCONNECT_DATA_FACILITY_WAYPOINT struct {
+	SIMCONNECT_DATA_FACILITY_AIRPORT         // Position and identifier
+	MagVar                           float32 // Magnetic variation in degrees
+}
+
+// SIMCONNECT_DATA_FACILITY_NDB structure describing an NDB station
+type SIMCONNECT_DATA_FACILITY_NDB struct {
+	SIMCONNECT_DATA_FACILITY_WAYPOINT        // Waypoint data
+	Frequency                         uint32 // Frequency in Hz
+}
+
+// SIMCONNECT_DATA_FACILITY_VOR structure describing a VOR station
+type SIMCONNECT_DATA_FACILITY_VOR struct {
+	SIMCONNECT_DATA_FACILITY_NDB         // NDB data
+	Flags                        uint32  // SIMCONNECT_RECV_ID_VOR_LIST_HAS_* flags
+	Localizer                    float32 // Localizer heading in degrees
+	GlideLat                     float64 // Glide slope latitude
+	GlideLon                     float64 // Glide slope longitude
+	GlideAlt                     float64 // Glide slope altitude
+	GlideSlopeAngle              float32 // Glide slope angle in degrees
+}
+
+// SIMCONNECT_RECV_AIRPORT_LIST structure answering facility requests for airports
+type SIMCONNECT_RECV_AIRPORT_LIST struct {
+	SIMCONNECT_RECV_LIST_TEMPLATE                                    // List header
+	List                          []SIMCONNECT_DATA_FACILITY_AIRPORT // Airports
+}
+
+// SIMCONNECT_RECV_WAYPOINT_LIST structure answering facility requests for waypoints
+type SIMCONNECT_RECV_WAYPOINT_LIST struct {
+	SIMCONNECT_RECV_LIST_TEMPLATE                                     // List header
+	List                          []SIMCONNECT_DATA_FACILITY_WAYPOINT // Waypoints
+}
+
+// SIMCONNECT_RECV_NDB_LIST structure answering facility requests for NDB stations
+type SIMCONNECT_RECV_NDB_LIST struct {
+	SIMCONNECT_RECV_LIST_TEMPLATE                                // List header
+	List                          []SIMCONNECT_DATA_FACILITY_NDB // NDB stations
+}
+
+// SIMCONNECT_RECV_VOR_LIST structure answering facility requests for VOR stations
+type SIMCONNECT_RECV_VOR_LIST struct {
+	SIMCONNECT_RECV_LIST_TEMPLATE                                // List header
+	List                          []SIMCONNECT_DATA_FACILITY_VOR // VOR stations
+}
+
+// SIMCONNECT_RECV_FACILITY_DATA structure answering RequestFacilityData
+type SIMCONNECT_RECV_FACILITY_DATA struct {
+	SIMCONNECT_RECV              // Inherited base structure
+	UserRequestID         uint32 // Client defined request ID
+	UniqueRequestID       uint32 // Unique ID of this facility data block
+	ParentUniqueRequestID uint32 // Unique ID of the parent block
+	Type                  uint32 // SIMCONNECT_FACILITY_DATA_TYPE
+	IsListItem            uint32 // Non-zero if the block is part of a list
+	ItemIndex             uint32 // Index in the list
+	ListSize              uint32 // Size of the list
+	Data                  []byte // Facility data laid out as the facility definition
+}
+
+// SIMCONNECT_RECV_FACILITY_DATA_END structure ending a facility data answer
+type SIMCONNECT_RECV_FACILITY_DATA_END struct {
+	SIMCONNECT_RECV        // Inherited base structure
+	RequestID       uint32 // Client defined request ID
+}
+
+// SIMCONNECT_ICAO structure identifying a facility
+type SIMCONNECT_ICAO struct {
+	Type    byte   // Facility type character
+	Ident   string // Identifier
+	Region  string // Region code
+	Airport string // Airport the facility belongs to
+}
+
+// SIMCONNECT_DATA_LATLONALT structure for a geographic position
+type SIMCONNECT_DATA_LATLONALT struct {
+	Latitude  float64 // Degrees
+	Longitude float64 // Degrees
+	Altitude  float64 // Meters
+}
+
+// SIMCONNECT_DATA_XYZ structure for a cartesian vector
+type SIMCONNECT_DATA_XYZ struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// SIMCONNECT_DATA_INITPOSITION structure for the initial position of an object
+type SIMCONNECT_DATA_INITPOSITION struct {
+	Latitude  float64 // Degrees
+	Longitude float64 // Degrees
+	Altitude  float64 // Feet
+	Pitch     float64 // Degrees
+	Bank      float64 // Degrees
+	Heading   float64 // Degrees
+	OnGround  uint32  // 1 to place the object on the ground
+	Airspeed  uint32  // Knots, or INITPOSITION_AIRSPEED_CRUISE (-1) / INITPOSITION_AIRSPEED_KEEP (-2)
+}
+
+// SIMCONNECT_DATA_MARKERSTATE structure for the state of a marker (e.g. "Smoke")
+type SIMCONNECT_DATA_MARKERSTATE struct {
+	MarkerName  [64]byte // NUL-terminated marker name
+	MarkerState uint32   // 1 when on
+}
+
+// SIMCONNECT_DATA_WAYPOINT structure for a waypoint of an AI object
+type SIMCONNECT_DATA_WAYPOINT struct {
+	Latitude        float64 // Degrees
+	Longitude       float64 // Degrees
+	Altitude        float64 // Feet
+	Flags           uint32  // SIMCONNECT_WAYPOINT_* flags
+	KtsSpeed        float64 // Knots
+	PercentThrottle float64 // Percent
+}
+
+// SIMCONNECT_DATA_PBH structure for pitch, bank and heading
+type SIMCONNECT_DATA_PBH struct {
+	Pitch   float32
+	Bank    float32
+	Heading float32
+}
+
+// SIMCONNECT_FACILITY_MINIMAL structure describing a facility by ICAO and position
+type SIMCONNECT_FACILITY_MINIMAL struct {
+	ICAO SIMCONNECT_ICAO           // Facility identifier
+	LLA  SIMCONNECT_DATA_LATLONALT // Facility position
+}
+
+// SIMCONNECT_RECV_FACILITY_MINIMAL_LIST structure answering RequestAllFacilities
+type SIMCONNECT_RECV_FACILITY_MINIMAL_LIST struct {
+	SIMCONNECT_RECV_LIST_TEMPLATE                               // List header
+	List                          []SIMCONNECT_FACILITY_MINIMAL // Facilities
+}
+
+// SIMCONNECT_JETWAY_DATA structure describing a jetway
+type SIMCONNECT_JETWAY_DATA struct {
+	AirportIcao         string                    // Airport ICAO
+	ParkingIndex        int32                     // Parking spot index
+	LLA                 SIMCONNECT_DATA_LATLONALT // Jetway position
+	PBH                 SIMCONNECT_DATA_PBH       // Jetway orientation
+	Status              int32                     // SIMCONNECT_JETWAY_STATUS
+	Door                int32                     // Door index the jetway is attached to
+	ExitDoorRelativePos SIMCONNECT_DATA_XYZ       // Exit door position relative to the aircraft
+	MainHandlePos       SIMCONNECT_DATA_XYZ       // Main handle position
+	SecondaryHandle     SIMCONNECT_DATA_XYZ       // Secondary handle position
+	WheelGroundLock     SIMCONNECT_DATA_XYZ       // Wheel ground lock position
+	JetwayObjectID      uint32                    // Object ID of the jetway
+	AttachedObjectID    uint32                    // Object ID of the attached aircraft
+}
+
+// SIMCONNECT_RECV_JETWAY_DATA structure answering RequestJetwayData
+type SIMCONNECT_RECV_JETWAY_DATA struct {
+	SIMCONNECT_RECV_LIST_TEMPLATE                          // List header
+	List                          []SIMCONNECT_JETWAY_DATA // Jetways
+}
+
+// SIMCONNECT_VERSION_BASE_TYPE structure for a four part version number
+type SIMCONNECT_VERSION_BASE_TYPE struct {
+	Major    uint16
+	Minor    uint16
+	Revision uint16
+	Build    uint16
+}
+
+// SIMCONNECT_CONTROLLER_ITEM structure describing an input device
+type SIMCONNECT_CONTROLLER_ITEM struct {
+	DeviceName      string                       // Device name
+	DeviceID        uint32                       // Device ID
+	ProductID       uint32                       // USB product ID
+	CompositeID     uint32                       // USB composite ID
+	HardwareVersion SIMCONNECT_VERSION_BASE_TYPE // Hardware version
+}
+
+// SIMCONNECT_RECV_CONTROLLERS_LIST structure answering EnumerateControllers
+type SIMCONNECT_RECV_CONTROLLERS_LIST struct {
+	SIMCONNECT_RECV_LIST_TEMPLATE                              // List header
+	List                          []SIMCONNECT_CONTROLLER_ITEM // Controllers
+}
+
+// SIMCONNECT_RECV_ACTION_CALLBACK structure answering ExecuteAction
+type SIMCONNECT_RECV_ACTION_CALLBACK struct {
+	SIMCONNECT_RECV        // Inherited base structure
+	ActionID        string // Executed action
+	RequestID       uint32 // Client defined request ID
+}
+
+// SIMCONNECT_INPUT_EVENT_DESCRIPTOR structure describing an input event of the current aircraft
+type SIMCONNECT_INPUT_EVENT_DESCRIPTOR struct {
+	Name string                      // Input event name
+	Hash uint64                      // Hash identifying the input event
+	Type SIMCONNECT_INPUT_EVENT_TYPE // Value type
+}
+
+// SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS structure answering EnumerateInputEvents
+type SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS struct {
+	SIMCONNECT_RECV_LIST_TEMPLATE                                     // List header
+	List                          []SIMCONNECT_INPUT_EVENT_DESCRIPTOR // Input events
+}
+
+// SIMCONNECT_RECV_GET_INPUT_EVENT structure answering GetInputEvent
+type SIMCONNECT_RECV_GET_INPUT_EVENT struct {
+	SIMCONNECT_RECV                             // Inherited base structure
+	RequestID       uint32                      // Client defined request ID
+	Type            SIMCONNECT_INPUT_EVENT_TYPE // Value type
+	Value           interface{}                 // float64 or string depending on Type
+}
+
+// SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT structure notifying an input event value change
+type SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT struct {
+	SIMCONNECT_RECV                             // Inherited base structure
+	Hash            uint64                      // Hash of the input event
+	Type            SIMCONNECT_INPUT_EVENT_TYPE // Value type
+	Value           interface{}                 // float64 or string depending on Type
+}
+
+// SIMCONNECT_RECV_ENUMERATE_INPUT_EVENT_PARAMS structure answering EnumerateInputEventParams
+type SIMCONNECT_RECV_ENUMERATE_INPUT_EVENT_PARAMS struct {
+	SIMCONNECT_RECV        // Inherited base structure
+	Hash            uint64 // Hash of the input event
+	Value           string // Parameter types separated by ';'
+}
+
+// SIMCONNECT_ENUMERATE_SIMOBJECT_LIVERY structure describing an aircraft livery
+type SIMCONNECT_ENUMERATE_SIMOBJECT_LIVERY struct {
+	AircraftTitle string // Aircraft title
+	LiveryName    string // Livery name
+}
+
+// SIMCONNECT_RECV_ENUMERATE_SIMOBJECT_AND_LIVERY_LIST structure answering EnumerateSimObjectsAndLiveries
+type SIMCONNECT_RECV_ENUMERATE_SIMOBJECT_AND_LIVERY_LIST struct {
+	SIMCONNECT_RECV_LIST_TEMPLATE                                         // List header
+	List                          []SIMCONNECT_ENUMERATE_SIMOBJECT_LIVERY // Liveries
+}
+
+// SIMCONNECT_RECV_FLOW_EVENT structure for flight flow notifications
+type SIMCONNECT_RECV_FLOW_EVENT struct {
+	SIMCONNECT_RECV        // Inherited base structure
+	FlowEvent       uint32 // SIMCONNECT_FLOW_EVENT
+	FltPath         string // Path of the related flight file
+}
+
+// UnknownMessage holds a message with a SIMCONNECT_RECV_ID this package does not know
+type UnknownMessage struct {
+	SIMCONNECT_RECV        // Inherited base structure
+	Data            []byte // Message body after the header
+}
+
+// Fixed string field sizes used by the messages
+const (
+	recvStringShort      = 30  // RESERVED_KEY choice
+	recvStringKey        = 50  // RESERVED_KEY key
+	recvStringName       = 64  // Input event name
+	recvStringDeviceName = 256 // Controller and livery names
+)
+
+// Entry sizes of the list messages
+const (
+	facilityAirportSize  = 6 + 3 + 3*8
+	facilityWaypointSize = facilityAirportSize + 4
+	facilityNDBSize      = facilityWaypointSize + 4
+	facilityVORSize      = facilityNDBSize + 4 + 4 + 3*8 + 4
+	facilityMinimalSize  = 1 + 9 + 3 + 5 + 3*8
+	jetwayDataSize       = 8 + 4 + 3*8 + 3*4 + 4 + 4 + 4*3*8 + 4 + 4
+	controllerItemSize   = recvStringDeviceName + 3*4 + 4*2
+	inputEventDescSize   = recvStringName + 8 + 4
+	liverySize           = 2 * recvStringDeviceName
+)
+
+// DecodeMessage decodes a raw message as returned by GetRawDispatch into its concrete type.
+// Messages with an unknown SIMCONNECT_RECV_ID are returned as *UnknownMessage.
+// Malformed messages are rejected with a *MessageError.
+func DecodeMessage(data []byte) (Message, error) {
+	header, err := readHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	r := &messageReader{data: data, offset: recvHeaderSize, recvID: header.DwID}
+
+	var message Message
+	switch header.DwID {
+	case SIMCONNECT_RECV_ID_NULL:
+		message = &header
+	case SIMCONNECT_RECV_ID_EXCEPTION:
+		message = &SIMCONNECT_RECV_EXCEPTION{
+			SIMCONNECT_RECV: header,
+			Exception:       SIMCONNECT_EXCEPTION(r.uint32()),
+			SendID:          r.uint32(),
+			Index:           r.uint32(),
+		}
+	case SIMCONNECT_RECV_ID_OPEN:
+		open := &SIMCONNECT_RECV_OPEN{SIMCONNECT_RECV: header, ApplicationName: r.string(256)}
+		open.ApplicationVersionMajor, open.ApplicationVersionMinor = r.uint32(), r.uint32()
+		open.ApplicationBuildMajor, open.ApplicationBuildMinor = r.uint32(), r.uint32()
+		open.SimConnectVersionMajor, open.SimConnectVersionMinor = r.uint32(), r.uint32()
+		open.SimConnectBuildMajor, open.SimConnectBuildMinor = r.uint32(), r.uint32()
+		open.Reserved = [2]uint32{r.uint32(), r.uint32()}
+		message = open
+	case SIMCONNECT_RECV_ID_QUIT:
+		message = &SIMCONNECT_RECV_QUIT{SIMCONNECT_RECV: header}
+	case SIMCONNECT_RECV_ID_EVENT:
+		event := r.event(header)
+		message = &event
+	case SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE:
+		event := r.event(header)
+		message = &SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE{
+			SIMCONNECT_RECV: header,
+			GroupID:         event.GroupID,
+			EventID:         event.EventID,
+			Data:            event.Data,
+			ObjectID:        event.Data,
+			ObjType:         r.uint32(),
+		}
+	case SIMCONNECT_RECV_ID_EVENT_FILENAME:
+		event := r.event(header)
+		filename := &SIMCONNECT_RECV_EVENT_FILENAME{
+			SIMCONNECT_RECV: header,
+			GroupID:         event.GroupID,
+			EventID:         event.EventID,
+			Data:            event.Data,
+		}
+		copy(filename.SzFileName[:], r.bytes(MAX_PATH))
+		filename.DwFlags = r.uint32()
+		message = filename
+	case SIMCONNECT_RECV_ID_EVENT_FRAME:
+		event := r.event(header)
+		message = &SIMCONNECT_RECV_EVENT_FRAME{
+			SIMCONNECT_RECV: header,
+			GroupID:         event.GroupID,
+			EventID:         event.EventID,
+			Data:            event.Data,
+			FrameRate:       r.float32(),
+			SimSpeed:        r.float32(),
+		}
+	case SIMCONNECT_RECV_ID_SIMOBJECT_DATA:
+		message = r.simObjectData(header)
+	case SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE:
+		message = &SIMCONNECT_RECV_SIMOBJECT_DATA_BYTYPE{*r.simObjectData(header)}
+	case SIMCONNECT_RECV_ID_WEATHER_OBSERVATION:
+		message = &SIMCONNECT_RECV_WEATHER_OBSERVATION{
+			SIMCONNECT_RECV: header,
+			RequestID:       r.uint32(),
+			Metar:           r.restString(),
+		}
+	case SIMCONNECT_RECV_ID_CLOUD_STATE:
+		cloud := &SIMCONNECT_RECV_CLOUD_STATE{SIMCONNECT_RECV: header, RequestID: r.uint32(), ArraySize: r.uint32()}
+		if r.err == nil && uint64(cloud.ArraySize) > uint64(len(r.data)-r.offset) {
+			r.err = newMessageError(r.recvID, ErrMessageInvalid, "array of %d bytes exceeds message size %d", cloud.ArraySize, len(r.data))
+		}
+		if r.err == nil {
+			cloud.Data = append([]byte(nil), r.bytes(int(cloud.ArraySize))...)
+		}
+		message = cloud
+	case SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID:
+		message = &SIMCONNECT_RECV_ASSIGNED_OBJECT_ID{
+			SIMCONNECT_RECV: header,
+			RequestID:       r.uint32(),
+			ObjectID:        r.uint32(),
+		}
+	case SIMCONNECT_RECV_ID_RESERVED_KEY:
+		message = &SIMCONNECT_RECV_RESERVED_KEY{
+			SIMCONNECT_RECV: header,
+			ChoiceReserved:  r.string(recvStringShort),
+			ReservedKey:     r.string(recvStringKey),
+		}
+	case SIMCONNECT_RECV_ID_CUSTOM_ACTION:
+		action := &SIMCONNECT_RECV_CUSTOM_ACTION{SIMCONNECT_RECV_EVENT: r.event(header)}
+		copy(action.InstanceID[:], r.bytes(16))
+		action.WaitForCompletion = r.uint32()
+		action.Payload = r.restString()
+		message = action
+	case SIMCONNECT_RECV_ID_SYSTEM_STATE:
+		state := &SIMCONNECT_RECV_SYSTEM_STATE{
+			SIMCONNECT_RECV: header,
+			DwRequestID:     r.uint32(),
+			DwInteger:       r.uint32(),
+			FFloat:          r.float32(),
+		}
+		copy(state.SzString[:], r.bytes(MAX_PATH))
+		message = state
+	case SIMCONNECT_RECV_ID_CLIENT_DATA:
+		message = &SIMCONNECT_RECV_CLIENT_DATA{*r.simObjectData(header)}
+	case SIMCONNECT_RECV_ID_EVENT_WEATHER_MODE:
+		message = &SIMCONNECT_RECV_EVENT_WEATHER_MODE{r.event(header)}
+	case SIMCONNECT_RECV_ID_AIRPORT_LIST:
+		list := &SIMCONNECT_RECV_AIRPORT_LIST{SIMCONNECT_RECV_LIST_TEMPLATE: r.list(header, facilityAirportSize)}
+		for i := uint32(0); i < list.ArraySize && r.err == nil; i++ {
+			list.List = append(list.List, r.facilityAirport())
+		}
+		message = list
+	case SIMCONNECT_RECV_ID_VOR_LIST:
+		list := &SIMCONNECT_RECV_VOR_LIST{SIMCONNECT_RECV_LIST_TEMPLATE: r.list(header, facilityVORSize)}
+		for i := uint32(0); i < list.ArraySize && r.err == nil; i++ {
+			list.List = append(list.List, SIMCONNECT_DATA_FACILITY_VOR{
+				SIMCONNECT_DATA_FACILITY_NDB: r.facilityNDB(),
+				Flags:                        r.uint32(),
+				Localizer:                    r.float32(),
+				GlideLat:                     r.float64(),
+				GlideLon:                     r.float64(),
+				GlideAlt:                     r.float64(),
+				GlideSlopeAngle:              r.float32(),
+			})
+		}
+		message = list
+	case SIMCONNECT_RECV_ID_NDB_LIST:
+		list := &SIMCONNECT_RECV_NDB_LIST{SIMCONNECT_RECV_LIST_TEMPLATE: r.list(header, facilityNDBSize)}
+		for i := uint32(0); i < list.ArraySize && r.err == nil; i++ {
+			list.List = append(list.List, r.facilityNDB())
+		}
+		message = list
+	case SIMCONNECT_RECV_ID_WAYPOINT_LIST:
+		list := &SIMCONNECT_RECV_WAYPOINT_LIST{SIMCONNECT_RECV_LIST_TEMPLATE: r.list(header, facilityWaypointSize)}
+		for i := uint32(0); i < list.ArraySize && r.err == nil; i++ {
+			list.List = append(list.List, r.facilityWaypoint())
+		}
+		message = list
+	case SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED,
+		SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED,
+		SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED:
+		message = &SIMCONNECT_RECV_EVENT_MULTIPLAYER{r.event(header)}
+	case SIMCONNECT_RECV_ID_EVENT_RACE_END:
+		message = &SIMCONNECT_RECV_EVENT_RACE_END{
+			SIMCONNECT_RECV_EVENT: r.event(header),
+			RacerNumber:           r.uint32(),
+			RacerData:             r.raceResult(),
+		}
+	case SIMCONNECT_RECV_ID_EVENT_RACE_LAP:
+		message = &SIMCONNECT_RECV_EVENT_RACE_LAP{
+			SIMCONNECT_RECV_EVENT: r.event(header),
+			LapIndex:              r.uint32(),
+			RacerData:             r.raceResult(),
+		}
+	case SIMCONNECT_RECV_ID_EVENT_EX1:
+		message = &SIMCONNECT_RECV_EVENT_EX1{
+			SIMCONNECT_RECV: header,
+			GroupID:         r.uint32(),
+			EventID:         r.uint32(),
+			Data:            [5]uint32{r.uint32(), r.uint32(), r.uint32(), r.uint32(), r.uint32()},
+		}
+	case SIMCONNECT_RECV_ID_FACILITY_DATA:
+		facility := &SIMCONNECT_RECV_FACILITY_DATA{
+			SIMCONNECT_RECV:       header,
+			UserRequestID:         r.uint32(),
+			UniqueRequestID:       r.uint32(),
+			ParentUniqueRequestID: r.uint32(),
+			Type:                  r.uint32(),
+			IsListItem:            r.uint32(),
+			ItemIndex:             r.uint32(),
+			ListSize:              r.uint32(),
+		}
+		facility.Data = append([]byte(nil), r.remaining()...)
+		message = facility
+	case SIMCONNECT_RECV_ID_FACILITY_DATA_END:
+		message = &SIMCONNECT_RECV_FACILITY_DATA_END{SIMCONNECT_RECV: header, RequestID: r.uint32()}
+	case SIMCONNECT_RECV_ID_FACILITY_MINIMAL_LIST:
+		list := &SIMCONNECT_RECV_FACILITY_MINIMAL_LIST{SIMCONNECT_RECV_LIST_TEMPLATE: r.list(header, facilityMinimalSize)}
+		for i := uint32(0); i < list.ArraySize && r.err == nil; i++ {
+			list.List = append(list.List, SIMCONNECT_FACILITY_MINIMAL{ICAO: r.icao(), LLA: r.latLonAlt()})
+		}
+		message = list
+	case SIMCONNECT_RECV_ID_JETWAY_DATA:
+		list := &SIMCONNECT_RECV_JETWAY_DATA{SIMCONNECT_RECV_LIST_TEMPLATE: r.list(header, jetwayDataSize)}
+		for i := uint32(0); i < list.ArraySize && r.err == nil; i++ {
+			list.List = append(list.List, SIMCONNECT_JETWAY_DATA{
+				AirportIcao:         r.string(8),
+				ParkingIndex:        int32(r.uint32()),
+				LLA:                 r.latLonAlt(),
+				PBH:                 SIMCONNECT_DATA_PBH{Pitch: r.float32(), Bank: r.float32(), Heading: r.float32()},
+				Status:              int32(r.uint32()),
+				Door:                int32(r.uint32()),
+				ExitDoorRelativePos: r.xyz(),
+				MainHandlePos:       r.xyz(),
+				SecondaryHandle:     r.xyz(),
+				WheelGroundLock:     r.xyz(),
+				JetwayObjectID:      r.uint32(),
+				AttachedObjectID:    r.uint32(),
+			})
+		}
+		message = list
+	case SIMCONNECT_RECV_ID_CONTROLLERS_LIST:
+		list := &SIMCONNECT_RECV_CONTROLLERS_LIST{SIMCONNECT_RECV_LIST_TEMPLATE: r.list(header, controllerItemSize)}
+		for i := uint32(0); i < list.ArraySize && r.err == nil; i++ {
+			list.List = append(list.List, SIMCONNECT_CONTROLLER_ITEM{
+				DeviceName:  r.string(recvStringDeviceName),
+				DeviceID:    r.uint32(),
+				ProductID:   r.uint32(),
+				CompositeID: r.uint32(),
+				HardwareVersion: SIMCONNECT_VERSION_BASE_TYPE{
+					Major: r.uint16(), Minor: r.uint16(), Revision: r.uint16(), Build: r.uint16(),
+				},
+			})
+		}
+		message = list
+	case SIMCONNECT_RECV_ID_ACTION_CALLBACK:
+		message = &SIMCONNECT_RECV_ACTION_CALLBACK{
+			SIMCONNECT_RECV: header,
+			ActionID:        r.string(MAX_PATH),
+			RequestID:       r.uint32(),
+		}
+	case SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS:
+		list := &SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS{SIMCONNECT_RECV_LIST_TEMPLATE: r.list(header, inputEventDescSize)}
+		for i := uint32(0); i < list.ArraySize && r.err == nil; i++ {
+			list.List = append(list.List, SIMCONNECT_INPUT_EVENT_DESCRIPTOR{
+				Name: r.string(recvStringName),
+				Hash: r.uint64(),
+				Type: SIMCONNECT_INPUT_EVENT_TYPE(r.uint32()),
+			})
+		}
+		message = list
+	case SIMCONNECT_RECV_ID_GET_INPUT_EVENT:
+		event := &SIMCONNECT_RECV_GET_INPUT_EVENT{SIMCONNECT_RECV: header, RequestID: r.uint32()}
+		event.Type = SIMCONNECT_INPUT_EVENT_TYPE(r.uint32())
+		event.Value = r.inputEventValue(event.Type)
+		message = event
+	case SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT:
+		event := &SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT{SIMCONNECT_RECV: header, Hash: r.uint64()}
+		event.Type = SIMCONNECT_INPUT_EVENT_TYPE(r.uint32())
+		event.Value = r.inputEventValue(event.Type)
+		message = event
+	case SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS:
+		message = &SIMCONNECT_RECV_ENUMERATE_INPUT_EVENT_PARAMS{
+			SIMCONNECT_RECV: header,
+			Hash:            r.uint64(),
+			Value:           r.restString(),
+		}
+	case SIMCONNECT_RECV_ID_ENUMERATE_SIMOBJECT_AND_LIVERY_LIST:
+		list := &SIMCONNECT_RECV_ENUMERATE_SIMOBJECT_AND_LIVERY_LIST{SIMCONNECT_RECV_LIST_TEMPLATE: r.list(header, liverySize)}
+		for i := uint32(0); i < list.ArraySize && r.err == nil; i++ {
+			list.List = append(list.List, SIMCONNECT_ENUMERATE_SIMOBJECT_LIVERY{
+				AircraftTitle: r.string(recvStringDeviceName),
+				LiveryName:    r.string(recvStringDeviceName),
+			})
+		}
+		message = list
+	case SIMCONNECT_RECV_ID_FLOW_EVENT:
+		message = &SIMCONNECT_RECV_FLOW_EVENT{
+			SIMCONNECT_RECV: header,
+			FlowEvent:       r.uint32(),
+			FltPath:         r.string(MAX_PATH),
+		}
+	default:
+		message = &UnknownMessage{SIMCONNECT_RECV: header, Data: append([]byte(nil), r.remaining()...)}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return message, nil
+}
+
+// readHeader reads the SIMCONNECT_RECV header and validates DwSize against the buffer
+func readHeader(data []byte) (SIMCONNECT_RECV, error) {
+	if len(data) < recvHeaderSize {
+		return SIMCONNECT_RECV{}, newMessageError(0, ErrMessageTruncated, "need %d header bytes, have %d", recvHeaderSize, len(data))
+	}
+
+	header := SIMCONNECT_RECV{
+		DwSize:    binary.LittleEndian.Uint32(data[0:]),
+		DwVersion: binary.LittleEndian.Uint32(data[4:]),
+		DwID:      binary.LittleEndian.Uint32(data[8:]),
+	}
+
+	if header.DwSize < recvHeaderSize {
+		return header, newMessageError(header.DwID, ErrMessageInvalid, "DwSize %d is smaller than the header", header.DwSize)
+	}
+	if int64(header.DwSize) != int64(len(data)) {
+		return header, newMessageError(header.DwID, ErrMessageSizeMismatch, "DwSize %d, buffer has %d bytes", header.DwSize, len(data))
+	}
+	return header, nil
+}
+
+// decodeAs decodes a message and checks that it has the expected SIMCONNECT_RECV_ID
+func decodeAs(data []byte, recvIDs ...uint32) (Message, error) {
+	message, err := DecodeMessage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	id := message.Header().DwID
+	for _, recvID := range recvIDs {
+		if id == recvID {
+			return message, nil
+		}
+	}
+	return nil, newMessageError(id, ErrMessageType, "expected message 0x%08X", recvIDs[0])
+}
+
+// recvHeaderSize is the size of the SIMCONNECT_RECV header
+const recvHeaderSize = 12
+
+// messageReader reads little-endian fields from a message and remembers the first error
+type messageReader struct {
+	data   []byte
+	offset int
+	recvID uint32 // Message type for error reports
+	err    error
+}
+
+// bytes returns the next n bytes, or zeroes once the message is exhausted
+func (r *messageReader) bytes(n int) []byte {
+	if r.err != nil {
+		return make([]byte, n)
+	}
+	if n < 0 || len(r.data)-r.offset < n {
+		r.err = newMessageError(r.recvID, ErrMessageTruncated, "need %d bytes at offset %d, have %d", n, r.offset, len(r.data)-r.offset)
+		return make([]byte, n)
+	}
+	b := r.data[r.offset : r.offset+n]
+	r.offset += n
+	return b
+}
+
+// remaining returns the unread part of the message
+func (r *messageReader) remaining() []byte {
+	if r.err != nil {
+		return nil
+	}
+	b := r.data[r.offset:]
+	r.offset = len(r.data)
+	return b
+}
+
+// restString reads a NUL-terminated string filling the rest of the message
+func (r *messageReader) restString() string {
+	return cStringToGoString(r.remaining())
+}
+
+func (r *messageReader) uint16() uint16 {
+	return binary.LittleEndian.Uint16(r.bytes(2))
+}
+
+func (r *messageReader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.bytes(4))
+}
+
+func (r *messageReader) uint64() uint64 {
+	return binary.LittleEndian.Uint64(r.bytes(8))
+}
+
+func (r *messageReader) float32() float32 {
+	return math.Float32frombits(r.uint32())
+}
+
+func (r *messageReader) float64() float64 {
+	return math.Float64frombits(r.uint64())
+}
+
+// string reads a fixed-size NUL-terminated string field
+func (r *messageReader) string(size int) string {
+	return cStringToGoString(r.bytes(size))
+}
+
+func (r *messageReader) event(header SIMCONNECT_RECV) SIMCONNECT_RECV_EVENT {
+	return SIMCONNECT_RECV_EVENT{SIMCONNECT_RECV: header, GroupID: r.uint32(), EventID: r.uint32(), Data: r.uint32()}
+}
+
+func (r *messageReader) simObjectData(header SIMCONNECT_RECV) *SIMCONNECT_RECV_SIMOBJECT_DATA {
+	return &SIMCONNECT_RECV_SIMOBJECT_DATA{
+		SIMCONNECT_RECV:  header,
+		DwRequestID:      r.uint32(),
+		DwObjectID:       r.uint32(),
+		DwDefineID:       r.uint32(),
+		DwFlags:          r.uint32(),
+		DwentrynumberOut: r.uint32(),
+		DwoutofOut:       r.uint32(),
+		DwDefineCount:    r.uint32(),
+		Data:             r.remaining(),
+	}
+}
+
+// list reads a list header and checks that the message holds ArraySize entries of entrySize bytes
+func (r *messageReader) list(header SIMCONNECT_RECV, entrySize int) SIMCONNECT_RECV_LIST_TEMPLATE {
+	list := SIMCONNECT_RECV_LIST_TEMPLATE{
+		SIMCONNECT_RECV: header,
+		RequestID:       r.uint32(),
+		ArraySize:       r.uint32(),
+		EntryNumber:     r.uint32(),
+		OutOf:           r.uint32(),
+	}
+	if r.err != nil {
+		return list
+	}
+	if uint64(list.ArraySize)*uint64(entrySize) > uint64(len(r.data)-r.offset) {
+		r.err = newMessageError(r.recvID, ErrMessageInvalid, "%d entries of %d bytes exceed message size %d", list.ArraySize, entrySize, len(r.data))
+	} else if list.OutOf > 0 && list.EntryNumber >= list.OutOf {
+		r.err = newMessageError(r.recvID, ErrMessageInvalid, "entry %d out of %d", list.EntryNumber, list.OutOf)
+	}
+	return list
+}
+
+func (r *messageReader) facilityAirport() SIMCONNECT_DATA_FACILITY_AIRPORT {
+	return SIMCONNECT_DATA_FACILITY_AIRPORT{
+		Ident:     r.string(6),
+		Region:    r.string(3),
+		Latitude:  r.float64(),
+		Longitude: r.float64(),
+		Altitude:  r.float64(),
+	}
+}
+
+func (r *messageReader) facilityWaypoint() SIMCONNECT_DATA_FACILITY_WAYPOINT {
+	return SIMCONNECT_DATA_FACILITY_WAYPOINT{SIMCONNECT_DATA_FACILITY_AIRPORT: r.facilityAirport(), MagVar: r.float32()}
+}
+
+func (r *messageReader) facilityNDB() SIMCONNECT_DATA_FACILITY_NDB {
+	return SIMCONNECT_DATA_FACILITY_NDB{SIMCONNECT_DATA_FACILITY_WAYPOINT: r.facilityWaypoint(), Frequency: r.uint32()}
+}
+
+func (r *messageReader) icao() SIMCONNECT_ICAO {
+	return SIMCONNECT_ICAO{
+		Type:    r.bytes(1)[0],
+		Ident:   r.string(9),
+		Region:  r.string(3),
+		Airport: r.string(5),
+	}
+}
+
+func (r *messageReader) latLonAlt() SIMCONNECT_DATA_LATLONALT {
+	return SIMCONNECT_DATA_LATLONALT{Latitude: r.float64(), Longitude: r.float64(), Altitude: r.float64()}
+}
+
+func (r *messageReader) xyz() SIMCONNECT_DATA_XYZ {
+	return SIMCONNECT_DATA_XYZ{X: r.float64(), Y: r.float64(), Z: r.float64()}
+}
+
+func (r *messageReader) raceResult() SIMCONNECT_DATA_RACE_RESULT {
+	result := SIMCONNECT_DATA_RACE_RESULT{NumberOfRacers: r.uint32()}
+	copy(result.MissionGUID[:], r.bytes(16))
+	result.PlayerName = r.string(MAX_PATH)
+	result.SessionType = r.string(MAX_PATH)
+	result.Aircraft = r.string(MAX_PATH)
+	result.PlayerRole = r.string(MAX_PATH)
+	result.TotalTime = r.float64()
+	result.PenaltyTime = r.float64()
+	result.IsDisqualified = r.uint32()
+	return result
+}
+
+// inputEventValue reads the trailing value of input event messages
+func (r *messageReader) inputEventValue(valueType SIMCONNECT_INPUT_EVENT_TYPE) interface{} {
+	switch valueType {
+	case SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE:
+		return r.float64()
+	case SIMCONNECT_INPUT_EVENT_TYPE_STRING:
+		return r.restString()
+	default:
+		return append([]byte(nil), r.remaining()...)
+	}
+}
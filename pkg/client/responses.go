@@ -1,8 +1,10 @@
 package client
 
 import (
+	"context"
+	"encoding/binary"
 	"fmt"
-	"unsafe"
+	"math"
 )
 
 // SIMCONNECT_RECV_ID constants
@@ -29,6 +31,26 @@ const (
 	SIMCONNECT_RECV_ID_VOR_LIST               = 0x00000013
 	SIMCONNECT_RECV_ID_NDB_LIST               = 0x00000014
 	SIMCONNECT_RECV_ID_WAYPOINT_LIST          = 0x00000015
+
+	// Microsoft Flight Simulator 2020/2024
+	SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED    = 0x00000016
+	SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED    = 0x00000017
+	SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED     = 0x00000018
+	SIMCONNECT_RECV_ID_EVENT_RACE_END                      = 0x00000019
+	SIMCONNECT_RECV_ID_EVENT_RACE_LAP                      = 0x0000001A
+	SIMCONNECT_RECV_ID_EVENT_EX1                           = 0x0000001B
+	SIMCONNECT_RECV_ID_FACILITY_DATA                       = 0x0000001C
+	SIMCONNECT_RECV_ID_FACILITY_DATA_END                   = 0x0000001D
+	SIMCONNECT_RECV_ID_FACILITY_MINIMAL_LIST               = 0x0000001E
+	SIMCONNECT_RECV_ID_JETWAY_DATA                         = 0x0000001F
+	SIMCONNECT_RECV_ID_CONTROLLERS_LIST                    = 0x00000020
+	SIMCONNECT_RECV_ID_ACTION_CALLBACK                     = 0x00000021
+	SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS              = 0x00000022
+	SIMCONNECT_RECV_ID_GET_INPUT_EVENT                     = 0x00000023
+	SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT               = 0x00000024
+	SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS        = 0x00000025
+	SIMCONNECT_RECV_ID_ENUMERATE_SIMOBJECT_AND_LIVERY_LIST = 0x00000026
+	SIMCONNECT_RECV_ID_FLOW_EVENT                          = 0x00000027
 )
 
 // MAX_PATH constant from Windows
@@ -60,7 +82,7 @@ type SIMCONNECT_RECV_SIMOBJECT_DATA struct {
 	DwentrynumberOut uint32 // Entry number (reserved)
 	DwoutofOut       uint32 // Out of (reserved)
 	DwDefineCount    uint32 // Number of data definitions
-	// Data follows this structure - must be cast to appropriate type
+	Data             []byte // Data block following the header, laid out as the data definition
 }
 
 // SIMCONNECT_RECV_EVENT structure for system event notifications
@@ -78,6 +100,7 @@ type SIMCONNECT_RECV_EVENT_FILENAME struct {
 	EventID         uint32         // Event ID specified when subscribing
 	Data            uint32         // Event-specific data
 	SzFileName      [MAX_PATH]byte // Null-terminated filename string
+	DwFlags         uint32         // Reserved flags
 }
 
 // SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE structure for object add/remove events
@@ -86,85 +109,154 @@ type SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE struct {
 	GroupID         uint32 // Group ID (reserved for system events)
 	EventID         uint32 // Event ID specified when subscribing
 	Data            uint32 // Event-specific data
-	ObjectID        uint32 // Object ID that was added or removed
+	ObjectID        uint32 // Object ID that was added or removed (same as Data)
+	ObjType         uint32 // SIMCONNECT_SIMOBJECT_TYPE of the object
 }
 
 // SIMCONNECT_RECV_EVENT_FRAME structure for frame events (same as basic event but semantically different)
 type SIMCONNECT_RECV_EVENT_FRAME struct {
-	SIMCONNECT_RECV        // Inherited base structure
-	GroupID         uint32 // Group ID (reserved for system events)
-	EventID         uint32 // Event ID specified when subscribing
-	Data            uint32 // Frame number or timing data
+	SIMCONNECT_RECV         // Inherited base structure
+	GroupID         uint32  // Group ID (reserved for system events)
+	EventID         uint32  // Event ID specified when subscribing
+	Data            uint32  // Frame number or timing data
+	FrameRate       float32 // Visual frame rate in frames per second
+	SimSpeed        float32 // Simulation rate
 }
 
 // ParseSimObjectData parses a SIMCONNECT_RECV_SIMOBJECT_DATA message from raw bytes
+// SIMOBJECT_DATA_BYTYPE and CLIENT_DATA messages share the layout and are accepted too.
+// The returned data block is also available as the Data field of the header.
 func ParseSimObjectData(data []byte) (*SIMCONNECT_RECV_SIMOBJECT_DATA, []byte, error) {
-	if len(data) < int(unsafe.Sizeof(SIMCONNECT_RECV_SIMOBJECT_DATA{})) {
-		return nil, nil, fmt.Errorf("data too short for SIMCONNECT_RECV_SIMOBJECT_DATA")
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_SIMOBJECT_DATA, SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE, SIMCONNECT_RECV_ID_CLIENT_DATA)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Cast the data to the structure
-	recv := (*SIMCONNECT_RECV_SIMOBJECT_DATA)(unsafe.Pointer(&data[0]))
+	var recv *SIMCONNECT_RECV_SIMOBJECT_DATA
+	switch m := message.(type) {
+	case *SIMCONNECT_RECV_SIMOBJECT_DATA:
+		recv = m
+	case *SIMCONNECT_RECV_SIMOBJECT_DATA_BYTYPE:
+		recv = &m.SIMCONNECT_RECV_SIMOBJECT_DATA
+	case *SIMCONNECT_RECV_CLIENT_DATA:
+		recv = &m.SIMCONNECT_RECV_SIMOBJECT_DATA
+	}
 
-	// Calculate where the actual simulation data starts
-	headerSize := unsafe.Sizeof(SIMCONNECT_RECV_SIMOBJECT_DATA{})
-	if len(data) <= int(headerSize) {
+	if len(recv.Data) == 0 {
+		recv.Data = nil
 		return recv, nil, nil // No data portion
 	}
 
-	// Return the header and the remaining data
-	simData := data[headerSize:]
-	return recv, simData, nil
+	return recv, recv.Data, nil
 }
 
 // ParseMessageType returns the message type from raw SimConnect data
+// The header is validated, a DwSize that does not match the buffer is rejected
 func ParseMessageType(data []byte) (uint32, error) {
-	if len(data) < int(unsafe.Sizeof(SIMCONNECT_RECV{})) {
-		return 0, fmt.Errorf("data too short for SIMCONNECT_RECV")
+	header, err := readHeader(data)
+	if err != nil {
+		return 0, err
 	}
-
-	recv := (*SIMCONNECT_RECV)(unsafe.Pointer(&data[0]))
-	return recv.DwID, nil
+	return header.DwID, nil
 }
 
 // ParseEvent parses a SIMCONNECT_RECV_EVENT message from raw bytes
 func ParseEvent(data []byte) (*SIMCONNECT_RECV_EVENT, error) {
-	if len(data) < int(unsafe.Sizeof(SIMCONNECT_RECV_EVENT{})) {
-		return nil, fmt.Errorf("data too short for SIMCONNECT_RECV_EVENT")
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_EVENT)
+	if err != nil {
+		return nil, err
+	}
+	return message.(*SIMCONNECT_RECV_EVENT), nil
+}
+
+// ParseEventEx1 parses a SIMCONNECT_RECV_EVENT_EX1 message from raw bytes
+func ParseEventEx1(data []byte) (*SIMCONNECT_RECV_EVENT_EX1, error) {
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_EVENT_EX1)
+	if err != nil {
+		return nil, err
+	}
+	return message.(*SIMCONNECT_RECV_EVENT_EX1), nil
+}
+
+// ParseEnumerateInputEvents parses a SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS message from raw bytes
+func ParseEnumerateInputEvents(data []byte) (*SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS, error) {
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS)
+	if err != nil {
+		return nil, err
 	}
+	return message.(*SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS), nil
+}
 
-	recv := (*SIMCONNECT_RECV_EVENT)(unsafe.Pointer(&data[0]))
-	return recv, nil
+// ParseGetInputEvent parses a SIMCONNECT_RECV_GET_INPUT_EVENT message from raw bytes
+func ParseGetInputEvent(data []byte) (*SIMCONNECT_RECV_GET_INPUT_EVENT, error) {
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_GET_INPUT_EVENT)
+	if err != nil {
+		return nil, err
+	}
+	return message.(*SIMCONNECT_RECV_GET_INPUT_EVENT), nil
+}
+
+// ParseSubscribeInputEvent parses a SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT message from raw bytes
+func ParseSubscribeInputEvent(data []byte) (*SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT, error) {
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT)
+	if err != nil {
+		return nil, err
+	}
+	return message.(*SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT), nil
+}
+
+// ParseEnumerateInputEventParams parses a SIMCONNECT_RECV_ENUMERATE_INPUT_EVENT_PARAMS message from raw bytes
+func ParseEnumerateInputEventParams(data []byte) (*SIMCONNECT_RECV_ENUMERATE_INPUT_EVENT_PARAMS, error) {
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS)
+	if err != nil {
+		return nil, err
+	}
+	return message.(*SIMCONNECT_RECV_ENUMERATE_INPUT_EVENT_PARAMS), nil
 }
 
 // ParseEventFilename parses a SIMCONNECT_RECV_EVENT_FILENAME message from raw bytes
 func ParseEventFilename(data []byte) (*SIMCONNECT_RECV_EVENT_FILENAME, error) {
-	if len(data) < int(unsafe.Sizeof(SIMCONNECT_RECV_EVENT_FILENAME{})) {
-		return nil, fmt.Errorf("data too short for SIMCONNECT_RECV_EVENT_FILENAME")
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_EVENT_FILENAME)
+	if err != nil {
+		return nil, err
 	}
-
-	recv := (*SIMCONNECT_RECV_EVENT_FILENAME)(unsafe.Pointer(&data[0]))
-	return recv, nil
+	return message.(*SIMCONNECT_RECV_EVENT_FILENAME), nil
 }
 
 // ParseEventObjectAddRemove parses a SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE message from raw bytes
 func ParseEventObjectAddRemove(data []byte) (*SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE, error) {
-	if len(data) < int(unsafe.Sizeof(SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE{})) {
-		return nil, fmt.Errorf("data too short for SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE")
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE)
+	if err != nil {
+		return nil, err
 	}
-
-	recv := (*SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE)(unsafe.Pointer(&data[0]))
-	return recv, nil
+	return message.(*SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE), nil
 }
 
 // ParseEventFrame parses a SIMCONNECT_RECV_EVENT_FRAME message from raw bytes
 func ParseEventFrame(data []byte) (*SIMCONNECT_RECV_EVENT_FRAME, error) {
-	if len(data) < int(unsafe.Sizeof(SIMCONNECT_RECV_EVENT_FRAME{})) {
-		return nil, fmt.Errorf("data too short for SIMCONNECT_RECV_EVENT_FRAME")
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_EVENT_FRAME)
+	if err != nil {
+		return nil, err
 	}
+	return message.(*SIMCONNECT_RECV_EVENT_FRAME), nil
+}
 
-	recv := (*SIMCONNECT_RECV_EVENT_FRAME)(unsafe.Pointer(&data[0]))
-	return recv, nil
+// ParseSystemState parses a SIMCONNECT_RECV_SYSTEM_STATE message from raw bytes
+func ParseSystemState(data []byte) (*SIMCONNECT_RECV_SYSTEM_STATE, error) {
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_SYSTEM_STATE)
+	if err != nil {
+		return nil, err
+	}
+	return message.(*SIMCONNECT_RECV_SYSTEM_STATE), nil
+}
+
+// ParseException parses a SIMCONNECT_RECV_EXCEPTION message from raw bytes
+func ParseException(data []byte) (*SIMCONNECT_RECV_EXCEPTION, error) {
+	message, err := decodeAs(data, SIMCONNECT_RECV_ID_EXCEPTION)
+	if err != nil {
+		return nil, err
+	}
+	return message.(*SIMCONNECT_RECV_EXCEPTION), nil
 }
 
 // SystemStateResponse represents a processed system state response
@@ -191,68 +283,34 @@ type SystemEventCallback func(eventData SystemEventData)
 
 // GetNextDispatch retrieves the next SimConnect message
 func (c *Client) GetNextDispatch() (*SystemStateResponse, error) {
-	if !c.isOpen {
+	if !c.IsOpen() {
 		return nil, fmt.Errorf("client is not open")
 	}
 
-	// Get the SimConnect_GetNextDispatch function from DLL
-	proc := c.dll.NewProc("SimConnect_GetNextDispatch")
-
-	var pData uintptr
-	var cbData uint32
-
-	// Call SimConnect_GetNextDispatch
-	// HRESULT SimConnect_GetNextDispatch(HANDLE hSimConnect, SIMCONNECT_RECV** ppData, DWORD* pcbData)
-	r1, _, _ := proc.Call(
-		c.handle,                         // hSimConnect
-		uintptr(unsafe.Pointer(&pData)),  // ppData
-		uintptr(unsafe.Pointer(&cbData)), // pcbData
-	)
-	hresult := uint32(r1)
-
-	// Handle E_FAIL as "no data available" - this is normal behavior for GetNextDispatch
-	if hresult == E_FAIL {
-		return nil, nil // No message available in queue
-	}
-
-	// Only treat other non-success codes as actual errors
-	if !IsHRESULTSuccess(hresult) {
-		return nil, NewSimConnectError("SimConnect_GetNextDispatch", hresult, GetHRESULTMessage(hresult))
+	// Get raw message data
+	data, err := c.GetRawDispatch()
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if we have data
-	if pData == 0 || cbData == 0 {
+	if data == nil {
 		return nil, nil // No message available
 	}
 
 	// Read the base SIMCONNECT_RECV structure
-	recv := (*SIMCONNECT_RECV)(unsafe.Pointer(pData))
+	msgType, err := ParseMessageType(data)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if this is a system state response
-	if recv.DwID == SIMCONNECT_RECV_ID_SYSTEM_STATE {
-		// Cast to SIMCONNECT_RECV_SYSTEM_STATE
-		systemStateRecv := (*SIMCONNECT_RECV_SYSTEM_STATE)(unsafe.Pointer(pData))
-
-		// Convert the response to our Go structure
-		response := &SystemStateResponse{
-			RequestID:    DataRequestID(systemStateRecv.DwRequestID),
-			IntegerValue: systemStateRecv.DwInteger,
-			FloatValue:   systemStateRecv.FFloat,
-		}
-
-		// Convert the C string to Go string
-		response.StringValue = cStringToGoString(systemStateRecv.SzString[:])
-
-		// Determine the primary data type based on content
-		if response.StringValue != "" {
-			response.DataType = "string"
-		} else if systemStateRecv.FFloat != 0.0 {
-			response.DataType = "float"
-		} else {
-			response.DataType = "integer"
+	if msgType == SIMCONNECT_RECV_ID_SYSTEM_STATE {
+		systemStateRecv, err := ParseSystemState(data)
+		if err != nil {
+			return nil, err
 		}
 
-		return response, nil
+		return newSystemStateResponse(systemStateRecv), nil
 	}
 
 	// Not a system state response - we got another message type
@@ -260,92 +318,90 @@ func (c *Client) GetNextDispatch() (*SystemStateResponse, error) {
 	return nil, nil
 }
 
-// GetNextDispatchDebug retrieves the next SimConnect message with debug information
-func (c *Client) GetNextDispatchDebug() (*SystemStateResponse, error) {
-	if !c.isOpen {
-		return nil, fmt.Errorf("client is not open")
+// newSystemStateResponse converts a SYSTEM_STATE message into a SystemStateResponse
+func newSystemStateResponse(recv *SIMCONNECT_RECV_SYSTEM_STATE) *SystemStateResponse {
+	response := &SystemStateResponse{
+		RequestID:    DataRequestID(recv.DwRequestID),
+		IntegerValue: recv.DwInteger,
+		FloatValue:   recv.FFloat,
 	}
 
-	// Get the SimConnect_GetNextDispatch function from DLL
-	proc := c.dll.NewProc("SimConnect_GetNextDispatch")
+	// Convert the C string to Go string
+	response.StringValue = cStringToGoString(recv.SzString[:])
 
-	var pData uintptr
-	var cbData uint32
-
-	// Call SimConnect_GetNextDispatch
-	r1, _, _ := proc.Call(
-		c.handle,                         // hSimConnect
-		uintptr(unsafe.Pointer(&pData)),  // ppData
-		uintptr(unsafe.Pointer(&cbData)), // pcbData
-	)
-	hresult := uint32(r1)
-
-	// Handle E_FAIL as "no data available"
-	if hresult == E_FAIL {
-		return nil, nil // No message available in queue
+	// Determine the primary data type based on content
+	if response.StringValue != "" {
+		response.DataType = "string"
+	} else if recv.FFloat != 0.0 {
+		response.DataType = "float"
+	} else {
+		response.DataType = "integer"
 	}
 
-	// Only treat other non-success codes as actual errors
-	if !IsHRESULTSuccess(hresult) {
-		return nil, NewSimConnectError("SimConnect_GetNextDispatch", hresult, GetHRESULTMessage(hresult))
+	return response
+}
+
+// GetNextMessage retrieves the next SimConnect message decoded into its concrete type
+// Returns nil without error when no message is available
+func (c *Client) GetNextMessage() (Message, error) {
+	data, err := c.GetRawDispatch()
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if we have data
-	if pData == 0 || cbData == 0 {
+	if data == nil {
 		return nil, nil // No message available
 	}
 
-	// Read the base SIMCONNECT_RECV structure
-	recv := (*SIMCONNECT_RECV)(unsafe.Pointer(pData))
+	return DecodeMessage(data)
+}
 
-	// Debug: Print what message type we received
-	fmt.Printf("🔍 DEBUG: Received message type: 0x%08X, size: %d bytes\n", recv.DwID, cbData)
+// GetNextMessageContext waits for the next SimConnect message and decodes it into its concrete type.
+// It returns ctx.Err() when ctx is done before a message arrives.
+func (c *Client) GetNextMessageContext(ctx context.Context) (Message, error) {
+	for {
+		msg, err := c.GetNextMessage()
+		if err != nil || msg != nil {
+			return msg, err
+		}
 
-	// Check if this is a system state response
-	if recv.DwID == SIMCONNECT_RECV_ID_SYSTEM_STATE {
-		fmt.Println("✅ Found SYSTEM_STATE message!")
-		// Cast to SIMCONNECT_RECV_SYSTEM_STATE
-		systemStateRecv := (*SIMCONNECT_RECV_SYSTEM_STATE)(unsafe.Pointer(pData))
-
-		// Convert the response to our Go structure
-		response := &SystemStateResponse{
-			RequestID:    DataRequestID(systemStateRecv.DwRequestID),
-			IntegerValue: systemStateRecv.DwInteger,
-			FloatValue:   systemStateRecv.FFloat,
+		if err := c.WaitForMessage(ctx); err != nil {
+			return nil, err
 		}
+	}
+}
 
-		// Convert the C string to Go string
-		response.StringValue = cStringToGoString(systemStateRecv.SzString[:])
+// GetNextDispatchDebug retrieves the next SimConnect message and returns it when it is a system state response
+// Other messages are discarded
+func (c *Client) GetNextDispatchDebug() (*SystemStateResponse, error) {
+	if !c.IsOpen() {
+		return nil, fmt.Errorf("client is not open")
+	}
 
-		// Determine the primary data type based on content
-		if response.StringValue != "" {
-			response.DataType = "string"
-		} else if systemStateRecv.FFloat != 0.0 {
-			response.DataType = "float"
-		} else {
-			response.DataType = "integer"
-		}
+	// Get raw message data
+	data, err := c.GetRawDispatch()
+	if err != nil {
+		return nil, err
+	}
 
-		return response, nil
-	} else { // Print what other message types we're getting
-		switch recv.DwID {
-		case SIMCONNECT_RECV_ID_NULL:
-			fmt.Println("📭 Received NULL message")
-		case SIMCONNECT_RECV_ID_EXCEPTION:
-			fmt.Println("⚠️  Received EXCEPTION message")
-		case SIMCONNECT_RECV_ID_OPEN:
-			fmt.Println("🔗 Received OPEN confirmation message")
-		case SIMCONNECT_RECV_ID_QUIT:
-			fmt.Println("👋 Received QUIT message")
-		case SIMCONNECT_RECV_ID_EVENT:
-			fmt.Println("📡 Received EVENT message")
-		case SIMCONNECT_RECV_ID_SIMOBJECT_DATA:
-			fmt.Println("📊 Received SIMOBJECT_DATA message")
-		case SIMCONNECT_RECV_ID_CLIENT_DATA:
-			fmt.Println("💾 Received CLIENT_DATA message")
-		default:
-			fmt.Printf("❓ Received unknown message type: 0x%08X\n", recv.DwID)
+	if data == nil {
+		return nil, nil // No message available
+	}
+
+	// Read the base SIMCONNECT_RECV structure
+	msgType, err := ParseMessageType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if this is a system state response
+	if msgType == SIMCONNECT_RECV_ID_SYSTEM_STATE {
+		systemStateRecv, err := ParseSystemState(data)
+		if err != nil {
+			return nil, err
 		}
+
+		return newSystemStateResponse(systemStateRecv), nil
 	}
 
 	// Not a system state response
@@ -365,7 +421,7 @@ func cStringToGoString(data []byte) string {
 
 // GetSimObjectData retrieves the next SimConnect message and returns simulation object data if found
 func (c *Client) GetSimObjectData() (*SIMCONNECT_RECV_SIMOBJECT_DATA, []float64, error) {
-	if !c.isOpen {
+	if !c.IsOpen() {
 		return nil, nil, fmt.Errorf("client is not open")
 	}
 
@@ -401,21 +457,16 @@ func (c *Client) GetSimObjectData() (*SIMCONNECT_RECV_SIMOBJECT_DATA, []float64,
 	}
 
 	// Parse the simulation data as an array of float64 values
-	// Each float64 is 8 bytes
+	// Each float64 is 8 bytes, definitions holding other data types cannot be read this way
 	const float64Size = 8
-	numFloats := len(simData) / float64Size
-
-	if numFloats == 0 {
-		return header, nil, nil
+	if len(simData)%float64Size != 0 {
+		return header, nil, newMessageError(header.DwID, ErrMessageInvalid,
+			"data block of %d bytes is not a sequence of FLOAT64 values", len(simData))
 	}
 
-	floats := make([]float64, numFloats)
-	for i := 0; i < numFloats; i++ {
-		offset := i * float64Size
-		if offset+float64Size <= len(simData) {
-			// Convert bytes to float64
-			floats[i] = *(*float64)(unsafe.Pointer(&simData[offset]))
-		}
+	floats := make([]float64, len(simData)/float64Size)
+	for i := range floats {
+		floats[i] = math.Float64frombits(binary.LittleEndian.Uint64(simData[i*float64Size:]))
 	}
 
 	return header, floats, nil
@@ -0,0 +1,252 @@
+package client_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// cString returns the text of a null-terminated byte array
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+func TestParsers(t *testing.T) {
+	block := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	descriptors := []client.SIMCONNECT_INPUT_EVENT_DESCRIPTOR{
+		{Name: "AUTOPILOT_AP_MASTER", Hash: 0x1122334455667788, Type: client.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE},
+		{Name: "COM1_STANDBY", Hash: 0x0102030405060708, Type: client.SIMCONNECT_INPUT_EVENT_TYPE_STRING},
+	}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		fields func(data []byte) ([]interface{}, error) // Parses data and returns the fields to compare
+		want   []interface{}
+	}{
+		{
+			name: "event",
+			data: simtest.EncodeEvent(3, 7, 1),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseEvent(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.GroupID, m.EventID, m.Data}, nil
+			},
+			want: []interface{}{uint32(3), uint32(7), uint32(1)},
+		},
+		{
+			name: "event ex1",
+			data: simtest.EncodeEventEx1(3, 7, [5]uint32{1, 2, 3, 4, 5}),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseEventEx1(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.GroupID, m.EventID, m.Data}, nil
+			},
+			want: []interface{}{uint32(3), uint32(7), [5]uint32{1, 2, 3, 4, 5}},
+		},
+		{
+			name: "event filename",
+			data: simtest.EncodeEventFilename(4, 2, `flights\default.flt`),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseEventFilename(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.EventID, m.Data, cString(m.SzFileName[:])}, nil
+			},
+			want: []interface{}{uint32(4), uint32(2), `flights\default.flt`},
+		},
+		{
+			name: "event object add remove",
+			data: simtest.EncodeEventObjectAddRemove(5, 42, 3),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseEventObjectAddRemove(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.EventID, m.ObjectID, m.ObjType}, nil
+			},
+			want: []interface{}{uint32(5), uint32(42), uint32(3)},
+		},
+		{
+			name: "event frame",
+			data: simtest.EncodeEventFrame(6, 59.5, 2),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseEventFrame(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.EventID, m.FrameRate, m.SimSpeed}, nil
+			},
+			want: []interface{}{uint32(6), float32(59.5), float32(2)},
+		},
+		{
+			name: "system state",
+			data: simtest.EncodeSystemState(8, 1, 0.5, "flights/default.flt"),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseSystemState(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.DwRequestID, m.DwInteger, m.FFloat, cString(m.SzString[:])}, nil
+			},
+			want: []interface{}{uint32(8), uint32(1), float32(0.5), "flights/default.flt"},
+		},
+		{
+			name: "exception",
+			data: simtest.EncodeException(uint32(client.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED), 17, 2),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseException(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.Exception, m.SendID, m.Index}, nil
+			},
+			want: []interface{}{client.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED, uint32(17), uint32(2)},
+		},
+		{
+			name: "simobject data",
+			data: simtest.EncodeSimObjectData(9, 1, 10, 0, 1, block),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, block, err := client.ParseSimObjectData(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.DwRequestID, m.DwObjectID, m.DwDefineID, m.DwDefineCount, block}, nil
+			},
+			want: []interface{}{uint32(9), uint32(1), uint32(10), uint32(1), block},
+		},
+		{
+			name: "simobject data without block",
+			data: simtest.EncodeSimObjectData(9, 1, 10, 0, 0, nil),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, block, err := client.ParseSimObjectData(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.DwRequestID, block == nil, m.Data == nil}, nil
+			},
+			want: []interface{}{uint32(9), true, true},
+		},
+		{
+			name: "enumerate input events",
+			data: simtest.EncodeEnumerateInputEvents(11, 1, 2, descriptors),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseEnumerateInputEvents(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.RequestID, m.ArraySize, m.EntryNumber, m.OutOf, m.List}, nil
+			},
+			want: []interface{}{uint32(11), uint32(2), uint32(1), uint32(2), descriptors},
+		},
+		{
+			name: "get input event double",
+			data: simtest.EncodeGetInputEvent(12, 1.5),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseGetInputEvent(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.RequestID, m.Type, m.Value}, nil
+			},
+			want: []interface{}{uint32(12), client.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE, 1.5},
+		},
+		{
+			name: "get input event string",
+			data: simtest.EncodeGetInputEvent(13, "122.800"),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseGetInputEvent(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.RequestID, m.Type, m.Value}, nil
+			},
+			want: []interface{}{uint32(13), client.SIMCONNECT_INPUT_EVENT_TYPE_STRING, "122.800"},
+		},
+		{
+			name: "subscribe input event",
+			data: simtest.EncodeSubscribeInputEvent(0x1122334455667788, 0.0),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseSubscribeInputEvent(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.Hash, m.Type, m.Value}, nil
+			},
+			want: []interface{}{uint64(0x1122334455667788), client.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE, 0.0},
+		},
+		{
+			name: "enumerate input event params",
+			data: simtest.EncodeEnumerateInputEventParams(0x1122334455667788, "FLOAT64;STRING"),
+			fields: func(data []byte) ([]interface{}, error) {
+				m, err := client.ParseEnumerateInputEventParams(data)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{m.Hash, m.Value}, nil
+			},
+			want: []interface{}{uint64(0x1122334455667788), "FLOAT64;STRING"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fields(tt.data)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsersRejectMalformedMessages(t *testing.T) {
+	event := simtest.EncodeEvent(3, 7, 1)
+	oversized := append(append([]byte(nil), event...), 0, 0, 0, 0)
+	// A consistent header whose body is too short for an event
+	shortBody := append([]byte(nil), event[:len(event)-4]...)
+	binary.LittleEndian.PutUint32(shortBody[0:4], uint32(len(shortBody)))
+
+	parseMessageType := func(data []byte) error { _, err := client.ParseMessageType(data); return err }
+	parseEvent := func(data []byte) error { _, err := client.ParseEvent(data); return err }
+
+	tests := []struct {
+		name  string
+		data  []byte
+		parse func(data []byte) error
+		want  error
+	}{
+		{"empty buffer", nil, parseMessageType, client.ErrMessageTruncated},
+		{"truncated header", event[:8], parseMessageType, client.ErrMessageTruncated},
+		{"buffer shorter than size", event[:len(event)-4], parseEvent, client.ErrMessageSizeMismatch},
+		{"buffer longer than size", oversized, parseEvent, client.ErrMessageSizeMismatch},
+		{"body shorter than message", shortBody, parseEvent, client.ErrMessageTruncated},
+		{"other message type", simtest.EncodeQuit(), parseEvent, client.ErrMessageType},
+		{"event as frame", event, func(data []byte) error { _, err := client.ParseEventFrame(data); return err }, client.ErrMessageType},
+		{"exception as system state", simtest.EncodeException(1, 2, 3), func(data []byte) error { _, err := client.ParseSystemState(data); return err }, client.ErrMessageType},
+		{"event as simobject data", event, func(data []byte) error { _, _, err := client.ParseSimObjectData(data); return err }, client.ErrMessageType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.parse(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
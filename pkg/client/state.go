@@ -0,0 +1,168 @@
+package client
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ConnectionState describes the lifecycle of a SimConnect connection
+type ConnectionState int
+
+// Connection states
+const (
+	StateDisconnected ConnectionState = iota // Not connected, Open has not been called or Close completed
+	StateConnecting                          // Open succeeded, waiting for SIMCONNECT_RECV_OPEN
+	StateOpen                                // SIMCONNECT_RECV_OPEN received, the simulator is talking to us
+	StateQuitting                            // SIMCONNECT_RECV_QUIT received, the simulator is shutting down
+	StateLost                                // The connection dropped (STATUS_REMOTE_DISCONNECT)
+)
+
+// String returns the name of the connection state
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "Disconnected"
+	case StateConnecting:
+		return "Connecting"
+	case StateOpen:
+		return "Open"
+	case StateQuitting:
+		return "Quitting"
+	case StateLost:
+		return "Lost"
+	default:
+		return fmt.Sprintf("ConnectionState(%d)", int(s))
+	}
+}
+
+// StateChangeHandler is called when the connection state changes
+type StateChangeHandler func(from, to ConnectionState)
+
+// stateChange is a transition waiting to be delivered to the handlers
+type stateChange struct {
+	from, to ConnectionState
+}
+
+// connectionState tracks the client's connection state and its subscribers
+type connectionState struct {
+	mutex      sync.RWMutex                     // Guards the fields below
+	state      ConnectionState                  // Current state
+	handlers   map[HandlerID]StateChangeHandler // State change subscribers
+	nextID     HandlerID                        // Last assigned handler ID
+	pending    []stateChange                    // Transitions not yet delivered
+	delivering bool                             // A goroutine is delivering pending transitions
+}
+
+// State returns the current connection state
+func (c *Client) State() ConnectionState {
+	c.conn.mutex.RLock()
+	defer c.conn.mutex.RUnlock()
+	return c.conn.state
+}
+
+// OnStateChange registers a handler called on every connection state change.
+// Handlers run in transition order on the goroutine that caused the change and should return quickly.
+func (c *Client) OnStateChange(handler StateChangeHandler) HandlerID {
+	c.conn.mutex.Lock()
+	defer c.conn.mutex.Unlock()
+
+	if c.conn.handlers == nil {
+		c.conn.handlers = make(map[HandlerID]StateChangeHandler)
+	}
+	c.conn.nextID++
+	c.conn.handlers[c.conn.nextID] = handler
+	return c.conn.nextID
+}
+
+// RemoveStateChangeHandler unregisters a state change handler; removing an unknown ID is a no-op
+func (c *Client) RemoveStateChangeHandler(id HandlerID) {
+	c.conn.mutex.Lock()
+	defer c.conn.mutex.Unlock()
+	delete(c.conn.handlers, id)
+}
+
+// setState moves the client to a new state and notifies the handlers
+func (c *Client) setState(state ConnectionState) {
+	c.transition(func(ConnectionState) (ConnectionState, bool) { return state, true })
+}
+
+// transition applies next to the current state and notifies the handlers of the result.
+// next returns false to leave the state unchanged.
+func (c *Client) transition(next func(current ConnectionState) (ConnectionState, bool)) {
+	c.conn.mutex.Lock()
+	to, ok := next(c.conn.state)
+	if !ok || to == c.conn.state {
+		c.conn.mutex.Unlock()
+		return
+	}
+	c.conn.pending = append(c.conn.pending, stateChange{from: c.conn.state, to: to})
+	c.conn.state = to
+
+	// A handler changing the state again only queues the transition, the loop below delivers it
+	if c.conn.delivering {
+		c.conn.mutex.Unlock()
+		return
+	}
+	c.conn.delivering = true
+
+	for len(c.conn.pending) > 0 {
+		change := c.conn.pending[0]
+		c.conn.pending = c.conn.pending[1:]
+		handlers := make([]StateChangeHandler, 0, len(c.conn.handlers))
+		for _, handler := range c.conn.handlers {
+			handlers = append(handlers, handler)
+		}
+		c.conn.mutex.Unlock()
+
+		for _, handler := range handlers {
+			c.invokeStateHandler(handler, change)
+		}
+
+		c.conn.mutex.Lock()
+	}
+
+	c.conn.delivering = false
+	c.conn.mutex.Unlock()
+}
+
+// invokeStateHandler calls a state change handler and reports panics on the dispatcher's error channel
+func (c *Client) invokeStateHandler(handler StateChangeHandler, change stateChange) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.dispatcher.reportError(fmt.Errorf("state change handler panic: %v", r))
+		}
+	}()
+	handler(change.from, change.to)
+}
+
+// observeMessage updates the state from OPEN and QUIT messages
+func (c *Client) observeMessage(data []byte) {
+	if len(data) < recvHeaderSize {
+		return
+	}
+
+	switch binary.LittleEndian.Uint32(data[8:12]) {
+	case SIMCONNECT_RECV_ID_OPEN:
+		c.transition(func(current ConnectionState) (ConnectionState, bool) {
+			return StateOpen, current == StateConnecting
+		})
+	case SIMCONNECT_RECV_ID_QUIT:
+		c.transition(func(current ConnectionState) (ConnectionState, bool) {
+			return StateQuitting, current == StateConnecting || current == StateOpen
+		})
+	}
+}
+
+// observeError moves the client to StateLost when a call reports STATUS_REMOTE_DISCONNECT
+func (c *Client) observeError(err error) {
+	var simErr *SimConnectError
+	if !errors.As(err, &simErr) || simErr.HRESULT != STATUS_REMOTE_DISCONNECT {
+		return
+	}
+
+	c.transition(func(current ConnectionState) (ConnectionState, bool) {
+		return StateLost, current != StateDisconnected
+	})
+}
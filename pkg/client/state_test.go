@@ -0,0 +1,119 @@
+package client_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// stateStep drives a client on a MemoryTransport
+type stateStep func(simClient *client.Client, transport *client.MemoryTransport)
+
+// receive queues a message and reads it, so the client observes it
+func receive(message []byte) stateStep {
+	return func(simClient *client.Client, transport *client.MemoryTransport) {
+		transport.Push(message)
+		simClient.GetRawDispatch()
+	}
+}
+
+// disconnect makes the next dispatch fail with STATUS_REMOTE_DISCONNECT
+func disconnect(simClient *client.Client, transport *client.MemoryTransport) {
+	transport.Fail("SimConnect_GetNextDispatch", client.NewSimConnectError("SimConnect_GetNextDispatch", client.STATUS_REMOTE_DISCONNECT, "pipe closed"))
+	simClient.GetRawDispatch()
+	transport.Fail("SimConnect_GetNextDispatch", nil)
+}
+
+// openStep opens the client
+func openStep(simClient *client.Client, _ *client.MemoryTransport) { simClient.Open() }
+
+// closeStep closes the client
+func closeStep(simClient *client.Client, _ *client.MemoryTransport) { simClient.Close() }
+
+func TestConnectionStateTransitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []stateStep
+		want  []string // Transitions reported to OnStateChange
+	}{
+		{
+			name:  "open confirmed",
+			steps: []stateStep{openStep, receive(simtest.EncodeOpen("test"))},
+			want:  []string{"Disconnected>Connecting", "Connecting>Open"},
+		},
+		{
+			name:  "repeated open message",
+			steps: []stateStep{openStep, receive(simtest.EncodeOpen("test")), receive(simtest.EncodeOpen("test"))},
+			want:  []string{"Disconnected>Connecting", "Connecting>Open"},
+		},
+		{
+			name:  "simulator quits",
+			steps: []stateStep{openStep, receive(simtest.EncodeOpen("test")), receive(simtest.EncodeQuit()), closeStep},
+			want:  []string{"Disconnected>Connecting", "Connecting>Open", "Open>Quitting", "Quitting>Disconnected"},
+		},
+		{
+			name:  "quit before confirmation",
+			steps: []stateStep{openStep, receive(simtest.EncodeQuit())},
+			want:  []string{"Disconnected>Connecting", "Connecting>Quitting"},
+		},
+		{
+			name:  "connection lost",
+			steps: []stateStep{openStep, receive(simtest.EncodeOpen("test")), disconnect},
+			want:  []string{"Disconnected>Connecting", "Connecting>Open", "Open>Lost"},
+		},
+		{
+			name:  "reopen after loss",
+			steps: []stateStep{openStep, disconnect, openStep, receive(simtest.EncodeOpen("test"))},
+			want:  []string{"Disconnected>Connecting", "Connecting>Lost", "Lost>Connecting", "Connecting>Open"},
+		},
+		{
+			name:  "other messages keep the state",
+			steps: []stateStep{openStep, receive(simtest.EncodeEvent(0, 1, 1)), receive(simtest.EncodeException(1, 1, 1))},
+			want:  []string{"Disconnected>Connecting"},
+		},
+		{
+			name:  "close while connecting",
+			steps: []stateStep{openStep, closeStep},
+			want:  []string{"Disconnected>Connecting", "Connecting>Disconnected"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := client.NewMemoryTransport()
+			simClient := client.NewClientWithTransport("test", transport)
+			defer func() {
+				if simClient.State() != client.StateDisconnected {
+					simClient.Close()
+				}
+			}()
+
+			var got []string
+			simClient.OnStateChange(func(from, to client.ConnectionState) {
+				got = append(got, fmt.Sprintf("%s>%s", from, to))
+			})
+			for _, step := range tt.steps {
+				step(simClient, transport)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("transitions %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailedOpenStaysDisconnected(t *testing.T) {
+	transport := client.NewMemoryTransport()
+	transport.Fail("SimConnect_Open", client.NewSimConnectError("SimConnect_Open", client.E_FAIL, "simulator is not running"))
+	simClient := client.NewClientWithTransport("test", transport)
+
+	if err := simClient.Open(); err == nil {
+		t.Fatal("Open succeeded")
+	}
+	if state := simClient.State(); state != client.StateDisconnected {
+		t.Errorf("state %s after failed Open, want %s", state, client.StateDisconnected)
+	}
+}
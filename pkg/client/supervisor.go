@@ -0,0 +1,315 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ReconnectConfig controls how a Supervisor retries Open
+type ReconnectConfig struct {
+	InitialDelay time.Duration // Delay after the first failed attempt
+	MaxDelay     time.Duration // Upper bound for the delay between attempts
+	Multiplier   float64       // Growth factor of the delay after each failed attempt
+	MaxAttempts  int           // Attempts per reconnect before giving up, 0 retries forever
+	OpenTimeout  time.Duration // Wait for SIMCONNECT_RECV_OPEN after Open, a failed attempt when exceeded
+}
+
+// DefaultReconnectConfig returns a backoff from 1 second up to 30 seconds that retries forever
+// and waits 10 seconds for the simulator to confirm each connection
+func DefaultReconnectConfig() ReconnectConfig {
+	return ReconnectConfig{
+		InitialDelay: time.Second,
+		MaxDelay:     30 * time.Second,
+		Multiplier:   2,
+		OpenTimeout:  10 * time.Second,
+	}
+}
+
+// ReplayFunc re-creates connection-scoped SimConnect state after a reconnect.
+// It runs once the simulator has confirmed the connection, while State() is StateOpen.
+type ReplayFunc func() error
+
+// Supervisor reopens the client after the simulator quits or the connection is lost,
+// then replays the definitions, requests and subscriptions of the client's managers
+type Supervisor struct {
+	client     *Client         // Supervised client
+	config     ReconnectConfig // Backoff settings
+	mutex      sync.RWMutex    // Thread safety
+	running    bool            // Supervisor state
+	handler    HandlerID       // State change handler registered while running
+	wake       chan struct{}   // Signals the loop to reconnect
+	stopChan   chan struct{}   // Closed by Stop
+	done       chan struct{}   // Closed when the loop exits
+	errorChan  chan error      // Error notifications
+	reconnects int64           // Completed reconnects
+	lastReplay time.Time       // Time of the last completed reconnect
+}
+
+// NewSupervisor creates a reconnect supervisor for the client
+func NewSupervisor(client *Client, config ReconnectConfig) *Supervisor {
+	defaults := DefaultReconnectConfig()
+	if config.InitialDelay <= 0 {
+		config.InitialDelay = defaults.InitialDelay
+	}
+	if config.MaxDelay < config.InitialDelay {
+		config.MaxDelay = config.InitialDelay
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = defaults.Multiplier
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaults.OpenTimeout
+	}
+
+	return &Supervisor{
+		client:    client,
+		config:    config,
+		errorChan: make(chan error, 10), // Buffered channel for errors
+	}
+}
+
+// OnReconnect registers a function replayed after every reconnect, after the managers' state.
+// FlightDataManager and SystemEventManager register themselves when they are created.
+func (s *Supervisor) OnReconnect(replay ReplayFunc) {
+	s.client.addReplay(replay)
+}
+
+// Start begins supervising the client; a client that is not open is connected right away
+func (s *Supervisor) Start() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.running {
+		return fmt.Errorf("supervisor is already running")
+	}
+
+	s.running = true
+	s.wake = make(chan struct{}, 1)
+	s.stopChan = make(chan struct{})
+	s.done = make(chan struct{})
+	s.handler = s.client.OnStateChange(s.stateChanged)
+
+	go s.loop(s.wake, s.stopChan, s.done)
+
+	if !s.client.IsOpen() {
+		s.wake <- struct{}{} // Connect right away
+	}
+	return nil
+}
+
+// Stop halts supervision and waits for a running reconnect attempt to end.
+// The client is left in its current state.
+func (s *Supervisor) Stop() {
+	s.mutex.Lock()
+	if !s.running {
+		s.mutex.Unlock()
+		return
+	}
+
+	s.client.RemoveStateChangeHandler(s.handler)
+	s.running = false
+	close(s.stopChan)
+	done := s.done
+	s.mutex.Unlock()
+
+	<-done
+}
+
+// Run supervises the client until ctx is done and returns ctx.Err(), or the Start error
+func (s *Supervisor) Run(ctx context.Context) error {
+	if err := s.Start(); err != nil {
+		return err
+	}
+	defer s.Stop()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// IsRunning returns whether the supervisor is active
+func (s *Supervisor) IsRunning() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.running
+}
+
+// GetStats returns how often the supervisor (re)opened the client and when it last did
+func (s *Supervisor) GetStats() (reconnects int64, lastReconnect time.Time) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.reconnects, s.lastReplay
+}
+
+// GetErrors returns a channel for receiving reconnect and replay errors (non-blocking)
+func (s *Supervisor) GetErrors() <-chan error {
+	return s.errorChan
+}
+
+// stateChanged schedules a reconnect when the simulator quits or the connection drops
+func (s *Supervisor) stateChanged(from, to ConnectionState) {
+	if to == StateQuitting || to == StateLost {
+		s.signal()
+	}
+}
+
+// signal wakes the loop without blocking; a pending wake-up covers further signals
+func (s *Supervisor) signal() {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if !s.running {
+		return
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop reconnects each time it is woken until stopped
+func (s *Supervisor) loop(wake, stopChan, done chan struct{}) {
+	defer close(done)
+
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-wake:
+			s.reconnect(stopChan)
+		}
+	}
+}
+
+// reconnect reopens the client with backoff and replays the registered state
+func (s *Supervisor) reconnect(stopChan chan struct{}) {
+	if s.client.IsOpen() {
+		return // Reopened by someone else
+	}
+	if s.client.State() != StateDisconnected {
+		s.client.Close() // Release the dead connection, failures are expected here
+	}
+
+	delay := s.config.InitialDelay
+	for attempt := 1; ; attempt++ {
+		err := s.open(stopChan)
+		if err == nil {
+			break
+		}
+
+		if s.config.MaxAttempts > 0 && attempt >= s.config.MaxAttempts {
+			s.reportError(fmt.Errorf("giving up reconnecting after %d attempts: %v", attempt, err))
+			return
+		}
+
+		select {
+		case <-stopChan:
+			return
+		case <-time.After(delay):
+		}
+
+		delay = time.Duration(float64(delay) * s.config.Multiplier)
+		if delay > s.config.MaxDelay {
+			delay = s.config.MaxDelay
+		}
+	}
+
+	for _, err := range s.client.replay() {
+		s.reportError(fmt.Errorf("failed to replay state after reconnect: %v", err))
+	}
+
+	s.mutex.Lock()
+	s.reconnects++
+	s.lastReplay = time.Now()
+	s.mutex.Unlock()
+}
+
+// open opens the client and waits until the simulator confirms the connection, so replays
+// run in StateOpen. A connection that ends or stays unconfirmed is closed again.
+func (s *Supervisor) open(stopChan chan struct{}) error {
+	// Connection outcome reported by the dispatcher
+	outcome := make(chan ConnectionState, 1)
+	handler := s.client.OnStateChange(func(from, to ConnectionState) {
+		if to == StateOpen || to == StateQuitting || to == StateLost {
+			select {
+			case outcome <- to:
+			default:
+			}
+		}
+	})
+	defer s.client.RemoveStateChangeHandler(handler)
+
+	if err := s.client.Open(); err != nil {
+		return err
+	}
+
+	// SIMCONNECT_RECV_OPEN is delivered by the dispatcher
+	s.client.dispatcher.Start()
+	defer s.client.dispatcher.Stop()
+
+	select {
+	case state := <-outcome:
+		if state == StateOpen {
+			return nil
+		}
+		s.client.Close()
+		return fmt.Errorf("connection %s before it was confirmed", state)
+	case <-time.After(s.config.OpenTimeout):
+		s.client.Close()
+		return fmt.Errorf("simulator did not confirm the connection within %v", s.config.OpenTimeout)
+	case <-stopChan:
+		s.client.Close()
+		return fmt.Errorf("supervisor stopped before the connection was confirmed")
+	}
+}
+
+// reportError sends an error to the error channel without blocking
+func (s *Supervisor) reportError(err error) {
+	select {
+	case s.errorChan <- err:
+	default: // Channel full, drop error
+	}
+}
+
+// replayEntry is a replay function registered with the client
+type replayEntry struct {
+	id     HandlerID  // Identifies the entry for removeReplay
+	replay ReplayFunc // Re-creates the state
+}
+
+// addReplay registers a function re-creating connection-scoped state after a reconnect
+func (c *Client) addReplay(replay ReplayFunc) HandlerID {
+	c.replayMutex.Lock()
+	defer c.replayMutex.Unlock()
+	c.nextReplay++
+	c.replays = append(c.replays, replayEntry{id: c.nextReplay, replay: replay})
+	return c.nextReplay
+}
+
+// removeReplay unregisters a replay function; removing an unknown ID is a no-op
+func (c *Client) removeReplay(id HandlerID) {
+	c.replayMutex.Lock()
+	defer c.replayMutex.Unlock()
+	for i, entry := range c.replays {
+		if entry.id == id {
+			c.replays = append(c.replays[:i], c.replays[i+1:]...)
+			return
+		}
+	}
+}
+
+// replay runs the registered replay functions in registration order
+func (c *Client) replay() []error {
+	c.replayMutex.Lock()
+	replays := append([]replayEntry(nil), c.replays...)
+	c.replayMutex.Unlock()
+
+	var errs []error
+	for _, entry := range replays {
+		if err := entry.replay(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
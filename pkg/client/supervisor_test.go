@@ -0,0 +1,131 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// openClient connects a client to server and waits for the simulator's confirmation
+func openClient(t *testing.T, server *simtest.Server) *client.Client {
+	t.Helper()
+	simClient := server.NewClient("test")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := simClient.OpenContext(ctx); err != nil {
+		t.Fatalf("OpenContext: %v", err)
+	}
+	t.Cleanup(func() {
+		if simClient.State() != client.StateDisconnected {
+			simClient.Close()
+		}
+	})
+	return simClient
+}
+
+func TestSupervisorReplaysInOpenState(t *testing.T) {
+	server := simtest.NewServer()
+	simClient := openClient(t, server)
+
+	// Keep the dispatcher running, so the QUIT message is observed
+	simClient.Dispatcher().Start()
+	defer simClient.Dispatcher().Stop()
+
+	supervisor := client.NewSupervisor(simClient, client.ReconnectConfig{InitialDelay: time.Millisecond})
+	replayed := make(chan client.ConnectionState, 1)
+	supervisor.OnReconnect(func() error {
+		replayed <- simClient.State()
+		return nil
+	})
+	if err := supervisor.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer supervisor.Stop()
+
+	server.Quit()
+
+	select {
+	case state := <-replayed:
+		if state != client.StateOpen {
+			t.Errorf("replay ran in state %s, want %s", state, client.StateOpen)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no replay after the simulator quit")
+	}
+	waitFor(t, "reconnect count", func() bool {
+		reconnects, _ := supervisor.GetStats()
+		return reconnects == 1
+	})
+}
+
+func TestSupervisorRetriesWithBackoff(t *testing.T) {
+	server := simtest.NewServer()
+	simClient := openClient(t, server)
+	simClient.Dispatcher().Start()
+	defer simClient.Dispatcher().Stop()
+
+	supervisor := client.NewSupervisor(simClient, client.ReconnectConfig{
+		InitialDelay: time.Millisecond,
+		MaxDelay:     4 * time.Millisecond,
+		MaxAttempts:  3,
+	})
+	if err := supervisor.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer supervisor.Stop()
+
+	server.FailCall("SimConnect_Open", errors.New("simulator is not running"))
+	server.Quit()
+
+	select {
+	case err := <-supervisor.GetErrors():
+		if err == nil {
+			t.Fatal("nil error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("supervisor did not give up after MaxAttempts")
+	}
+	if reconnects, _ := supervisor.GetStats(); reconnects != 0 {
+		t.Errorf("reconnects = %d, want 0", reconnects)
+	}
+	if simClient.IsOpen() {
+		t.Error("client is open although every attempt failed")
+	}
+}
+
+func TestSupervisorStopClosesUnconfirmedConnection(t *testing.T) {
+	// A MemoryTransport never confirms the connection on its own
+	transport := client.NewMemoryTransport()
+	simClient := client.NewClientWithTransport("test", transport)
+
+	supervisor := client.NewSupervisor(simClient, client.ReconnectConfig{InitialDelay: time.Millisecond})
+	if err := supervisor.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	waitFor(t, "connection attempt", func() bool { return simClient.State() == client.StateConnecting })
+
+	supervisor.Stop()
+	if state := simClient.State(); state != client.StateDisconnected {
+		t.Errorf("state %s after Stop, want %s", state, client.StateDisconnected)
+	}
+	if calls := transport.CallsTo("SimConnect_Close"); len(calls) != 1 {
+		t.Errorf("%d SimConnect_Close calls, want 1", len(calls))
+	}
+}
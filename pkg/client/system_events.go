@@ -2,51 +2,121 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"sync"
-	"time"
 )
 
+// DeliveryMode selects how a SystemEventManager runs event callbacks
+type DeliveryMode int
+
+const (
+	// DeliveryOrdered runs the callbacks of each subscription one after another, in event order,
+	// on a goroutine of the subscription fed through a bounded queue
+	DeliveryOrdered DeliveryMode = iota
+	// DeliveryConcurrent runs every callback in a new goroutine, so events of one subscription,
+	// such as Pause followed by Unpause, may be observed out of order
+	DeliveryConcurrent
+)
+
+// OverflowPolicy decides what happens to an event when a delivery queue is full
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota // Wait for room, holding up the client's dispatcher until the queue is read or the manager stopped
+	OverflowDropOldest                       // Discard the oldest queued event to make room
+	OverflowDropNewest                       // Discard the new event
+)
+
+// DeliveryConfig controls how a SystemEventManager delivers events to callbacks and the Events channel
+type DeliveryConfig struct {
+	Mode      DeliveryMode   // How callbacks are run
+	QueueSize int            // Capacity of each subscription's queue and of the Events channel
+	Overflow  OverflowPolicy // Applied when a queue or the Events channel is full
+}
+
+// DefaultDeliveryConfig returns ordered delivery through queues of 64 events that block when full
+func DefaultDeliveryConfig() DeliveryConfig {
+	return DeliveryConfig{
+		Mode:      DeliveryOrdered,
+		QueueSize: 64,
+		Overflow:  OverflowBlock,
+	}
+}
+
 // SystemEventManager provides thread-safe management of SimConnect system events
 type SystemEventManager struct {
 	client     *Client                                            // SimConnect client
 	mutex      sync.RWMutex                                       // Thread safety
 	callbacks  map[SIMCONNECT_CLIENT_EVENT_ID]SystemEventCallback // Event callbacks
 	eventNames map[SIMCONNECT_CLIENT_EVENT_ID]string              // Event ID to name mapping
+	states     map[SIMCONNECT_CLIENT_EVENT_ID]SIMCONNECT_STATE    // Event states set through SetEventState
+	bound      map[SIMCONNECT_CLIENT_EVENT_ID]boundEvent          // Client events delivered for other components
+	delivery   DeliveryConfig                                     // Callback and Events channel delivery
+	queues     map[SIMCONNECT_CLIENT_EVENT_ID]*eventQueue         // Ordered delivery queues, created with the first event
+	eventsChan chan SystemEventData                               // Events channel, nil until Events is called
+	stopChan   chan struct{}                                      // Closed by Stop, ends a wait for room in the Events channel
 	running    bool                                               // Manager state
-	stopChan   chan struct{}                                      // Stop signal
+	handlers   []HandlerID                                        // Dispatcher handlers registered while running
+	closed     bool                                               // Close was called, the manager cannot be used anymore
+	onError    HandlerID                                          // Exception handler registered by NewSystemEventManager
+	replayID   HandlerID                                          // Replay registered by NewSystemEventManager
 	errorChan  chan error                                         // Error notifications
-	nextID     SIMCONNECT_CLIENT_EVENT_ID                         // Next available event ID
+}
+
+// eventQueue delivers the events of one subscription to its callback in order
+type eventQueue struct {
+	events chan SystemEventData // Queued events
+	quit   chan struct{}        // Closed when the subscription ends, discarding queued events
+}
+
+// boundEvent is a client event that is not a system event subscription, such as the client
+// event of an input binding, delivered through the manager's event dispatch
+type boundEvent struct {
+	name     string              // Name reported as SystemEventData.EventName
+	callback SystemEventCallback // Event callback
 }
 
 // NewSystemEventManager creates a new SystemEventManager instance
 func NewSystemEventManager(client *Client) *SystemEventManager {
-	return &SystemEventManager{
+	sem := &SystemEventManager{
 		client:     client,
 		callbacks:  make(map[SIMCONNECT_CLIENT_EVENT_ID]SystemEventCallback),
 		eventNames: make(map[SIMCONNECT_CLIENT_EVENT_ID]string),
+		states:     make(map[SIMCONNECT_CLIENT_EVENT_ID]SIMCONNECT_STATE),
+		bound:      make(map[SIMCONNECT_CLIENT_EVENT_ID]boundEvent),
+		delivery:   DefaultDeliveryConfig(),
+		queues:     make(map[SIMCONNECT_CLIENT_EVENT_ID]*eventQueue),
 		running:    false,
-		stopChan:   make(chan struct{}),
 		errorChan:  make(chan error, 10), // Buffered channel for non-blocking errors
-		nextID:     1000,                 // Start at 1000 to avoid conflicts
 	}
+
+	// Exceptions caused by our subscriptions are reported on our error channel
+	sem.onError = client.Dispatcher().HandleException(sem.handleException)
+	// Subscriptions are re-created when a Supervisor reconnects the client
+	sem.replayID = client.addReplay(sem.replay)
+	return sem
 }
 
-// SubscribeToEvent subscribes to a system event with a callback
+// SubscribeToEvent subscribes to a system event with a callback. The callback may be nil
+// when the events are read from the Events channel.
 func (sem *SystemEventManager) SubscribeToEvent(eventName string, callback SystemEventCallback) (SIMCONNECT_CLIENT_EVENT_ID, error) {
 	sem.mutex.Lock()
 	defer sem.mutex.Unlock()
 
+	if sem.closed {
+		return 0, fmt.Errorf("SystemEventManager is closed")
+	}
 	if !sem.client.IsOpen() {
 		return 0, fmt.Errorf("SimConnect client is not open")
 	}
 
-	// Assign new event ID
-	eventID := sem.nextID
-	sem.nextID++
+	// Assign new event ID from the client's registry
+	eventID := sem.client.IDs().NewEventID()
 
 	// Subscribe to the event via SimConnect
 	if err := sem.client.SubscribeToSystemEvent(eventID, eventName); err != nil {
+		sem.client.IDs().Release(IDEvent, uint32(eventID))
 		return 0, fmt.Errorf("failed to subscribe to event '%s': %v", eventName, err)
 	}
 
@@ -74,14 +144,17 @@ func (sem *SystemEventManager) UnsubscribeFromEvent(eventID SIMCONNECT_CLIENT_EV
 	// Remove from internal tracking
 	delete(sem.callbacks, eventID)
 	delete(sem.eventNames, eventID)
+	delete(sem.states, eventID)
+	sem.retireQueue(eventID)
+	sem.client.IDs().Release(IDEvent, uint32(eventID))
 
 	return nil
 }
 
 // SetEventState sets the state of a system event (ON/OFF)
 func (sem *SystemEventManager) SetEventState(eventID SIMCONNECT_CLIENT_EVENT_ID, state SIMCONNECT_STATE) error {
-	sem.mutex.RLock()
-	defer sem.mutex.RUnlock()
+	sem.mutex.Lock()
+	defer sem.mutex.Unlock()
 
 	if !sem.client.IsOpen() {
 		return fmt.Errorf("SimConnect client is not open")
@@ -92,7 +165,46 @@ func (sem *SystemEventManager) SetEventState(eventID SIMCONNECT_CLIENT_EVENT_ID,
 		return fmt.Errorf("event ID %d is not subscribed", eventID)
 	}
 
-	return sem.client.SetSystemEventState(eventID, state)
+	if err := sem.client.SetSystemEventState(eventID, state); err != nil {
+		return err
+	}
+
+	sem.states[eventID] = state
+	return nil
+}
+
+// bind delivers a client event owned by another component to callback while the manager runs.
+// Bound events are not subscribed, re-subscribed or listed by GetSubscribedEvents.
+func (sem *SystemEventManager) bind(eventID SIMCONNECT_CLIENT_EVENT_ID, name string, callback SystemEventCallback) {
+	sem.mutex.Lock()
+	defer sem.mutex.Unlock()
+	sem.bound[eventID] = boundEvent{name: name, callback: callback}
+}
+
+// unbind stops delivering a client event registered with bind
+func (sem *SystemEventManager) unbind(eventID SIMCONNECT_CLIENT_EVENT_ID) {
+	sem.mutex.Lock()
+	defer sem.mutex.Unlock()
+	delete(sem.bound, eventID)
+	sem.retireQueue(eventID)
+}
+
+// replay re-subscribes to all events, restoring their states, on a new connection
+func (sem *SystemEventManager) replay() error {
+	sem.mutex.RLock()
+	defer sem.mutex.RUnlock()
+
+	for eventID, eventName := range sem.eventNames {
+		if err := sem.client.SubscribeToSystemEvent(eventID, eventName); err != nil {
+			return fmt.Errorf("failed to re-subscribe to event '%s': %v", eventName, err)
+		}
+		if state, exists := sem.states[eventID]; exists && state != SIMCONNECT_STATE_ON {
+			if err := sem.client.SetSystemEventState(eventID, state); err != nil {
+				return fmt.Errorf("failed to restore state of event '%s': %v", eventName, err)
+			}
+		}
+	}
+	return nil
 }
 
 // Start begins processing system events in a background goroutine
@@ -100,6 +212,9 @@ func (sem *SystemEventManager) Start() error {
 	sem.mutex.Lock()
 	defer sem.mutex.Unlock()
 
+	if sem.closed {
+		return fmt.Errorf("SystemEventManager is closed")
+	}
 	if sem.running {
 		return fmt.Errorf("SystemEventManager is already running")
 	}
@@ -108,26 +223,93 @@ func (sem *SystemEventManager) Start() error {
 		return fmt.Errorf("SimConnect client is not open")
 	}
 
+	// Receive event messages from the client's dispatcher, shared with other managers
+	dispatcher := sem.client.Dispatcher()
+	for _, recvID := range []uint32{
+		SIMCONNECT_RECV_ID_EVENT,
+		SIMCONNECT_RECV_ID_EVENT_FILENAME,
+		SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE,
+		SIMCONNECT_RECV_ID_EVENT_FRAME,
+	} {
+		sem.handlers = append(sem.handlers, dispatcher.HandleMessageType(recvID, sem.handleEvent))
+	}
+
 	sem.running = true
 	sem.stopChan = make(chan struct{})
-
-	// Start event processing goroutine
-	go sem.eventLoop()
+	dispatcher.Start()
 
 	return nil
 }
 
-// Stop halts the system event processing
+// Run starts event processing, blocks until ctx is done and stops it again.
+// It returns the Start error, or ctx.Err() once stopped.
+func (sem *SystemEventManager) Run(ctx context.Context) error {
+	if err := sem.Start(); err != nil {
+		return err
+	}
+	defer sem.Stop()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Stop halts the system event processing. Events still queued for callbacks are discarded.
 func (sem *SystemEventManager) Stop() {
 	sem.mutex.Lock()
-	defer sem.mutex.Unlock()
-
 	if !sem.running {
+		sem.mutex.Unlock()
 		return
 	}
 
-	sem.running = false
+	dispatcher := sem.client.Dispatcher()
+	for _, id := range sem.handlers {
+		dispatcher.RemoveHandler(id)
+	}
+	sem.handlers = nil
+	// Ends the delivery goroutines and releases a handler waiting for room in a queue
+	for eventID := range sem.queues {
+		sem.retireQueue(eventID)
+	}
 	close(sem.stopChan)
+	sem.running = false
+	sem.mutex.Unlock()
+
+	// Released outside the lock, a handler may still be waiting for it
+	dispatcher.Stop()
+}
+
+// Close stops the manager, unsubscribes from its events, returns their IDs to the registry and
+// unregisters its exception handler and replay from the client. A closed manager cannot be used
+// anymore; closing it twice is a no-op. Close must not be called from a dispatcher handler.
+func (sem *SystemEventManager) Close() error {
+	sem.Stop()
+
+	sem.mutex.Lock()
+	defer sem.mutex.Unlock()
+
+	if sem.closed {
+		return nil
+	}
+	sem.closed = true
+
+	sem.client.Dispatcher().RemoveHandler(sem.onError)
+	sem.client.removeReplay(sem.replayID)
+
+	// Without a connection there is nothing to unsubscribe, SimConnect dropped the subscriptions with it
+	var result error
+	for eventID, eventName := range sem.eventNames {
+		if sem.client.IsOpen() {
+			if err := sem.client.UnsubscribeFromSystemEvent(eventID); err != nil && result == nil {
+				result = fmt.Errorf("failed to unsubscribe from event '%s': %v", eventName, err)
+			}
+		}
+		sem.client.IDs().Release(IDEvent, uint32(eventID))
+	}
+	sem.callbacks = make(map[SIMCONNECT_CLIENT_EVENT_ID]SystemEventCallback)
+	sem.eventNames = make(map[SIMCONNECT_CLIENT_EVENT_ID]string)
+	sem.states = make(map[SIMCONNECT_CLIENT_EVENT_ID]SIMCONNECT_STATE)
+	sem.bound = make(map[SIMCONNECT_CLIENT_EVENT_ID]boundEvent)
+	return result
 }
 
 // IsRunning returns whether the event manager is currently running
@@ -142,6 +324,49 @@ func (sem *SystemEventManager) GetErrors() <-chan error {
 	return sem.errorChan
 }
 
+// Events returns a channel receiving the events of all subscriptions in the order they arrive,
+// alongside their callbacks. The channel is created by the first call with the delivery
+// config's queue size and stays open for the lifetime of the manager. When it is full the
+// overflow policy applies: with OverflowBlock the channel must be read, or the client's
+// dispatcher stalls until Stop; the drop policies report discarded events on GetErrors.
+func (sem *SystemEventManager) Events() <-chan SystemEventData {
+	sem.mutex.Lock()
+	defer sem.mutex.Unlock()
+
+	if sem.eventsChan == nil {
+		sem.eventsChan = make(chan SystemEventData, sem.delivery.QueueSize)
+	}
+	return sem.eventsChan
+}
+
+// SetDeliveryConfig changes how events are delivered to callbacks; the manager must not be running.
+// Events still queued for callbacks are discarded. The Events channel keeps its original size.
+func (sem *SystemEventManager) SetDeliveryConfig(config DeliveryConfig) error {
+	if config.QueueSize < 1 {
+		config.QueueSize = DefaultDeliveryConfig().QueueSize
+	}
+
+	sem.mutex.Lock()
+	defer sem.mutex.Unlock()
+
+	if sem.running {
+		return fmt.Errorf("cannot change the delivery config of a running SystemEventManager")
+	}
+
+	for eventID := range sem.queues {
+		sem.retireQueue(eventID)
+	}
+	sem.delivery = config
+	return nil
+}
+
+// GetDeliveryConfig returns the current delivery config
+func (sem *SystemEventManager) GetDeliveryConfig() DeliveryConfig {
+	sem.mutex.RLock()
+	defer sem.mutex.RUnlock()
+	return sem.delivery
+}
+
 // GetSubscribedEvents returns a copy of currently subscribed events
 func (sem *SystemEventManager) GetSubscribedEvents() map[SIMCONNECT_CLIENT_EVENT_ID]string {
 	sem.mutex.RLock()
@@ -155,101 +380,176 @@ func (sem *SystemEventManager) GetSubscribedEvents() map[SIMCONNECT_CLIENT_EVENT
 	return events
 }
 
-// eventLoop is the main event processing loop that runs in a background goroutine
-// This integrates with the existing dispatch mechanism to avoid competing message polling
-func (sem *SystemEventManager) eventLoop() {
-	ticker := time.NewTicker(50 * time.Millisecond) // Poll at 20Hz
-	defer ticker.Stop()
+// handleEvent is the dispatcher handler for event messages
+func (sem *SystemEventManager) handleEvent(data []byte) {
+	msgType, err := ParseMessageType(data)
+	if err != nil {
+		sem.reportError(fmt.Errorf("error parsing message type: %v", err))
+		return
+	}
 
-	for {
-		select {
-		case <-sem.stopChan:
-			return
-
-		case <-ticker.C:
-			// Process all available events in this tick
-			if err := sem.processEventsFromRawDispatch(); err != nil {
-				// Send error to error channel (non-blocking)
-				select {
-				case sem.errorChan <- err:
-				default:
-					// Channel full, skip this error
-				}
-			}
+	// Parse the event using existing GetSystemEvent logic
+	eventData, err := sem.parseEventFromRawData(data, msgType)
+	if err != nil {
+		sem.reportError(fmt.Errorf("error parsing event data: %v", err))
+		return
+	}
+
+	// Find callback and delivery settings
+	sem.mutex.RLock()
+	callback, subscribed := sem.callbacks[eventData.EventID]
+	eventName := sem.eventNames[eventData.EventID]
+	bound, isBound := sem.bound[eventData.EventID]
+	events := sem.eventsChan
+	stopChan := sem.stopChan
+	delivery := sem.delivery
+	sem.mutex.RUnlock()
+
+	if !subscribed {
+		if !isBound {
+			return // Event of another component
 		}
+		callback, eventName = bound.callback, bound.name
+	}
+
+	// Update event data with human-readable name
+	eventData.EventName = eventName
+
+	// The Events channel carries the manager's own subscriptions; Stop ends a wait for room
+	if subscribed && events != nil && offerEvent(events, *eventData, delivery.Overflow, stopChan) {
+		sem.reportError(fmt.Errorf("dropped event '%s', the Events channel is full", eventName))
+	}
+
+	if callback == nil {
+		return
+	}
+
+	if delivery.Mode == DeliveryConcurrent {
+		go sem.invoke(callback, *eventData)
+		return
+	}
+
+	queue := sem.queue(eventData.EventID, callback)
+	if queue == nil {
+		return // Unsubscribed or stopped meanwhile
+	}
+	if offerEvent(queue.events, *eventData, delivery.Overflow, queue.quit) {
+		sem.reportError(fmt.Errorf("dropped event '%s', its callback queue is full", eventName))
 	}
 }
 
-// processEventsFromRawDispatch processes system events directly from raw dispatch data
-// This integrates with the existing FlightDataManager dispatch mechanism to avoid message conflicts
-func (sem *SystemEventManager) processEventsFromRawDispatch() error {
-	for {
-		// Get raw dispatch data directly (same as FlightDataManager)
-		data, err := sem.client.GetRawDispatch()
-		if err != nil {
-			return fmt.Errorf("error getting raw dispatch: %v", err)
-		}
+// queue returns the ordered delivery queue of an event, starting it with the first event.
+// It returns nil once the event is no longer subscribed or bound, or the manager is stopped.
+func (sem *SystemEventManager) queue(eventID SIMCONNECT_CLIENT_EVENT_ID, callback SystemEventCallback) *eventQueue {
+	sem.mutex.Lock()
+	defer sem.mutex.Unlock()
 
-		if data == nil {
-			// No more messages available
-			break
-		}
+	if queue, exists := sem.queues[eventID]; exists {
+		return queue
+	}
 
-		// Parse message type
-		msgType, err := ParseMessageType(data)
-		if err != nil {
-			return fmt.Errorf("error parsing message type: %v", err)
-		}
+	_, subscribed := sem.callbacks[eventID]
+	_, isBound := sem.bound[eventID]
+	if !sem.running || (!subscribed && !isBound) {
+		return nil
+	}
 
-		// Only process event messages
-		switch msgType {
-		case SIMCONNECT_RECV_ID_EVENT,
-			SIMCONNECT_RECV_ID_EVENT_FILENAME,
-			SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE,
-			SIMCONNECT_RECV_ID_EVENT_FRAME:
-
-			// Parse the event using existing GetSystemEvent logic
-			eventData, err := sem.parseEventFromRawData(data, msgType)
-			if err != nil {
-				return fmt.Errorf("error parsing event data: %v", err)
+	queue := &eventQueue{
+		events: make(chan SystemEventData, sem.delivery.QueueSize),
+		quit:   make(chan struct{}),
+	}
+	sem.queues[eventID] = queue
+
+	// One goroutine per queue runs the callbacks one after another
+	go func() {
+		for {
+			select {
+			case event := <-queue.events:
+				sem.invoke(callback, event)
+			case <-queue.quit:
+				return
 			}
+		}
+	}()
+	return queue
+}
 
-			if eventData != nil {
-				// Find and execute callback
-				sem.mutex.RLock()
-				callback, exists := sem.callbacks[eventData.EventID]
-				eventName, nameExists := sem.eventNames[eventData.EventID]
-				sem.mutex.RUnlock()
-
-				if exists && callback != nil {
-					// Update event data with human-readable name
-					if nameExists {
-						eventData.EventName = eventName
-					}
-
-					// Execute callback in a separate goroutine to prevent blocking
-					go func(event SystemEventData, cb SystemEventCallback) {
-						defer func() {
-							if r := recover(); r != nil {
-								// Send panic as error to error channel
-								select {
-								case sem.errorChan <- fmt.Errorf("event callback panic: %v", r):
-								default:
-								}
-							}
-						}()
-						cb(event)
-					}(*eventData, callback)
-				}
-			}
+// retireQueue ends the delivery queue of an event; the caller holds the lock
+func (sem *SystemEventManager) retireQueue(eventID SIMCONNECT_CLIENT_EVENT_ID) {
+	if queue, exists := sem.queues[eventID]; exists {
+		close(queue.quit)
+		delete(sem.queues, eventID)
+	}
+}
 
+// invoke runs a callback and reports a panic as error
+func (sem *SystemEventManager) invoke(callback SystemEventCallback, event SystemEventData) {
+	defer func() {
+		if r := recover(); r != nil {
+			// Send panic as error to error channel
+			sem.reportError(fmt.Errorf("event callback panic: %v", r))
+		}
+	}()
+	callback(event)
+}
+
+// offerEvent puts an event into a delivery queue according to the overflow policy and reports
+// whether an event was dropped. OverflowBlock gives up when quit is closed.
+func offerEvent(queue chan SystemEventData, event SystemEventData, policy OverflowPolicy, quit <-chan struct{}) bool {
+	switch policy {
+	case OverflowDropNewest:
+		select {
+		case queue <- event:
+			return false
 		default:
-			// Not an event message - ignore (could be flight data, etc.)
-			continue
+			return true
 		}
+	case OverflowDropOldest:
+		dropped := false
+		for {
+			select {
+			case queue <- event:
+				return dropped
+			default:
+			}
+			// Make room; the reader may have done so already
+			select {
+			case <-queue:
+				dropped = true
+			default:
+			}
+		}
+	default:
+		select {
+		case queue <- event:
+		case <-quit:
+		}
+		return false
 	}
+}
 
-	return nil
+// handleException claims exceptions caused by this manager's subscriptions
+func (sem *SystemEventManager) handleException(err *ExceptionError) bool {
+	if err.Packet == nil || err.Packet.EventID == 0 {
+		return false
+	}
+
+	sem.mutex.RLock()
+	_, owned := sem.eventNames[err.Packet.EventID]
+	sem.mutex.RUnlock()
+
+	if owned {
+		sem.reportError(err)
+	}
+	return owned
+}
+
+// reportError sends an error to the error channel without blocking
+func (sem *SystemEventManager) reportError(err error) {
+	select {
+	case sem.errorChan <- err:
+	default: // Channel full, skip this error
+	}
 }
 
 // parseEventFromRawData parses event data from raw dispatch bytes
@@ -317,51 +617,6 @@ func (sem *SystemEventManager) parseEventFromRawData(data []byte, msgType uint32
 	}
 }
 
-// processEvents processes all available system events (legacy method for compatibility)
-func (sem *SystemEventManager) processEvents() error {
-	for {
-		// Get next event
-		eventData, err := sem.client.GetSystemEvent()
-		if err != nil {
-			return fmt.Errorf("error getting system event: %v", err)
-		}
-
-		if eventData == nil {
-			// No more events available
-			break
-		}
-
-		// Find and execute callback
-		sem.mutex.RLock()
-		callback, exists := sem.callbacks[eventData.EventID]
-		eventName, nameExists := sem.eventNames[eventData.EventID]
-		sem.mutex.RUnlock()
-
-		if exists && callback != nil {
-			// Update event data with human-readable name
-			if nameExists {
-				eventData.EventName = eventName
-			}
-
-			// Execute callback in a separate goroutine to prevent blocking
-			go func(event SystemEventData, cb SystemEventCallback) {
-				defer func() {
-					if r := recover(); r != nil {
-						// Send panic as error to error channel
-						select {
-						case sem.errorChan <- fmt.Errorf("event callback panic: %v", r):
-						default:
-						}
-					}
-				}()
-				cb(event)
-			}(*eventData, callback)
-		}
-	}
-
-	return nil
-}
-
 // SubscribeToCommonEvents is a convenience method to subscribe to commonly used events
 func (sem *SystemEventManager) SubscribeToCommonEvents(callbacks map[string]SystemEventCallback) error {
 	for eventName, callback := range callbacks {
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOfferEvent(t *testing.T) {
+	event := func(data uint32) SystemEventData { return SystemEventData{EventName: "Pause", Data: data} }
+
+	tests := []struct {
+		name    string
+		policy  OverflowPolicy
+		queued  []uint32 // Events in the queue before the offer
+		dropped bool     // Whether the offer reports a drop
+		want    []uint32 // Queue contents after the offer
+	}{
+		{"block with room", OverflowBlock, []uint32{1}, false, []uint32{1, 2}},
+		{"drop oldest with room", OverflowDropOldest, []uint32{1}, false, []uint32{1, 2}},
+		{"drop oldest when full", OverflowDropOldest, []uint32{0, 1}, true, []uint32{1, 2}},
+		{"drop newest with room", OverflowDropNewest, nil, false, []uint32{2}},
+		{"drop newest when full", OverflowDropNewest, []uint32{0, 1}, true, []uint32{0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := make(chan SystemEventData, 2)
+			for _, data := range tt.queued {
+				queue <- event(data)
+			}
+
+			if dropped := offerEvent(queue, event(2), tt.policy, nil); dropped != tt.dropped {
+				t.Errorf("dropped = %v, want %v", dropped, tt.dropped)
+			}
+
+			close(queue)
+			var got []uint32
+			for queued := range queue {
+				got = append(got, queued.Data)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("queue holds %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("queue holds %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOfferEventBlocksUntilRoomOrQuit(t *testing.T) {
+	queue := make(chan SystemEventData, 1)
+	queue <- SystemEventData{Data: 1}
+
+	// A reader making room lets the blocked offer complete
+	offered := make(chan bool)
+	go func() { offered <- offerEvent(queue, SystemEventData{Data: 2}, OverflowBlock, nil) }()
+	select {
+	case <-offered:
+		t.Fatal("offer did not wait for room")
+	case <-time.After(20 * time.Millisecond):
+	}
+	<-queue
+	select {
+	case dropped := <-offered:
+		if dropped {
+			t.Error("blocked offer reported a drop")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("offer still blocked after a reader made room")
+	}
+	if event := <-queue; event.Data != 2 {
+		t.Errorf("queued event %d, want 2", event.Data)
+	}
+
+	// Closing quit releases an offer to a full queue without queueing the event
+	queue <- SystemEventData{Data: 3}
+	quit := make(chan struct{})
+	go func() { offered <- offerEvent(queue, SystemEventData{Data: 4}, OverflowBlock, quit) }()
+	close(quit)
+	select {
+	case <-offered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("offer still blocked after quit was closed")
+	}
+	if event := <-queue; event.Data != 3 {
+		t.Errorf("queued event %d, want 3", event.Data)
+	}
+}
@@ -0,0 +1,218 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// stopWithin fails the test when sem.Stop does not return in time
+func stopWithin(t *testing.T, sem *client.SystemEventManager) {
+	t.Helper()
+	stopped := make(chan struct{})
+	go func() {
+		sem.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
+
+func TestSystemEventsUnreadEventsChannelDoesNotStallDispatcher(t *testing.T) {
+	server := simtest.NewServer()
+	simClient := openClient(t, server)
+
+	// Subscribes to the Events channel and never reads it
+	unread := client.NewSystemEventManager(simClient)
+	unread.SetDeliveryConfig(client.DeliveryConfig{QueueSize: 2, Overflow: client.OverflowDropOldest})
+	unread.Events()
+	if _, err := unread.SubscribeToEvent(client.SystemEventPause, nil); err != nil {
+		t.Fatalf("SubscribeToEvent: %v", err)
+	}
+	if err := unread.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	received := make(chan client.SystemEventData, 16)
+	other := client.NewSystemEventManager(simClient)
+	if _, err := other.SubscribeToEvent(client.SystemEventPause, func(event client.SystemEventData) {
+		received <- event
+	}); err != nil {
+		t.Fatalf("SubscribeToEvent: %v", err)
+	}
+	if err := other.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	const fired = 8
+	for i := 0; i < fired; i++ {
+		server.FireEvent(client.SystemEventPause, uint32(i%2))
+	}
+	for i := 0; i < fired; i++ {
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("other manager received %d of %d events", i, fired)
+		}
+	}
+
+	select {
+	case err := <-unread.GetErrors():
+		if err == nil {
+			t.Error("nil error")
+		}
+	default:
+		t.Error("dropped events were not reported")
+	}
+	if events := unread.Events(); len(events) != cap(events) {
+		t.Errorf("Events channel holds %d events, want %d", len(events), cap(events))
+	}
+
+	stopWithin(t, other)
+	stopWithin(t, unread)
+}
+
+func TestSystemEventsStopReleasesBlockedEventsChannel(t *testing.T) {
+	server := simtest.NewServer()
+	simClient := openClient(t, server)
+
+	sem := client.NewSystemEventManager(simClient)
+	sem.SetDeliveryConfig(client.DeliveryConfig{QueueSize: 1, Overflow: client.OverflowBlock})
+	events := sem.Events()
+	if _, err := sem.SubscribeToEvent(client.SystemEventPause, nil); err != nil {
+		t.Fatalf("SubscribeToEvent: %v", err)
+	}
+	if err := sem.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// The first event fills the channel, the second holds up the dispatcher
+	server.FireEvent(client.SystemEventPause, 1)
+	server.FireEvent(client.SystemEventPause, 2)
+	waitFor(t, "full Events channel", func() bool { return len(events) == 1 })
+
+	// Blocked, not dropped: reading makes room for the waiting event
+	if event := <-events; event.Data != 1 {
+		t.Errorf("first event %d, want 1", event.Data)
+	}
+	select {
+	case event := <-events:
+		if event.Data != 2 {
+			t.Errorf("second event %d, want 2", event.Data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting event not delivered after a read")
+	}
+	select {
+	case err := <-sem.GetErrors():
+		t.Errorf("blocking channel reported %v", err)
+	default:
+	}
+
+	// Nobody reads anymore; Stop must release the waiting dispatcher
+	server.FireEvent(client.SystemEventPause, 3)
+	server.FireEvent(client.SystemEventPause, 4)
+	waitFor(t, "full Events channel", func() bool { return len(events) == 1 })
+	stopWithin(t, sem)
+}
+
+func TestSystemEventsStopReleasesFullCallbackQueue(t *testing.T) {
+	server := simtest.NewServer()
+	simClient := openClient(t, server)
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+
+	sem := client.NewSystemEventManager(simClient)
+	sem.SetDeliveryConfig(client.DeliveryConfig{QueueSize: 1, Overflow: client.OverflowBlock})
+	if _, err := sem.SubscribeToEvent(client.SystemEventPause, func(client.SystemEventData) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	}); err != nil {
+		t.Fatalf("SubscribeToEvent: %v", err)
+	}
+	if err := sem.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// The first event holds the callback, the second fills the queue, the third waits for room
+	for i := 0; i < 3; i++ {
+		server.FireEvent(client.SystemEventPause, 1)
+	}
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not invoked")
+	}
+	time.Sleep(20 * time.Millisecond) // Let the dispatcher reach the full queue
+
+	stopWithin(t, sem)
+}
+
+func TestSystemEventManagerClose(t *testing.T) {
+	server := simtest.NewServer()
+	simClient := openClient(t, server)
+	simClient.Dispatcher().Start()
+	defer simClient.Dispatcher().Stop()
+
+	sem := client.NewSystemEventManager(simClient)
+	eventID, err := sem.SubscribeToEvent(client.SystemEventPause, nil)
+	if err != nil {
+		t.Fatalf("SubscribeToEvent: %v", err)
+	}
+	if err := sem.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if err := sem.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if sem.IsRunning() {
+		t.Error("manager still running after Close")
+	}
+	if subscriptions := server.Subscriptions(); len(subscriptions) != 0 {
+		t.Errorf("subscriptions %v still registered after Close", subscriptions)
+	}
+	if simClient.IDs().InUse(client.IDEvent, uint32(eventID)) {
+		t.Errorf("event ID %d still in use after Close", eventID)
+	}
+	if _, err := sem.SubscribeToEvent(client.SystemEventPause, nil); err == nil {
+		t.Error("SubscribeToEvent after Close succeeded")
+	}
+	if err := sem.Start(); err == nil {
+		t.Error("Start after Close succeeded")
+	}
+	if err := sem.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// A reconnect replays the open manager only
+	other := client.NewSystemEventManager(simClient)
+	defer other.Close()
+	if _, err := other.SubscribeToEvent(client.SystemEventPause, nil); err != nil {
+		t.Fatalf("SubscribeToEvent: %v", err)
+	}
+	supervisor := client.NewSupervisor(simClient, client.ReconnectConfig{InitialDelay: time.Millisecond})
+	if err := supervisor.Start(); err != nil {
+		t.Fatalf("supervisor Start: %v", err)
+	}
+	defer supervisor.Stop()
+
+	server.Quit()
+	waitFor(t, "reconnect", func() bool {
+		reconnects, _ := supervisor.GetStats()
+		return reconnects == 1
+	})
+	if subscriptions := server.Subscriptions(); len(subscriptions) != 1 {
+		t.Errorf("subscriptions %v after reconnect, want the other manager's only", subscriptions)
+	}
+}
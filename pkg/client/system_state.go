@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SystemStateValue is the typed answer of QuerySystemState. The concrete type depends on the state:
+//   - SystemStatePath for AircraftLoaded, FlightLoaded and FlightPlan
+//   - SystemStateFlag for DialogMode and Sim
+type SystemStateValue interface {
+	StateName() string // Name of the queried state, e.g. "AircraftLoaded"
+}
+
+// SystemStatePath is a system state holding a file path
+type SystemStatePath struct {
+	State string // AircraftLoaded, FlightLoaded or FlightPlan
+	Path  string // Full path of the file, empty when there is none (e.g. no active flight plan)
+}
+
+// StateName returns the name of the queried state
+func (s SystemStatePath) StateName() string {
+	return s.State
+}
+
+// SystemStateFlag is a system state holding a boolean
+type SystemStateFlag struct {
+	State   string // DialogMode or Sim
+	Enabled bool   // True in dialog mode, or while the user is in control of the aircraft
+}
+
+// StateName returns the name of the queried state
+func (s SystemStateFlag) StateName() string {
+	return s.State
+}
+
+// QuerySystemState requests a system state and waits for the matching SIMCONNECT_RECV_SYSTEM_STATE.
+// The request ID is taken from the client's registry. It returns the exception SimConnect raised
+// for the request, or ctx.Err() when ctx is done first.
+func (c *Client) QuerySystemState(ctx context.Context, state string) (SystemStateValue, error) {
+	decode, err := systemStateDecoder(state)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID := c.ids.NewRequestID()
+	defer c.ids.Release(IDRequest, uint32(requestID))
+
+	response, err := c.RequestSystemStateContext(ctx, DataRequestID(requestID), state)
+	if err != nil {
+		return nil, err
+	}
+	return decode(response), nil
+}
+
+// systemStateDecoder returns the conversion of a response into the state's typed value.
+// State names are case-insensitive like in SimConnect.
+func systemStateDecoder(state string) (func(*SystemStateResponse) SystemStateValue, error) {
+	switch {
+	case strings.EqualFold(state, SystemStateAircraftLoaded),
+		strings.EqualFold(state, SystemStateFlightLoaded),
+		strings.EqualFold(state, SystemStateFlightPlan):
+		return func(response *SystemStateResponse) SystemStateValue {
+			return SystemStatePath{State: state, Path: response.StringValue}
+		}, nil
+	case strings.EqualFold(state, SystemStateDialogMode),
+		strings.EqualFold(state, SystemStateSim):
+		return func(response *SystemStateResponse) SystemStateValue {
+			return SystemStateFlag{State: state, Enabled: response.IntegerValue != 0}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported system state '%s'", state)
+	}
+}
@@ -0,0 +1,129 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// queriedRequestID returns the request ID of the last RequestSystemState call made through transport
+func queriedRequestID(t *testing.T, transport *client.MemoryTransport) client.DataRequestID {
+	t.Helper()
+	calls := transport.CallsTo("SimConnect_RequestSystemState")
+	if len(calls) == 0 {
+		t.Fatal("no system state requested")
+	}
+	return calls[len(calls)-1].Args[0].(client.DataRequestID)
+}
+
+func TestQuerySystemStateTypedResults(t *testing.T) {
+	server := simtest.NewServer()
+	server.SetSystemState(client.SystemStateFlightPlan, simtest.SystemState{String: `flights\plans\LKPR-LKTB.PLN`})
+	server.SetSystemState(client.SystemStateDialogMode, simtest.SystemState{Integer: 1})
+	server.SetSystemState(client.SystemStateSim, simtest.SystemState{Integer: 0})
+	simClient := openClient(t, server)
+
+	tests := []struct {
+		state string
+		want  client.SystemStateValue
+	}{
+		{client.SystemStateAircraftLoaded, client.SystemStatePath{State: client.SystemStateAircraftLoaded, Path: `SimObjects\Airplanes\Asobo_C172SP_AS1000\aircraft.CFG`}},
+		{client.SystemStateFlightLoaded, client.SystemStatePath{State: client.SystemStateFlightLoaded, Path: `flights\other\MainMenu.FLT`}},
+		{client.SystemStateFlightPlan, client.SystemStatePath{State: client.SystemStateFlightPlan, Path: `flights\plans\LKPR-LKTB.PLN`}},
+		{client.SystemStateDialogMode, client.SystemStateFlag{State: client.SystemStateDialogMode, Enabled: true}},
+		{client.SystemStateSim, client.SystemStateFlag{State: client.SystemStateSim, Enabled: false}},
+		{"sim", client.SystemStateFlag{State: "sim", Enabled: false}}, // Names are case-insensitive
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			got, err := simClient.QuerySystemState(ctx, tt.state)
+			if err != nil {
+				t.Fatalf("QuerySystemState: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("result %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	sendID := server.LastSendID()
+	if _, err := simClient.QuerySystemState(context.Background(), "Weather"); err == nil {
+		t.Error("QuerySystemState of an unsupported state succeeded")
+	}
+	if server.LastSendID() != sendID {
+		t.Error("unsupported state was requested")
+	}
+}
+
+func TestQuerySystemStateCorrelatesByRequestID(t *testing.T) {
+	simClient, transport := newMemoryClient(t)
+
+	// Another request's answer arrives first and must not be taken for ours
+	transport.SetResponder(func(call client.TransportCall) [][]byte {
+		if call.Function != "SimConnect_RequestSystemState" {
+			return nil
+		}
+		requestID := call.Args[0].(client.DataRequestID)
+		return [][]byte{
+			simtest.EncodeSystemState(requestID+1, 1, 0, ""),
+			simtest.EncodeSystemState(requestID, 0, 0, ""),
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := simClient.QuerySystemState(ctx, client.SystemStateDialogMode)
+	if err != nil {
+		t.Fatalf("QuerySystemState: %v", err)
+	}
+	if want := (client.SystemStateFlag{State: client.SystemStateDialogMode}); got != want {
+		t.Errorf("result %#v, want %#v", got, want)
+	}
+	if requestID := queriedRequestID(t, transport); simClient.IDs().InUse(client.IDRequest, uint32(requestID)) {
+		t.Errorf("request ID %d not released", requestID)
+	}
+}
+
+func TestQuerySystemStateException(t *testing.T) {
+	simClient, transport := newMemoryClient(t)
+	transport.SetResponder(func(call client.TransportCall) [][]byte {
+		if call.Function != "SimConnect_RequestSystemState" {
+			return nil
+		}
+		return [][]byte{simtest.EncodeException(uint32(client.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED), call.SendID, 2)}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := simClient.QuerySystemState(ctx, client.SystemStateFlightPlan)
+
+	var exception *client.ExceptionError
+	if !errors.As(err, &exception) || exception.Exception != client.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED {
+		t.Fatalf("error %v, want NAME_UNRECOGNIZED", err)
+	}
+	if requestID := queriedRequestID(t, transport); simClient.IDs().InUse(client.IDRequest, uint32(requestID)) {
+		t.Errorf("request ID %d not released", requestID)
+	}
+}
+
+func TestQuerySystemStateContextDone(t *testing.T) {
+	simClient, transport := newMemoryClient(t)
+
+	// Nothing answers the request
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := simClient.QuerySystemState(ctx, client.SystemStateSim); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v, want %v", err, context.DeadlineExceeded)
+	}
+	requestID := queriedRequestID(t, transport)
+	if simClient.IDs().InUse(client.IDRequest, uint32(requestID)) {
+		t.Errorf("request ID %d not released", requestID)
+	}
+}
@@ -0,0 +1,119 @@
+package client
+
+import "context"
+
+// Transport is the backend that carries SimConnect calls to the simulator.
+// Each method mirrors the SimConnect API function of the same name; the Client
+// performs state checks and argument conversion before calling into it.
+//
+// The package ships a SimConnect.dll backend (NewClient), a network backend
+// (NewNetworkClient) and an in-memory fake (NewMemoryTransport). Custom
+// implementations can be plugged in with NewClientWithTransport.
+// Implementations must be safe for use from multiple goroutines.
+type Transport interface {
+	// Open establishes the connection (SimConnect_Open)
+	Open(name string) error
+
+	// Close terminates the connection (SimConnect_Close)
+	Close() error
+
+	// AddToDataDefinition implements SimConnect_AddToDataDefinition
+	AddToDataDefinition(defineID DataDefinitionID, datumName, unitsName string, datumType SIMCONNECT_DATATYPE, epsilon float32, datumID uint32) error
+
+	// ClearDataDefinition implements SimConnect_ClearDataDefinition
+	ClearDataDefinition(defineID DataDefinitionID) error
+
+	// RequestDataOnSimObject implements SimConnect_RequestDataOnSimObject
+	RequestDataOnSimObject(requestID SimObjectDataRequestID, defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, period SIMCONNECT_PERIOD, flags SIMCONNECT_DATA_REQUEST_FLAG, origin, interval, limit uint32) error
+
+	// SetDataOnSimObject implements SimConnect_SetDataOnSimObject
+	SetDataOnSimObject(defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, flags SIMCONNECT_DATA_SET_FLAG, arrayCount, unitSize uint32, data []byte) error
+
+	// RequestSystemState implements SimConnect_RequestSystemState
+	RequestSystemState(requestID DataRequestID, state string) error
+
+	// MapClientEventToSimEvent implements SimConnect_MapClientEventToSimEvent
+	MapClientEventToSimEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, eventName string) error
+
+	// TransmitClientEvent implements SimConnect_TransmitClientEvent
+	TransmitClientEvent(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, data uint32, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG) error
+
+	// TransmitClientEvent_EX1 implements SimConnect_TransmitClientEvent_EX1 (MSFS only)
+	TransmitClientEvent_EX1(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG, data [5]uint32) error
+
+	// AddClientEventToNotificationGroup implements SimConnect_AddClientEventToNotificationGroup
+	AddClientEventToNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, maskable bool) error
+
+	// RemoveClientEvent implements SimConnect_RemoveClientEvent
+	RemoveClientEvent(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID) error
+
+	// SetNotificationGroupPriority implements SimConnect_SetNotificationGroupPriority
+	SetNotificationGroupPriority(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error
+
+	// ClearNotificationGroup implements SimConnect_ClearNotificationGroup
+	ClearNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID) error
+
+	// MapInputEventToClientEvent implements SimConnect_MapInputEventToClientEvent
+	MapInputEventToClientEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string, downEventID SIMCONNECT_CLIENT_EVENT_ID, downValue uint32, upEventID SIMCONNECT_CLIENT_EVENT_ID, upValue uint32, maskable bool) error
+
+	// SetInputGroupPriority implements SimConnect_SetInputGroupPriority
+	SetInputGroupPriority(groupID SIMCONNECT_INPUT_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error
+
+	// RemoveInputEvent implements SimConnect_RemoveInputEvent
+	RemoveInputEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string) error
+
+	// ClearInputGroup implements SimConnect_ClearInputGroup
+	ClearInputGroup(groupID SIMCONNECT_INPUT_GROUP_ID) error
+
+	// SetInputGroupState implements SimConnect_SetInputGroupState
+	SetInputGroupState(groupID SIMCONNECT_INPUT_GROUP_ID, state SIMCONNECT_STATE) error
+
+	// RequestReservedKey implements SimConnect_RequestReservedKey
+	RequestReservedKey(eventID SIMCONNECT_CLIENT_EVENT_ID, keyChoice1, keyChoice2, keyChoice3 string) error
+
+	// SubscribeToSystemEvent implements SimConnect_SubscribeToSystemEvent
+	SubscribeToSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, systemEventName string) error
+
+	// UnsubscribeFromSystemEvent implements SimConnect_UnsubscribeFromSystemEvent
+	UnsubscribeFromSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID) error
+
+	// SetSystemEventState implements SimConnect_SetSystemEventState
+	SetSystemEventState(eventID SIMCONNECT_CLIENT_EVENT_ID, state SIMCONNECT_STATE) error
+
+	// EnumerateInputEvents implements SimConnect_EnumerateInputEvents (MSFS 2024 only)
+	EnumerateInputEvents(requestID DataRequestID) error
+
+	// GetInputEvent implements SimConnect_GetInputEvent (MSFS 2024 only)
+	GetInputEvent(requestID DataRequestID, hash uint64) error
+
+	// SetInputEvent implements SimConnect_SetInputEvent (MSFS 2024 only); value holds
+	// a little-endian float64 or a NUL-terminated string
+	SetInputEvent(hash uint64, value []byte) error
+
+	// SubscribeInputEvent implements SimConnect_SubscribeInputEvent (MSFS 2024 only)
+	SubscribeInputEvent(hash uint64) error
+
+	// UnsubscribeInputEvent implements SimConnect_UnsubscribeInputEvent (MSFS 2024 only)
+	UnsubscribeInputEvent(hash uint64) error
+
+	// EnumerateInputEventParams implements SimConnect_EnumerateInputEventParams (MSFS 2024 only)
+	EnumerateInputEventParams(hash uint64) error
+
+	// GetLastSentPacketID implements SimConnect_GetLastSentPacketID and returns the
+	// packet ID of the last successful call, used to match SIMCONNECT_RECV_EXCEPTION.DwSendID
+	GetLastSentPacketID() (uint32, error)
+
+	// GetNextDispatch implements SimConnect_GetNextDispatch and returns a copy of
+	// the next message, or nil if the queue is empty
+	GetNextDispatch() ([]byte, error)
+}
+
+// MessageWaiter is implemented by transports that can signal incoming messages.
+// Client.WaitForMessage, and with it the Dispatcher, blocks on it instead of polling.
+// The SimConnect.dll backend waits on the event handle passed to SimConnect_Open,
+// the network backend on its socket reader.
+type MessageWaiter interface {
+	// WaitForMessage blocks until a message may be available, the connection ends or ctx
+	// is done. Spurious wake-ups are allowed.
+	WaitForMessage(ctx context.Context) error
+}
@@ -0,0 +1,336 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// TransportCall records a single SimConnect call made through a MemoryTransport
+type TransportCall struct {
+	Function string        // SimConnect function name, e.g. "SimConnect_AddToDataDefinition"
+	Args     []interface{} // Arguments in SimConnect parameter order (after the handle)
+	SendID   uint32        // Packet ID assigned to the call, 0 if it failed
+}
+
+// TransportResponder produces the messages the fake server sends in reaction to a call
+type TransportResponder func(call TransportCall) [][]byte
+
+// MemoryTransport is an in-memory Transport for tests that run without the simulator.
+// It records every call, returns scripted dispatch messages in order and can be told
+// to fail specific functions.
+type MemoryTransport struct {
+	mutex     sync.Mutex
+	open      bool               // Whether Open has been called without a matching Close
+	calls     []TransportCall    // Every call made, in order
+	queue     [][]byte           // Messages waiting to be dispatched
+	failures  map[string]error   // Errors to return from specific functions
+	responder TransportResponder // Optional reaction to calls
+	sendID    uint32             // Packet ID of the last successful call
+	wake      chan struct{}      // Signals WaitForMessage that messages or failures were queued
+}
+
+// NewMemoryTransport creates an empty in-memory transport
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{
+		failures: make(map[string]error),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Push queues raw SimConnect messages to be returned by GetNextDispatch
+func (t *MemoryTransport) Push(messages ...[]byte) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, message := range messages {
+		t.queue = append(t.queue, append([]byte(nil), message...))
+	}
+	t.signal()
+}
+
+// SetResponder installs a function whose returned messages are queued after each call
+func (t *MemoryTransport) SetResponder(responder TransportResponder) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.responder = responder
+}
+
+// Fail makes every subsequent call to function return err; a nil err clears the failure
+func (t *MemoryTransport) Fail(function string, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if err == nil {
+		delete(t.failures, function)
+		return
+	}
+	t.failures[function] = err
+	t.signal() // Let a waiting dispatcher observe GetNextDispatch failures
+}
+
+// Calls returns a copy of all recorded calls
+func (t *MemoryTransport) Calls() []TransportCall {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	calls := make([]TransportCall, len(t.calls))
+	copy(calls, t.calls)
+	return calls
+}
+
+// CallsTo returns the recorded calls to a single SimConnect function
+func (t *MemoryTransport) CallsTo(function string) []TransportCall {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var calls []TransportCall
+	for _, call := range t.calls {
+		if call.Function == function {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Pending returns the number of queued messages not yet dispatched
+func (t *MemoryTransport) Pending() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return len(t.queue)
+}
+
+// record stores a call, applies injected failures and queues the responder's messages
+func (t *MemoryTransport) record(function string, args ...interface{}) error {
+	t.mutex.Lock()
+	call := TransportCall{Function: function, Args: args}
+
+	if err, exists := t.failures[function]; exists {
+		t.calls = append(t.calls, call)
+		t.mutex.Unlock()
+		return err
+	}
+
+	if !t.open && function != "SimConnect_Open" {
+		t.calls = append(t.calls, call)
+		t.mutex.Unlock()
+		return NewSimConnectError(function, E_FAIL, "connection is not open")
+	}
+
+	// Packet IDs restart with every connection, like the real server
+	if function == "SimConnect_Open" {
+		t.sendID = 0
+	}
+	t.sendID++
+	call.SendID = t.sendID
+	t.calls = append(t.calls, call)
+
+	responder := t.responder
+	t.mutex.Unlock()
+
+	// Run the responder outside the lock so it may call Push
+	if responder != nil {
+		t.Push(responder(call)...)
+	}
+
+	return nil
+}
+
+func (t *MemoryTransport) Open(name string) error {
+	t.mutex.Lock()
+	if t.open {
+		t.mutex.Unlock()
+		return NewSimConnectError("SimConnect_Open", E_FAIL, "connection is already open")
+	}
+	t.open = true
+	t.mutex.Unlock()
+
+	if err := t.record("SimConnect_Open", name); err != nil {
+		t.mutex.Lock()
+		t.open = false
+		t.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (t *MemoryTransport) Close() error {
+	if err := t.record("SimConnect_Close"); err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	t.open = false
+	t.queue = nil
+	t.signal()
+	t.mutex.Unlock()
+	return nil
+}
+
+func (t *MemoryTransport) AddToDataDefinition(defineID DataDefinitionID, datumName, unitsName string, datumType SIMCONNECT_DATATYPE, epsilon float32, datumID uint32) error {
+	return t.record("SimConnect_AddToDataDefinition", defineID, datumName, unitsName, datumType, epsilon, datumID)
+}
+
+func (t *MemoryTransport) ClearDataDefinition(defineID DataDefinitionID) error {
+	return t.record("SimConnect_ClearDataDefinition", defineID)
+}
+
+func (t *MemoryTransport) RequestDataOnSimObject(requestID SimObjectDataRequestID, defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, period SIMCONNECT_PERIOD, flags SIMCONNECT_DATA_REQUEST_FLAG, origin, interval, limit uint32) error {
+	return t.record("SimConnect_RequestDataOnSimObject", requestID, defineID, objectID, period, flags, origin, interval, limit)
+}
+
+func (t *MemoryTransport) SetDataOnSimObject(defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, flags SIMCONNECT_DATA_SET_FLAG, arrayCount, unitSize uint32, data []byte) error {
+	if uint64(len(data)) < uint64(arrayCount)*uint64(unitSize) {
+		return NewSimConnectError("SimConnect_SetDataOnSimObject", E_INVALIDARG, fmt.Sprintf("data block of %d bytes is shorter than %d x %d", len(data), arrayCount, unitSize))
+	}
+	return t.record("SimConnect_SetDataOnSimObject", defineID, objectID, flags, arrayCount, unitSize, append([]byte(nil), data...))
+}
+
+func (t *MemoryTransport) RequestSystemState(requestID DataRequestID, state string) error {
+	return t.record("SimConnect_RequestSystemState", requestID, state)
+}
+
+func (t *MemoryTransport) MapClientEventToSimEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, eventName string) error {
+	return t.record("SimConnect_MapClientEventToSimEvent", eventID, eventName)
+}
+
+func (t *MemoryTransport) TransmitClientEvent(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, data uint32, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG) error {
+	return t.record("SimConnect_TransmitClientEvent", objectID, eventID, data, groupID, flags)
+}
+
+func (t *MemoryTransport) TransmitClientEvent_EX1(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG, data [5]uint32) error {
+	return t.record("SimConnect_TransmitClientEvent_EX1", objectID, eventID, groupID, flags, data)
+}
+
+func (t *MemoryTransport) AddClientEventToNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, maskable bool) error {
+	return t.record("SimConnect_AddClientEventToNotificationGroup", groupID, eventID, maskable)
+}
+
+func (t *MemoryTransport) RemoveClientEvent(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	return t.record("SimConnect_RemoveClientEvent", groupID, eventID)
+}
+
+func (t *MemoryTransport) SetNotificationGroupPriority(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error {
+	return t.record("SimConnect_SetNotificationGroupPriority", groupID, priority)
+}
+
+func (t *MemoryTransport) ClearNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID) error {
+	return t.record("SimConnect_ClearNotificationGroup", groupID)
+}
+
+func (t *MemoryTransport) MapInputEventToClientEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string, downEventID SIMCONNECT_CLIENT_EVENT_ID, downValue uint32, upEventID SIMCONNECT_CLIENT_EVENT_ID, upValue uint32, maskable bool) error {
+	return t.record("SimConnect_MapInputEventToClientEvent", groupID, inputDefinition, downEventID, downValue, upEventID, upValue, maskable)
+}
+
+func (t *MemoryTransport) SetInputGroupPriority(groupID SIMCONNECT_INPUT_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error {
+	return t.record("SimConnect_SetInputGroupPriority", groupID, priority)
+}
+
+func (t *MemoryTransport) RemoveInputEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string) error {
+	return t.record("SimConnect_RemoveInputEvent", groupID, inputDefinition)
+}
+
+func (t *MemoryTransport) ClearInputGroup(groupID SIMCONNECT_INPUT_GROUP_ID) error {
+	return t.record("SimConnect_ClearInputGroup", groupID)
+}
+
+func (t *MemoryTransport) SetInputGroupState(groupID SIMCONNECT_INPUT_GROUP_ID, state SIMCONNECT_STATE) error {
+	return t.record("SimConnect_SetInputGroupState", groupID, state)
+}
+
+func (t *MemoryTransport) RequestReservedKey(eventID SIMCONNECT_CLIENT_EVENT_ID, keyChoice1, keyChoice2, keyChoice3 string) error {
+	return t.record("SimConnect_RequestReservedKey", eventID, keyChoice1, keyChoice2, keyChoice3)
+}
+
+func (t *MemoryTransport) SubscribeToSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, systemEventName string) error {
+	return t.record("SimConnect_SubscribeToSystemEvent", eventID, systemEventName)
+}
+
+func (t *MemoryTransport) UnsubscribeFromSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	return t.record("SimConnect_UnsubscribeFromSystemEvent", eventID)
+}
+
+func (t *MemoryTransport) SetSystemEventState(eventID SIMCONNECT_CLIENT_EVENT_ID, state SIMCONNECT_STATE) error {
+	return t.record("SimConnect_SetSystemEventState", eventID, state)
+}
+
+func (t *MemoryTransport) EnumerateInputEvents(requestID DataRequestID) error {
+	return t.record("SimConnect_EnumerateInputEvents", requestID)
+}
+
+func (t *MemoryTransport) GetInputEvent(requestID DataRequestID, hash uint64) error {
+	return t.record("SimConnect_GetInputEvent", requestID, hash)
+}
+
+func (t *MemoryTransport) SetInputEvent(hash uint64, value []byte) error {
+	return t.record("SimConnect_SetInputEvent", hash, value)
+}
+
+func (t *MemoryTransport) SubscribeInputEvent(hash uint64) error {
+	return t.record("SimConnect_SubscribeInputEvent", hash)
+}
+
+func (t *MemoryTransport) UnsubscribeInputEvent(hash uint64) error {
+	return t.record("SimConnect_UnsubscribeInputEvent", hash)
+}
+
+func (t *MemoryTransport) EnumerateInputEventParams(hash uint64) error {
+	return t.record("SimConnect_EnumerateInputEventParams", hash)
+}
+
+// GetLastSentPacketID returns the send ID of the last successful call
+func (t *MemoryTransport) GetLastSentPacketID() (uint32, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if err, exists := t.failures["SimConnect_GetLastSentPacketID"]; exists {
+		return 0, err
+	}
+	return t.sendID, nil
+}
+
+// WaitForMessage blocks until messages are queued, a failure is injected or ctx is done
+func (t *MemoryTransport) WaitForMessage(ctx context.Context) error {
+	t.mutex.Lock()
+	ready := len(t.queue) > 0
+	t.mutex.Unlock()
+
+	if ready {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.wake:
+		return nil
+	}
+}
+
+// signal wakes up WaitForMessage without blocking; the caller holds the mutex
+func (t *MemoryTransport) signal() {
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// GetNextDispatch returns the next queued message without recording a call,
+// as dispatch polling would otherwise flood the call log
+func (t *MemoryTransport) GetNextDispatch() ([]byte, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if err, exists := t.failures["SimConnect_GetNextDispatch"]; exists {
+		return nil, err
+	}
+
+	if len(t.queue) == 0 {
+		return nil, nil
+	}
+
+	data := t.queue[0]
+	t.queue[0] = nil
+	t.queue = t.queue[1:]
+	return data, nil
+}
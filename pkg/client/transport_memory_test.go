@@ -0,0 +1,110 @@
+package client_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+)
+
+// eventMessage builds a SIMCONNECT_RECV_EVENT message
+func eventMessage(eventID, data uint32) []byte {
+	message := make([]byte, 24)
+	binary.LittleEndian.PutUint32(message[0:], uint32(len(message)))
+	binary.LittleEndian.PutUint32(message[4:], 6)
+	binary.LittleEndian.PutUint32(message[8:], uint32(client.SIMCONNECT_RECV_ID_EVENT))
+	binary.LittleEndian.PutUint32(message[12:], 0xFFFFFFFF)
+	binary.LittleEndian.PutUint32(message[16:], eventID)
+	binary.LittleEndian.PutUint32(message[20:], data)
+	return message
+}
+
+func TestMemoryTransportRecordsCalls(t *testing.T) {
+	transport := client.NewMemoryTransport()
+	simClient := client.NewClientWithTransport("test", transport)
+	if err := simClient.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer simClient.Close()
+
+	if err := simClient.AddToDataDefinition(1, "PLANE ALTITUDE", "feet", client.SIMCONNECT_DATATYPE_FLOAT64); err != nil {
+		t.Fatalf("AddToDataDefinition: %v", err)
+	}
+	if err := simClient.RequestDataOnSimObject(2, 1, client.SIMCONNECT_OBJECT_ID_USER, client.SIMCONNECT_PERIOD_SECOND); err != nil {
+		t.Fatalf("RequestDataOnSimObject: %v", err)
+	}
+
+	calls := transport.CallsTo("SimConnect_AddToDataDefinition")
+	if len(calls) != 1 {
+		t.Fatalf("%d AddToDataDefinition calls, want 1", len(calls))
+	}
+	want := []interface{}{client.DataDefinitionID(1), "PLANE ALTITUDE", "feet", client.SIMCONNECT_DATATYPE_FLOAT64, float32(0), uint32(0)}
+	if !reflect.DeepEqual(calls[0].Args, want) {
+		t.Errorf("AddToDataDefinition args %v, want %v", calls[0].Args, want)
+	}
+
+	// Packet IDs count the successful calls of the connection, Open included
+	var sendIDs []uint32
+	for _, call := range transport.Calls() {
+		sendIDs = append(sendIDs, call.SendID)
+	}
+	if want := []uint32{1, 2, 3}; !reflect.DeepEqual(sendIDs, want) {
+		t.Errorf("send IDs %v, want %v", sendIDs, want)
+	}
+
+	// Injected failures are returned until cleared
+	refused := errors.New("refused")
+	transport.Fail("SimConnect_SubscribeToSystemEvent", refused)
+	if err := simClient.SubscribeToSystemEvent(1, "Pause"); !errors.Is(err, refused) {
+		t.Errorf("SubscribeToSystemEvent error %v, want %v", err, refused)
+	}
+	transport.Fail("SimConnect_SubscribeToSystemEvent", nil)
+	if err := simClient.SubscribeToSystemEvent(1, "Pause"); err != nil {
+		t.Errorf("SubscribeToSystemEvent after clearing the failure: %v", err)
+	}
+}
+
+func TestMemoryTransportDispatchesMessagesInOrder(t *testing.T) {
+	transport := client.NewMemoryTransport()
+	simClient := client.NewClientWithTransport("test", transport)
+	if err := simClient.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer simClient.Close()
+
+	// The responder's messages are queued after the pushed ones
+	transport.SetResponder(func(call client.TransportCall) [][]byte {
+		if call.Function != "SimConnect_SubscribeToSystemEvent" {
+			return nil
+		}
+		return [][]byte{eventMessage(uint32(call.Args[0].(client.SIMCONNECT_CLIENT_EVENT_ID)), 1)}
+	})
+	transport.Push(eventMessage(7, 0), eventMessage(8, 0))
+	if err := simClient.SubscribeToSystemEvent(9, "Pause"); err != nil {
+		t.Fatalf("SubscribeToSystemEvent: %v", err)
+	}
+	if pending := transport.Pending(); pending != 3 {
+		t.Errorf("%d pending messages, want 3", pending)
+	}
+
+	var eventIDs []uint32
+	for {
+		data, err := simClient.GetRawDispatch()
+		if err != nil {
+			t.Fatalf("GetRawDispatch: %v", err)
+		}
+		if data == nil {
+			break
+		}
+		event, err := client.ParseEvent(data)
+		if err != nil {
+			t.Fatalf("ParseEvent: %v", err)
+		}
+		eventIDs = append(eventIDs, event.EventID)
+	}
+	if want := []uint32{7, 8, 9}; !reflect.DeepEqual(eventIDs, want) {
+		t.Errorf("dispatched events %v, want %v", eventIDs, want)
+	}
+}
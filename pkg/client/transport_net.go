@@ -0,0 +1,517 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+// SimConnect wire protocol constants
+// Every packet starts with a 16 byte header (size, protocol version, packet type, send ID)
+// followed by the arguments of the call, little-endian, with strings in fixed-size NUL-padded fields.
+// Messages from the server use the SIMCONNECT_RECV layout with a leading size field.
+const (
+	netProtocolVersion = 4                  // Protocol version spoken by this client (FSX SP2 / Acceleration, accepted by MSFS)
+	netPacketTypeMask  = uint32(0xF0000000) // Marks a packet as a client call
+	netHeaderSize      = 16                 // dwSize, dwVersion, dwID, dwSendID
+	netStringSize      = 256                // Size of name fields in packets
+	netKeyChoiceSize   = 30                 // Size of RequestReservedKey key choices
+	netMaxMessageSize  = 16 << 20           // Upper bound for a single message from the server
+	netDialTimeout     = 10 * time.Second   // Timeout for establishing the TCP connection
+)
+
+// SimConnect wire protocol packet types
+const (
+	netPacketOpen                       = 0x01
+	netPacketMapClientEventToSimEvent   = 0x04
+	netPacketTransmitClientEvent        = 0x05
+	netPacketSetSystemEventState        = 0x06
+	netPacketAddClientEventToGroup      = 0x07
+	netPacketRemoveClientEvent          = 0x08
+	netPacketSetGroupPriority           = 0x09
+	netPacketClearNotificationGroup     = 0x0A
+	netPacketMapInputEventToClientEvent = 0x11
+	netPacketSetInputGroupPriority      = 0x12
+	netPacketRemoveInputEvent           = 0x13
+	netPacketClearInputGroup            = 0x14
+	netPacketSetInputGroupState         = 0x15
+	netPacketRequestReservedKey         = 0x16
+	netPacketAddToDataDefinition        = 0x0C
+	netPacketClearDataDefinition        = 0x0D
+	netPacketRequestDataOnSimObject     = 0x0E
+	netPacketSetDataOnSimObject         = 0x10
+	netPacketSubscribeToSystemEvent     = 0x17
+	netPacketUnsubscribeFromSystemEvent = 0x18
+	netPacketRequestSystemState         = 0x35
+)
+
+// netTransport speaks the SimConnect binary protocol directly over a stream connection
+type netTransport struct {
+	dial       func() (io.ReadWriteCloser, error) // Opens the underlying connection
+	writeMutex sync.Mutex                         // Serialises packet writes and guards sendID
+	sendID     uint32                             // ID of the last packet sent
+	mutex      sync.Mutex                         // Guards the fields below
+	conn       io.ReadWriteCloser                 // Active connection, nil when closed
+	queue      [][]byte                           // Messages received but not yet dispatched
+	err        error                              // Terminal read error, reported once the queue is drained
+	done       chan struct{}                      // Closed when the reader goroutine exits
+	wake       chan struct{}                      // Signals WaitForMessage that the reader queued a message or failed
+}
+
+// newNetTransport creates a network transport using the given dialer
+func newNetTransport(dial func() (io.ReadWriteCloser, error)) *netTransport {
+	return &netTransport{dial: dial, wake: make(chan struct{}, 1)}
+}
+
+// Open connects to the server and sends the Open packet
+func (t *netTransport) Open(name string) error {
+	conn, err := t.dial()
+	if err != nil {
+		return NewSimConnectError("SimConnect_Open", E_FAIL, fmt.Sprintf("failed to connect: %v", err))
+	}
+
+	t.writeMutex.Lock()
+	t.sendID = 0
+	t.writeMutex.Unlock()
+
+	t.mutex.Lock()
+	t.conn = conn
+	t.queue = nil
+	t.err = nil
+	t.done = make(chan struct{})
+	t.mutex.Unlock()
+
+	go t.readLoop(conn, t.done)
+
+	// Application name, reserved DWORD, alias bytes and the FSX SP2 version numbers
+	p := newNetPacket()
+	p.putString(name, netStringSize)
+	p.putUint32(0)
+	p.putBytes([]byte{0, 'X', 'S', 'F'})
+	p.putUint32(10)    // Major version
+	p.putUint32(0)     // Minor version
+	p.putUint32(61259) // Build major
+	p.putUint32(0)     // Build minor
+	if err := t.send("SimConnect_Open", netPacketOpen, p); err != nil {
+		t.Close()
+		return err
+	}
+
+	return nil
+}
+
+// Close terminates the connection and waits for the reader to exit
+func (t *netTransport) Close() error {
+	t.mutex.Lock()
+	conn, done := t.conn, t.done
+	t.conn = nil
+	t.queue = nil
+	t.mutex.Unlock()
+
+	if conn == nil {
+		return NewSimConnectError("SimConnect_Close", E_FAIL, "connection is not open")
+	}
+
+	err := conn.Close()
+	<-done
+	if err != nil {
+		return NewSimConnectError("SimConnect_Close", E_FAIL, err.Error())
+	}
+	return nil
+}
+
+// AddToDataDefinition sends an AddToDataDefinition packet
+func (t *netTransport) AddToDataDefinition(defineID DataDefinitionID, datumName, unitsName string, datumType SIMCONNECT_DATATYPE, epsilon float32, datumID uint32) error {
+	p := newNetPacket()
+	p.putUint32(uint32(defineID))
+	p.putString(datumName, netStringSize)
+	p.putString(unitsName, netStringSize)
+	p.putUint32(uint32(datumType))
+	p.putUint32(math.Float32bits(epsilon))
+	p.putUint32(datumID)
+	return t.send("SimConnect_AddToDataDefinition", netPacketAddToDataDefinition, p)
+}
+
+// ClearDataDefinition sends a ClearDataDefinition packet
+func (t *netTransport) ClearDataDefinition(defineID DataDefinitionID) error {
+	p := newNetPacket()
+	p.putUint32(uint32(defineID))
+	return t.send("SimConnect_ClearDataDefinition", netPacketClearDataDefinition, p)
+}
+
+// RequestDataOnSimObject sends a RequestDataOnSimObject packet
+func (t *netTransport) RequestDataOnSimObject(requestID SimObjectDataRequestID, defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, period SIMCONNECT_PERIOD, flags SIMCONNECT_DATA_REQUEST_FLAG, origin, interval, limit uint32) error {
+	p := newNetPacket()
+	p.putUint32(uint32(requestID))
+	p.putUint32(uint32(defineID))
+	p.putUint32(uint32(objectID))
+	p.putUint32(uint32(period))
+	p.putUint32(uint32(flags))
+	p.putUint32(origin)
+	p.putUint32(interval)
+	p.putUint32(limit)
+	return t.send("SimConnect_RequestDataOnSimObject", netPacketRequestDataOnSimObject, p)
+}
+
+// SetDataOnSimObject sends a SetDataOnSimObject packet followed by the data block
+func (t *netTransport) SetDataOnSimObject(defineID DataDefinitionID, objectID SIMCONNECT_OBJECT_ID, flags SIMCONNECT_DATA_SET_FLAG, arrayCount, unitSize uint32, data []byte) error {
+	if uint64(len(data)) < uint64(arrayCount)*uint64(unitSize) {
+		return NewSimConnectError("SimConnect_SetDataOnSimObject", E_INVALIDARG, GetHRESULTMessage(E_INVALIDARG))
+	}
+
+	p := newNetPacket()
+	p.putUint32(uint32(defineID))
+	p.putUint32(uint32(objectID))
+	p.putUint32(uint32(flags))
+	p.putUint32(arrayCount)
+	p.putUint32(unitSize)
+	p.putBytes(data[:arrayCount*unitSize])
+	return t.send("SimConnect_SetDataOnSimObject", netPacketSetDataOnSimObject, p)
+}
+
+// RequestSystemState sends a RequestSystemState packet
+func (t *netTransport) RequestSystemState(requestID DataRequestID, state string) error {
+	p := newNetPacket()
+	p.putUint32(uint32(requestID))
+	p.putString(state, netStringSize)
+	return t.send("SimConnect_RequestSystemState", netPacketRequestSystemState, p)
+}
+
+// MapClientEventToSimEvent sends a MapClientEventToSimEvent packet
+func (t *netTransport) MapClientEventToSimEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, eventName string) error {
+	p := newNetPacket()
+	p.putUint32(uint32(eventID))
+	p.putString(eventName, netStringSize)
+	return t.send("SimConnect_MapClientEventToSimEvent", netPacketMapClientEventToSimEvent, p)
+}
+
+// TransmitClientEvent sends a TransmitClientEvent packet
+func (t *netTransport) TransmitClientEvent(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, data uint32, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG) error {
+	p := newNetPacket()
+	p.putUint32(uint32(objectID))
+	p.putUint32(uint32(eventID))
+	p.putUint32(data)
+	p.putUint32(uint32(groupID))
+	p.putUint32(uint32(flags))
+	return t.send("SimConnect_TransmitClientEvent", netPacketTransmitClientEvent, p)
+}
+
+// TransmitClientEvent_EX1 is not part of the protocol version spoken by this transport
+func (t *netTransport) TransmitClientEvent_EX1(objectID SIMCONNECT_OBJECT_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, groupID SIMCONNECT_NOTIFICATION_GROUP_ID, flags SIMCONNECT_EVENT_FLAG, data [5]uint32) error {
+	return NewSimConnectError("SimConnect_TransmitClientEvent_EX1", E_NOTIMPL,
+		fmt.Sprintf("not supported by SimConnect protocol version %d, use TransmitClientEvent", netProtocolVersion))
+}
+
+// AddClientEventToNotificationGroup sends an AddClientEventToNotificationGroup packet
+func (t *netTransport) AddClientEventToNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID, maskable bool) error {
+	p := newNetPacket()
+	p.putUint32(uint32(groupID))
+	p.putUint32(uint32(eventID))
+	p.putBool(maskable)
+	return t.send("SimConnect_AddClientEventToNotificationGroup", netPacketAddClientEventToGroup, p)
+}
+
+// RemoveClientEvent sends a RemoveClientEvent packet
+func (t *netTransport) RemoveClientEvent(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	p := newNetPacket()
+	p.putUint32(uint32(groupID))
+	p.putUint32(uint32(eventID))
+	return t.send("SimConnect_RemoveClientEvent", netPacketRemoveClientEvent, p)
+}
+
+// SetNotificationGroupPriority sends a SetNotificationGroupPriority packet
+func (t *netTransport) SetNotificationGroupPriority(groupID SIMCONNECT_NOTIFICATION_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error {
+	p := newNetPacket()
+	p.putUint32(uint32(groupID))
+	p.putUint32(uint32(priority))
+	return t.send("SimConnect_SetNotificationGroupPriority", netPacketSetGroupPriority, p)
+}
+
+// ClearNotificationGroup sends a ClearNotificationGroup packet
+func (t *netTransport) ClearNotificationGroup(groupID SIMCONNECT_NOTIFICATION_GROUP_ID) error {
+	p := newNetPacket()
+	p.putUint32(uint32(groupID))
+	return t.send("SimConnect_ClearNotificationGroup", netPacketClearNotificationGroup, p)
+}
+
+// MapInputEventToClientEvent sends a MapInputEventToClientEvent packet
+func (t *netTransport) MapInputEventToClientEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string, downEventID SIMCONNECT_CLIENT_EVENT_ID, downValue uint32, upEventID SIMCONNECT_CLIENT_EVENT_ID, upValue uint32, maskable bool) error {
+	p := newNetPacket()
+	p.putUint32(uint32(groupID))
+	p.putString(inputDefinition, netStringSize)
+	p.putUint32(uint32(downEventID))
+	p.putUint32(downValue)
+	p.putUint32(uint32(upEventID))
+	p.putUint32(upValue)
+	p.putBool(maskable)
+	return t.send("SimConnect_MapInputEventToClientEvent", netPacketMapInputEventToClientEvent, p)
+}
+
+// SetInputGroupPriority sends a SetInputGroupPriority packet
+func (t *netTransport) SetInputGroupPriority(groupID SIMCONNECT_INPUT_GROUP_ID, priority SIMCONNECT_GROUP_PRIORITY) error {
+	p := newNetPacket()
+	p.putUint32(uint32(groupID))
+	p.putUint32(uint32(priority))
+	return t.send("SimConnect_SetInputGroupPriority", netPacketSetInputGroupPriority, p)
+}
+
+// RemoveInputEvent sends a RemoveInputEvent packet
+func (t *netTransport) RemoveInputEvent(groupID SIMCONNECT_INPUT_GROUP_ID, inputDefinition string) error {
+	p := newNetPacket()
+	p.putUint32(uint32(groupID))
+	p.putString(inputDefinition, netStringSize)
+	return t.send("SimConnect_RemoveInputEvent", netPacketRemoveInputEvent, p)
+}
+
+// ClearInputGroup sends a ClearInputGroup packet
+func (t *netTransport) ClearInputGroup(groupID SIMCONNECT_INPUT_GROUP_ID) error {
+	p := newNetPacket()
+	p.putUint32(uint32(groupID))
+	return t.send("SimConnect_ClearInputGroup", netPacketClearInputGroup, p)
+}
+
+// SetInputGroupState sends a SetInputGroupState packet
+func (t *netTransport) SetInputGroupState(groupID SIMCONNECT_INPUT_GROUP_ID, state SIMCONNECT_STATE) error {
+	p := newNetPacket()
+	p.putUint32(uint32(groupID))
+	p.putUint32(uint32(state))
+	return t.send("SimConnect_SetInputGroupState", netPacketSetInputGroupState, p)
+}
+
+// RequestReservedKey sends a RequestReservedKey packet
+func (t *netTransport) RequestReservedKey(eventID SIMCONNECT_CLIENT_EVENT_ID, keyChoice1, keyChoice2, keyChoice3 string) error {
+	p := newNetPacket()
+	p.putUint32(uint32(eventID))
+	p.putString(keyChoice1, netKeyChoiceSize)
+	p.putString(keyChoice2, netKeyChoiceSize)
+	p.putString(keyChoice3, netKeyChoiceSize)
+	return t.send("SimConnect_RequestReservedKey", netPacketRequestReservedKey, p)
+}
+
+// SubscribeToSystemEvent sends a SubscribeToSystemEvent packet
+func (t *netTransport) SubscribeToSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID, systemEventName string) error {
+	p := newNetPacket()
+	p.putUint32(uint32(eventID))
+	p.putString(systemEventName, netStringSize)
+	return t.send("SimConnect_SubscribeToSystemEvent", netPacketSubscribeToSystemEvent, p)
+}
+
+// UnsubscribeFromSystemEvent sends an UnsubscribeFromSystemEvent packet
+func (t *netTransport) UnsubscribeFromSystemEvent(eventID SIMCONNECT_CLIENT_EVENT_ID) error {
+	p := newNetPacket()
+	p.putUint32(uint32(eventID))
+	return t.send("SimConnect_UnsubscribeFromSystemEvent", netPacketUnsubscribeFromSystemEvent, p)
+}
+
+// SetSystemEventState sends a SetSystemEventState packet
+func (t *netTransport) SetSystemEventState(eventID SIMCONNECT_CLIENT_EVENT_ID, state SIMCONNECT_STATE) error {
+	p := newNetPacket()
+	p.putUint32(uint32(eventID))
+	p.putUint32(uint32(state))
+	return t.send("SimConnect_SetSystemEventState", netPacketSetSystemEventState, p)
+}
+
+// EnumerateInputEvents is an MSFS 2024 addition, not part of the protocol version spoken by this transport
+func (t *netTransport) EnumerateInputEvents(requestID DataRequestID) error {
+	return t.inputEventsUnsupported("SimConnect_EnumerateInputEvents")
+}
+
+// GetInputEvent is an MSFS 2024 addition, not part of the protocol version spoken by this transport
+func (t *netTransport) GetInputEvent(requestID DataRequestID, hash uint64) error {
+	return t.inputEventsUnsupported("SimConnect_GetInputEvent")
+}
+
+// SetInputEvent is an MSFS 2024 addition, not part of the protocol version spoken by this transport
+func (t *netTransport) SetInputEvent(hash uint64, value []byte) error {
+	return t.inputEventsUnsupported("SimConnect_SetInputEvent")
+}
+
+// SubscribeInputEvent is an MSFS 2024 addition, not part of the protocol version spoken by this transport
+func (t *netTransport) SubscribeInputEvent(hash uint64) error {
+	return t.inputEventsUnsupported("SimConnect_SubscribeInputEvent")
+}
+
+// UnsubscribeInputEvent is an MSFS 2024 addition, not part of the protocol version spoken by this transport
+func (t *netTransport) UnsubscribeInputEvent(hash uint64) error {
+	return t.inputEventsUnsupported("SimConnect_UnsubscribeInputEvent")
+}
+
+// EnumerateInputEventParams is an MSFS 2024 addition, not part of the protocol version spoken by this transport
+func (t *netTransport) EnumerateInputEventParams(hash uint64) error {
+	return t.inputEventsUnsupported("SimConnect_EnumerateInputEventParams")
+}
+
+// inputEventsUnsupported returns the error of all Input Event calls
+func (t *netTransport) inputEventsUnsupported(function string) error {
+	return NewSimConnectError(function, E_NOTIMPL,
+		fmt.Sprintf("not supported by SimConnect protocol version %d, Input Events need the MSFS 2024 SimConnect.dll", netProtocolVersion))
+}
+
+// GetLastSentPacketID returns the send ID of the last packet written to the connection
+func (t *netTransport) GetLastSentPacketID() (uint32, error) {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+	return t.sendID, nil
+}
+
+// GetNextDispatch returns the oldest received message, or nil if none is queued.
+// Once the connection is gone and the queue is drained the read error is returned.
+func (t *netTransport) GetNextDispatch() ([]byte, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if len(t.queue) > 0 {
+		data := t.queue[0]
+		t.queue[0] = nil
+		t.queue = t.queue[1:]
+		return data, nil
+	}
+
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	return nil, nil
+}
+
+// WaitForMessage blocks until the reader queued a message, the connection failed or ctx is done
+func (t *netTransport) WaitForMessage(ctx context.Context) error {
+	t.mutex.Lock()
+	ready := len(t.queue) > 0 || t.err != nil
+	t.mutex.Unlock()
+
+	if ready {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.wake:
+		return nil
+	}
+}
+
+// signal wakes up WaitForMessage without blocking; the caller holds the mutex
+func (t *netTransport) signal() {
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// send fills in the packet header and writes the packet to the connection
+func (t *netTransport) send(function string, packetType uint32, p *netPacket) error {
+	t.mutex.Lock()
+	conn, readErr := t.conn, t.err
+	t.mutex.Unlock()
+
+	if conn == nil {
+		return NewSimConnectError(function, E_FAIL, "connection is not open")
+	}
+	if readErr != nil {
+		return readErr
+	}
+
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	t.sendID++
+	binary.LittleEndian.PutUint32(p.buf[0:], uint32(len(p.buf)))
+	binary.LittleEndian.PutUint32(p.buf[4:], netProtocolVersion)
+	binary.LittleEndian.PutUint32(p.buf[8:], netPacketTypeMask|packetType)
+	binary.LittleEndian.PutUint32(p.buf[12:], t.sendID)
+
+	if _, err := conn.Write(p.buf); err != nil {
+		return NewSimConnectError(function, STATUS_REMOTE_DISCONNECT, fmt.Sprintf("%s: %v", GetHRESULTMessage(STATUS_REMOTE_DISCONNECT), err))
+	}
+
+	return nil
+}
+
+// readLoop reads messages from the connection into the dispatch queue until it fails
+func (t *netTransport) readLoop(conn io.Reader, done chan struct{}) {
+	defer close(done)
+
+	for {
+		data, err := readNetMessage(conn)
+
+		t.mutex.Lock()
+		if err != nil {
+			t.err = NewSimConnectError("SimConnect_GetNextDispatch", STATUS_REMOTE_DISCONNECT,
+				fmt.Sprintf("%s: %v", GetHRESULTMessage(STATUS_REMOTE_DISCONNECT), err))
+			t.signal()
+			t.mutex.Unlock()
+			return
+		}
+		t.queue = append(t.queue, data)
+		t.signal()
+		t.mutex.Unlock()
+	}
+}
+
+// readNetMessage reads a single size-prefixed message
+func readNetMessage(r io.Reader) ([]byte, error) {
+	var sizeBytes [4]byte
+	if _, err := io.ReadFull(r, sizeBytes[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.LittleEndian.Uint32(sizeBytes[:])
+	if size < 12 || size > netMaxMessageSize {
+		return nil, fmt.Errorf("invalid message size %d", size)
+	}
+
+	data := make([]byte, size)
+	copy(data, sizeBytes[:])
+	if _, err := io.ReadFull(r, data[4:]); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// netPacket accumulates the body of an outgoing packet after the reserved header
+type netPacket struct {
+	buf []byte
+}
+
+func newNetPacket() *netPacket {
+	return &netPacket{buf: make([]byte, netHeaderSize, 128)}
+}
+
+func (p *netPacket) putUint32(v uint32) {
+	p.buf = binary.LittleEndian.AppendUint32(p.buf, v)
+}
+
+// putBool writes a Win32 BOOL
+func (p *netPacket) putBool(v bool) {
+	if v {
+		p.putUint32(1)
+	} else {
+		p.putUint32(0)
+	}
+}
+
+func (p *netPacket) putBytes(b []byte) {
+	p.buf = append(p.buf, b...)
+}
+
+// putString writes s into a fixed-size NUL-padded field, truncating it like the C API does
+func (p *netPacket) putString(s string, size int) {
+	field := make([]byte, size)
+	copy(field[:size-1], s)
+	p.buf = append(p.buf, field...)
+}
+
+// dialTCP returns a dialer for a SimConnect server listening on address (host:port)
+func dialTCP(address string) func() (io.ReadWriteCloser, error) {
+	return func() (io.ReadWriteCloser, error) {
+		return net.DialTimeout("tcp", address, netDialTimeout)
+	}
+}
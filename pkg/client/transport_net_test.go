@@ -0,0 +1,126 @@
+package client_test
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// listen returns a loopback listener closed at the end of the test
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	return listener
+}
+
+// openNetworkClient connects a network client to address and waits for the simulator's confirmation
+func openNetworkClient(t *testing.T, address string) *client.Client {
+	t.Helper()
+	simClient := client.NewNetworkClient("loopback", address)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := simClient.OpenContext(ctx); err != nil {
+		t.Fatalf("OpenContext: %v", err)
+	}
+	t.Cleanup(func() {
+		if simClient.State() != client.StateDisconnected {
+			simClient.Close()
+		}
+	})
+	return simClient
+}
+
+func TestNetworkClientLoopback(t *testing.T) {
+	server := simtest.NewServer()
+	server.SetSimVar("PLANE ALTITUDE", 1500.0)
+	listener := listen(t)
+	go server.Serve(listener)
+
+	// Open handshake
+	simClient := openNetworkClient(t, listener.Addr().String())
+	if name := server.ClientName(); name != "loopback" {
+		t.Errorf("server saw application %q, want loopback", name)
+	}
+
+	dispatcher := simClient.Dispatcher()
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	// Data request round trip
+	defineID := simClient.IDs().NewDefinitionID()
+	requestID := simClient.IDs().NewRequestID()
+	received := make(chan []byte, 1)
+	dispatcher.HandleRequest(uint32(requestID), func(data []byte) { received <- data })
+
+	if err := simClient.AddToDataDefinition(defineID, "PLANE ALTITUDE", "feet", client.SIMCONNECT_DATATYPE_FLOAT64); err != nil {
+		t.Fatalf("AddToDataDefinition: %v", err)
+	}
+	if err := simClient.RequestDataOnSimObject(requestID, defineID, client.SIMCONNECT_OBJECT_ID_USER, client.SIMCONNECT_PERIOD_ONCE); err != nil {
+		t.Fatalf("RequestDataOnSimObject: %v", err)
+	}
+	server.Step(1)
+
+	recv, block, err := client.ParseSimObjectData(receiveMessage(t, received))
+	if err != nil {
+		t.Fatalf("ParseSimObjectData: %v", err)
+	}
+	if recv.DwDefineID != uint32(defineID) || len(block) != 8 {
+		t.Fatalf("data of definition %d with %d bytes, want definition %d with 8", recv.DwDefineID, len(block), defineID)
+	}
+	if altitude := math.Float64frombits(binary.LittleEndian.Uint64(block)); altitude != 1500 {
+		t.Errorf("altitude %v, want 1500", altitude)
+	}
+
+	// QUIT ends the connection; the client is released with Close
+	server.Quit()
+	waitFor(t, "quit", func() bool { return simClient.State() == client.StateQuitting })
+	if err := simClient.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	waitFor(t, "server to see the connection end", func() bool { return !server.IsOpen() })
+}
+
+func TestNetworkClientRemoteDisconnect(t *testing.T) {
+	server := simtest.NewServer()
+	listener := listen(t)
+
+	// Serve a single connection that can be dropped from the server side
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		accepted <- conn
+		server.ServeConn(conn)
+	}()
+
+	simClient := openNetworkClient(t, listener.Addr().String())
+	simClient.Dispatcher().Start()
+	defer simClient.Dispatcher().Stop()
+
+	conn := <-accepted
+	conn.Close()
+	waitFor(t, "lost connection", func() bool { return simClient.State() == client.StateLost })
+
+	if err := simClient.SubscribeToSystemEvent(1, client.SystemEventPause); err == nil {
+		t.Error("call on a lost connection succeeded")
+	}
+	if err := simClient.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if state := simClient.State(); state != client.StateDisconnected {
+		t.Errorf("state %s after Close, want %s", state, client.StateDisconnected)
+	}
+}
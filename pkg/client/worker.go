@@ -0,0 +1,107 @@
+package client
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// worker runs functions one at a time on a goroutine locked to its OS thread.
+// SimConnect handles are not safe for concurrent use and the DLL expects to be
+// called from one thread, so every transport call of a Client goes through its worker.
+// Open starts the worker and Close ends it; calls in between fail with a not-open error.
+type worker struct {
+	mutex sync.RWMutex  // Guards calls; held for reading while a call is handed over
+	calls chan func()   // Queue of the running worker, nil when stopped
+	done  chan struct{} // Closed when the running worker exits
+}
+
+// callPanic carries a panic of a worker call to the calling goroutine
+type callPanic struct {
+	value interface{} // Value passed to panic
+	stack []byte      // Stack of the worker goroutine when it panicked
+}
+
+func (p *callPanic) Error() string {
+	return fmt.Sprintf("client: SimConnect call panicked: %v\n\n%s", p.value, p.stack)
+}
+
+// Unwrap returns the panic value if it is an error
+func (p *callPanic) Unwrap() error {
+	err, _ := p.value.(error)
+	return err
+}
+
+// call runs fn on the worker goroutine and returns its result.
+// A panic in fn is re-raised on the calling goroutine as a *callPanic holding the
+// value and the worker's stack. Without a running worker call fails.
+func (w *worker) call(fn func() error) error {
+	var err error
+	var panicked *callPanic
+	finished := make(chan struct{})
+
+	job := func() {
+		defer close(finished)
+		defer func() {
+			if value := recover(); value != nil {
+				panicked = &callPanic{value: value, stack: debug.Stack()}
+			}
+		}()
+		err = fn()
+	}
+
+	w.mutex.RLock()
+	if w.calls == nil {
+		w.mutex.RUnlock()
+		return fmt.Errorf("client is not open")
+	}
+	w.calls <- job
+	w.mutex.RUnlock()
+
+	<-finished
+	if panicked != nil {
+		panic(panicked)
+	}
+	return err
+}
+
+// start launches the worker goroutine unless it is already running
+func (w *worker) start() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.calls != nil {
+		return
+	}
+
+	w.calls = make(chan func())
+	w.done = make(chan struct{})
+	go w.run(w.calls, w.done)
+}
+
+// stop ends the worker goroutine after the queued calls; later calls fail until start
+func (w *worker) stop() {
+	w.mutex.Lock()
+	calls, done := w.calls, w.done
+	w.calls = nil
+	w.mutex.Unlock()
+
+	if calls == nil {
+		return
+	}
+	close(calls)
+	<-done
+}
+
+// run executes calls on a locked OS thread until the queue is closed
+func (w *worker) run(calls chan func(), done chan struct{}) {
+	defer close(done)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	for job := range calls {
+		job()
+	}
+}
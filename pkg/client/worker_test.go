@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWorkerCallsFailWhileStopped(t *testing.T) {
+	var w worker
+	called := false
+	if err := w.call(func() error { called = true; return nil }); err == nil {
+		t.Error("call succeeded before start")
+	}
+
+	w.start()
+	if err := w.call(func() error { called = true; return nil }); err != nil {
+		t.Errorf("call after start: %v", err)
+	}
+	if !called {
+		t.Error("call after start did not run")
+	}
+
+	w.stop()
+	called = false
+	if err := w.call(func() error { called = true; return nil }); err == nil {
+		t.Error("call succeeded after stop")
+	}
+	if called {
+		t.Error("call ran after stop")
+	}
+}
+
+func TestWorkerRepanicsWithValueAndStack(t *testing.T) {
+	var w worker
+	w.start()
+	defer w.stop()
+
+	cause := errors.New("transport bug")
+	recovered := func() (value interface{}) {
+		defer func() { value = recover() }()
+		w.call(func() error { panic(cause) })
+		return nil
+	}()
+
+	panicked, ok := recovered.(*callPanic)
+	if !ok {
+		t.Fatalf("recovered %T %v, want *callPanic", recovered, recovered)
+	}
+	if panicked.value != cause || !errors.Is(panicked, cause) {
+		t.Errorf("panic value %v, want %v", panicked.value, cause)
+	}
+	if !strings.Contains(string(panicked.stack), "TestWorkerRepanicsWithValueAndStack") {
+		t.Errorf("stack does not show the panicking call:\n%s", panicked.stack)
+	}
+
+	// The worker keeps serving calls after a panic
+	if err := w.call(func() error { return nil }); err != nil {
+		t.Errorf("call after panic: %v", err)
+	}
+}
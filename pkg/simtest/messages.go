@@ -0,0 +1,214 @@
+package simtest
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+)
+
+// serverVersion is reported in the dwVersion field of every message
+const serverVersion = 4
+
+// message accumulates a SIMCONNECT_RECV message body after the header
+type message struct {
+	buf []byte
+}
+
+// newMessage starts a message of the given SIMCONNECT_RECV_ID
+func newMessage(recvID uint32) *message {
+	m := &message{buf: make([]byte, 12, 64)}
+	binary.LittleEndian.PutUint32(m.buf[4:], serverVersion)
+	binary.LittleEndian.PutUint32(m.buf[8:], recvID)
+	return m
+}
+
+func (m *message) putUint32(v uint32) *message {
+	m.buf = binary.LittleEndian.AppendUint32(m.buf, v)
+	return m
+}
+
+func (m *message) putUint64(v uint64) *message {
+	m.buf = binary.LittleEndian.AppendUint64(m.buf, v)
+	return m
+}
+
+func (m *message) putFloat32(v float32) *message {
+	return m.putUint32(math.Float32bits(v))
+}
+
+func (m *message) putFloat64(v float64) *message {
+	return m.putUint64(math.Float64bits(v))
+}
+
+func (m *message) putBytes(b []byte) *message {
+	m.buf = append(m.buf, b...)
+	return m
+}
+
+// putString writes s into a fixed-size NUL-padded field
+func (m *message) putString(s string, size int) *message {
+	field := make([]byte, size)
+	copy(field[:size-1], s)
+	return m.putBytes(field)
+}
+
+// bytes finalises the dwSize field and returns the encoded message
+func (m *message) bytes() []byte {
+	binary.LittleEndian.PutUint32(m.buf[0:], uint32(len(m.buf)))
+	return m.buf
+}
+
+// EncodeOpen builds a SIMCONNECT_RECV_OPEN message
+func EncodeOpen(applicationName string) []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_OPEN).
+		putString(applicationName, 256).
+		putUint32(11).putUint32(0).putUint32(0).putUint32(0). // Application version and build
+		putUint32(11).putUint32(0).putUint32(0).putUint32(0). // SimConnect version and build
+		putUint32(0).putUint32(0).                            // Reserved
+		bytes()
+}
+
+// EncodeQuit builds a SIMCONNECT_RECV_QUIT message
+func EncodeQuit() []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_QUIT).bytes()
+}
+
+// EncodeException builds a SIMCONNECT_RECV_EXCEPTION message
+func EncodeException(exception, sendID, index uint32) []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_EXCEPTION).
+		putUint32(exception).
+		putUint32(sendID).
+		putUint32(index).
+		bytes()
+}
+
+// EncodeEvent builds a SIMCONNECT_RECV_EVENT message
+func EncodeEvent(groupID uint32, eventID client.SIMCONNECT_CLIENT_EVENT_ID, data uint32) []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_EVENT).
+		putUint32(groupID).
+		putUint32(uint32(eventID)).
+		putUint32(data).
+		bytes()
+}
+
+// EncodeEventEx1 builds a SIMCONNECT_RECV_EVENT_EX1 message
+func EncodeEventEx1(groupID uint32, eventID client.SIMCONNECT_CLIENT_EVENT_ID, data [5]uint32) []byte {
+	m := newMessage(client.SIMCONNECT_RECV_ID_EVENT_EX1).
+		putUint32(groupID).
+		putUint32(uint32(eventID))
+	for _, value := range data {
+		m.putUint32(value)
+	}
+	return m.bytes()
+}
+
+// EncodeReservedKey builds a SIMCONNECT_RECV_RESERVED_KEY message
+func EncodeReservedKey(choiceReserved, reservedKey string) []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_RESERVED_KEY).
+		putString(choiceReserved, 30).
+		putString(reservedKey, 50).
+		bytes()
+}
+
+// EncodeEventFilename builds a SIMCONNECT_RECV_EVENT_FILENAME message
+func EncodeEventFilename(eventID client.SIMCONNECT_CLIENT_EVENT_ID, data uint32, filename string) []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_EVENT_FILENAME).
+		putUint32(0).
+		putUint32(uint32(eventID)).
+		putUint32(data).
+		putString(filename, client.MAX_PATH).
+		putUint32(0). // dwFlags
+		bytes()
+}
+
+// EncodeEventObjectAddRemove builds a SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE message
+// The object ID travels in dwData, followed by the SIMCONNECT_SIMOBJECT_TYPE of the object
+func EncodeEventObjectAddRemove(eventID client.SIMCONNECT_CLIENT_EVENT_ID, objectID client.SIMCONNECT_OBJECT_ID, objectType uint32) []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE).
+		putUint32(0).
+		putUint32(uint32(eventID)).
+		putUint32(uint32(objectID)).
+		putUint32(objectType).
+		bytes()
+}
+
+// EncodeEventFrame builds a SIMCONNECT_RECV_EVENT_FRAME message
+func EncodeEventFrame(eventID client.SIMCONNECT_CLIENT_EVENT_ID, frameRate, simSpeed float32) []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_EVENT_FRAME).
+		putUint32(0).
+		putUint32(uint32(eventID)).
+		putUint32(0).
+		putFloat32(frameRate).
+		putFloat32(simSpeed).
+		bytes()
+}
+
+// EncodeSystemState builds a SIMCONNECT_RECV_SYSTEM_STATE message
+func EncodeSystemState(requestID client.DataRequestID, integer uint32, float float32, str string) []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_SYSTEM_STATE).
+		putUint32(uint32(requestID)).
+		putUint32(integer).
+		putFloat32(float).
+		putString(str, client.MAX_PATH).
+		bytes()
+}
+
+// EncodeSimObjectData builds a SIMCONNECT_RECV_SIMOBJECT_DATA message around an encoded data block
+func EncodeSimObjectData(requestID client.SimObjectDataRequestID, objectID client.SIMCONNECT_OBJECT_ID, defineID client.DataDefinitionID, flags client.SIMCONNECT_DATA_REQUEST_FLAG, defineCount uint32, data []byte) []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_SIMOBJECT_DATA).
+		putUint32(uint32(requestID)).
+		putUint32(uint32(objectID)).
+		putUint32(uint32(defineID)).
+		putUint32(uint32(flags)).
+		putUint32(1). // dwentrynumber
+		putUint32(1). // dwoutof
+		putUint32(defineCount).
+		putBytes(data).
+		bytes()
+}
+
+// EncodeEnumerateInputEvents builds one SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS message of a
+// possibly multi-part answer; entryNumber counts from 0 up to outOf-1
+func EncodeEnumerateInputEvents(requestID client.DataRequestID, entryNumber, outOf uint32, events []client.SIMCONNECT_INPUT_EVENT_DESCRIPTOR) []byte {
+	m := newMessage(client.SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS).
+		putUint32(uint32(requestID)).
+		putUint32(uint32(len(events))).
+		putUint32(entryNumber).
+		putUint32(outOf)
+	for _, event := range events {
+		m.putString(event.Name, 64).
+			putUint64(event.Hash).
+			putUint32(uint32(event.Type))
+	}
+	return m.bytes()
+}
+
+// EncodeGetInputEvent builds a SIMCONNECT_RECV_GET_INPUT_EVENT message; value is a float64 or a string
+func EncodeGetInputEvent(requestID client.DataRequestID, value interface{}) []byte {
+	m := newMessage(client.SIMCONNECT_RECV_ID_GET_INPUT_EVENT).putUint32(uint32(requestID))
+	return putInputEventValue(m, value).bytes()
+}
+
+// EncodeSubscribeInputEvent builds a SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT message; value is a float64 or a string
+func EncodeSubscribeInputEvent(hash uint64, value interface{}) []byte {
+	m := newMessage(client.SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT).putUint64(hash)
+	return putInputEventValue(m, value).bytes()
+}
+
+// EncodeEnumerateInputEventParams builds a SIMCONNECT_RECV_ENUMERATE_INPUT_EVENT_PARAMS message
+func EncodeEnumerateInputEventParams(hash uint64, params string) []byte {
+	return newMessage(client.SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS).
+		putUint64(hash).
+		putBytes(append([]byte(params), 0)).
+		bytes()
+}
+
+// putInputEventValue writes the type and value of an input event message
+func putInputEventValue(m *message, value interface{}) *message {
+	if str, ok := value.(string); ok {
+		return m.putUint32(uint32(client.SIMCONNECT_INPUT_EVENT_TYPE_STRING)).putBytes(append([]byte(str), 0))
+	}
+	number, _ := value.(float64)
+	return m.putUint32(uint32(client.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE)).putFloat64(number)
+}
@@ -0,0 +1,231 @@
+package simtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+)
+
+// Wire protocol packet types understood by ServeConn
+const (
+	packetOpen                       = 0x01
+	packetMapClientEventToSimEvent   = 0x04
+	packetTransmitClientEvent        = 0x05
+	packetSetSystemEventState        = 0x06
+	packetAddClientEventToGroup      = 0x07
+	packetRemoveClientEvent          = 0x08
+	packetSetGroupPriority           = 0x09
+	packetClearNotificationGroup     = 0x0A
+	packetMapInputEventToClientEvent = 0x11
+	packetSetInputGroupPriority      = 0x12
+	packetRemoveInputEvent           = 0x13
+	packetClearInputGroup            = 0x14
+	packetSetInputGroupState         = 0x15
+	packetRequestReservedKey         = 0x16
+	packetAddToDataDefinition        = 0x0C
+	packetClearDataDefinition        = 0x0D
+	packetRequestDataOnSimObject     = 0x0E
+	packetSetDataOnSimObject         = 0x10
+	packetSubscribeToSystemEvent     = 0x17
+	packetUnsubscribeFromSystemEvent = 0x18
+	packetRequestSystemState         = 0x35
+)
+
+// maxPacketSize bounds the size of a single client packet
+const maxPacketSize = 1 << 20
+
+// Serve accepts connections from network clients (client.NewNetworkClient) one at a time
+// and serves each with ServeConn until the listener is closed
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		s.ServeConn(conn)
+	}
+}
+
+// ServeConn speaks the SimConnect wire protocol on conn, translating packets into calls
+// on the server and writing queued messages back, until the connection ends
+func (s *Server) ServeConn(conn io.ReadWriteCloser) error {
+	defer conn.Close()
+
+	done := make(chan struct{})
+	writerDone := make(chan struct{})
+	go s.writeLoop(conn, done, writerDone)
+
+	err := s.readLoop(conn)
+
+	close(done)
+	<-writerDone
+
+	if s.IsOpen() {
+		s.Close()
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// readLoop decodes client packets until the connection fails
+func (s *Server) readLoop(conn io.Reader) error {
+	for {
+		var header [16]byte
+		if _, err := io.ReadFull(conn, header[:]); err != nil {
+			return err
+		}
+
+		size := binary.LittleEndian.Uint32(header[0:])
+		if size < 16 || size > maxPacketSize {
+			return fmt.Errorf("invalid packet size %d", size)
+		}
+
+		body := make([]byte, size-16)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return err
+		}
+
+		packetType := binary.LittleEndian.Uint32(header[8:]) &^ 0xF0000000
+		if err := s.handlePacket(packetType, &packetReader{buf: body}); err != nil {
+			return err
+		}
+	}
+}
+
+// handlePacket calls the Transport method matching a packet.
+// Call errors are reported back as exceptions, like the real server does for protocol clients.
+func (s *Server) handlePacket(packetType uint32, r *packetReader) error {
+	var err error
+
+	switch packetType {
+	case packetOpen:
+		err = s.Open(r.string(256))
+	case packetMapClientEventToSimEvent:
+		err = s.MapClientEventToSimEvent(client.SIMCONNECT_CLIENT_EVENT_ID(r.uint32()), r.string(256))
+	case packetTransmitClientEvent:
+		err = s.TransmitClientEvent(client.SIMCONNECT_OBJECT_ID(r.uint32()), client.SIMCONNECT_CLIENT_EVENT_ID(r.uint32()),
+			r.uint32(), client.SIMCONNECT_NOTIFICATION_GROUP_ID(r.uint32()), client.SIMCONNECT_EVENT_FLAG(r.uint32()))
+	case packetSetSystemEventState:
+		err = s.SetSystemEventState(client.SIMCONNECT_CLIENT_EVENT_ID(r.uint32()), client.SIMCONNECT_STATE(r.uint32()))
+	case packetAddClientEventToGroup:
+		err = s.AddClientEventToNotificationGroup(client.SIMCONNECT_NOTIFICATION_GROUP_ID(r.uint32()),
+			client.SIMCONNECT_CLIENT_EVENT_ID(r.uint32()), r.uint32() != 0)
+	case packetRemoveClientEvent:
+		err = s.RemoveClientEvent(client.SIMCONNECT_NOTIFICATION_GROUP_ID(r.uint32()), client.SIMCONNECT_CLIENT_EVENT_ID(r.uint32()))
+	case packetSetGroupPriority:
+		err = s.SetNotificationGroupPriority(client.SIMCONNECT_NOTIFICATION_GROUP_ID(r.uint32()), client.SIMCONNECT_GROUP_PRIORITY(r.uint32()))
+	case packetClearNotificationGroup:
+		err = s.ClearNotificationGroup(client.SIMCONNECT_NOTIFICATION_GROUP_ID(r.uint32()))
+	case packetMapInputEventToClientEvent:
+		groupID, input := client.SIMCONNECT_INPUT_GROUP_ID(r.uint32()), r.string(256)
+		downEventID, downValue := client.SIMCONNECT_CLIENT_EVENT_ID(r.uint32()), r.uint32()
+		upEventID, upValue := client.SIMCONNECT_CLIENT_EVENT_ID(r.uint32()), r.uint32()
+		err = s.MapInputEventToClientEvent(groupID, input, downEventID, downValue, upEventID, upValue, r.uint32() != 0)
+	case packetSetInputGroupPriority:
+		err = s.SetInputGroupPriority(client.SIMCONNECT_INPUT_GROUP_ID(r.uint32()), client.SIMCONNECT_GROUP_PRIORITY(r.uint32()))
+	case packetRemoveInputEvent:
+		err = s.RemoveInputEvent(client.SIMCONNECT_INPUT_GROUP_ID(r.uint32()), r.string(256))
+	case packetClearInputGroup:
+		err = s.ClearInputGroup(client.SIMCONNECT_INPUT_GROUP_ID(r.uint32()))
+	case packetSetInputGroupState:
+		err = s.SetInputGroupState(client.SIMCONNECT_INPUT_GROUP_ID(r.uint32()), client.SIMCONNECT_STATE(r.uint32()))
+	case packetRequestReservedKey:
+		err = s.RequestReservedKey(client.SIMCONNECT_CLIENT_EVENT_ID(r.uint32()), r.string(30), r.string(30), r.string(30))
+	case packetAddToDataDefinition:
+		err = s.AddToDataDefinition(client.DataDefinitionID(r.uint32()), r.string(256), r.string(256),
+			client.SIMCONNECT_DATATYPE(r.uint32()), math.Float32frombits(r.uint32()), r.uint32())
+	case packetClearDataDefinition:
+		err = s.ClearDataDefinition(client.DataDefinitionID(r.uint32()))
+	case packetRequestDataOnSimObject:
+		err = s.RequestDataOnSimObject(client.SimObjectDataRequestID(r.uint32()), client.DataDefinitionID(r.uint32()),
+			client.SIMCONNECT_OBJECT_ID(r.uint32()), client.SIMCONNECT_PERIOD(r.uint32()),
+			client.SIMCONNECT_DATA_REQUEST_FLAG(r.uint32()), r.uint32(), r.uint32(), r.uint32())
+	case packetSetDataOnSimObject:
+		defineID, objectID, flags := client.DataDefinitionID(r.uint32()), client.SIMCONNECT_OBJECT_ID(r.uint32()), client.SIMCONNECT_DATA_SET_FLAG(r.uint32())
+		arrayCount, unitSize := r.uint32(), r.uint32()
+		err = s.SetDataOnSimObject(defineID, objectID, flags, arrayCount, unitSize, r.rest())
+	case packetSubscribeToSystemEvent:
+		err = s.SubscribeToSystemEvent(client.SIMCONNECT_CLIENT_EVENT_ID(r.uint32()), r.string(256))
+	case packetUnsubscribeFromSystemEvent:
+		err = s.UnsubscribeFromSystemEvent(client.SIMCONNECT_CLIENT_EVENT_ID(r.uint32()))
+	case packetRequestSystemState:
+		err = s.RequestSystemState(client.DataRequestID(r.uint32()), r.string(256))
+	default:
+		return fmt.Errorf("unsupported packet type 0x%02X", packetType)
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+	if err != nil {
+		s.InjectException(uint32(client.SIMCONNECT_EXCEPTION_ERROR), s.LastSendID(), 0)
+	}
+	return nil
+}
+
+// writeLoop forwards queued messages to the connection whenever the server signals new data
+func (s *Server) writeLoop(conn io.Writer, done, writerDone chan struct{}) {
+	defer close(writerDone)
+
+	for {
+		for {
+			message, err := s.GetNextDispatch()
+			if err != nil || message == nil {
+				break
+			}
+			if _, err := conn.Write(message); err != nil {
+				return
+			}
+		}
+
+		select {
+		case <-done:
+			return
+		case <-s.notify:
+		}
+	}
+}
+
+// packetReader reads little-endian fields from a packet body and remembers the first error
+type packetReader struct {
+	buf []byte
+	err error
+}
+
+func (r *packetReader) take(n int) []byte {
+	if r.err != nil {
+		return make([]byte, n)
+	}
+	if len(r.buf) < n {
+		r.err = fmt.Errorf("packet truncated: need %d bytes, have %d", n, len(r.buf))
+		return make([]byte, n)
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *packetReader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.take(4))
+}
+
+func (r *packetReader) string(size int) string {
+	field := r.take(size)
+	if end := bytes.IndexByte(field, 0); end >= 0 {
+		field = field[:end]
+	}
+	return string(field)
+}
+
+func (r *packetReader) rest() []byte {
+	b := r.buf
+	r.buf = nil
+	return b
+}
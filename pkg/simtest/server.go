@@ -0,0 +1,1622 @@
+// Package simtest provides an in-process SimConnect server for testing applications
+// built on the client package without a running simulator.
+//
+// A Server implements client.Transport, so a client created with
+// client.NewClientWithTransport (or Server.NewClient) talks to it directly:
+//
+//	server := simtest.NewServer()
+//	server.SetSimVar("PLANE ALTITUDE", 1500.0)
+//	simClient := server.NewClient("Test")
+//
+// Simulation time only advances when Step is called (or a clock started with
+// StartClock is running), which keeps tests deterministic.
+package simtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+)
+
+// DefaultFrameRate is the number of simulated frames per second used for SIMCONNECT_PERIOD_SECOND
+const DefaultFrameRate = 30
+
+// inputEventsPerMessage is the number of input events per SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS message
+const inputEventsPerMessage = 50
+
+// SimConnect exception codes raised by the server
+const (
+	exceptionNameUnrecognized = uint32(client.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED)
+	exceptionUnrecognizedID   = uint32(client.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID)
+	exceptionInvalidDataSize  = uint32(client.SIMCONNECT_EXCEPTION_INVALID_DATA_SIZE)
+	exceptionEventIDDuplicate = uint32(client.SIMCONNECT_EXCEPTION_EVENT_ID_DUPLICATE)
+	exceptionGetInputEvent    = uint32(client.SIMCONNECT_EXCEPTION_GET_INPUT_EVENT_FAILED)
+	exceptionSetInputEvent    = uint32(client.SIMCONNECT_EXCEPTION_SET_INPUT_EVENT_FAILED)
+)
+
+// ValueFunc generates a simvar value for the given simulated frame
+type ValueFunc func(frame uint64) interface{}
+
+// Datum describes one entry of a data definition registered through AddToDataDefinition
+type Datum struct {
+	Name     string
+	Units    string
+	DataType client.SIMCONNECT_DATATYPE
+	Epsilon  float32
+	DatumID  uint32
+}
+
+// DataRequest describes an active RequestDataOnSimObject subscription
+type DataRequest struct {
+	RequestID client.SimObjectDataRequestID
+	DefineID  client.DataDefinitionID
+	ObjectID  client.SIMCONNECT_OBJECT_ID
+	Period    client.SIMCONNECT_PERIOD
+	Flags     client.SIMCONNECT_DATA_REQUEST_FLAG
+	Origin    uint32
+	Interval  uint32
+	Limit     uint32
+	Sent      uint32 // Number of data messages sent so far
+}
+
+// SetDataCall records a SetDataOnSimObject call
+type SetDataCall struct {
+	DefineID client.DataDefinitionID
+	ObjectID client.SIMCONNECT_OBJECT_ID
+	Flags    client.SIMCONNECT_DATA_SET_FLAG
+	Data     []byte                 // Raw data block as sent by the client
+	Values   map[string]interface{} // Decoded values by simvar name
+	Frame    uint64                 // Simulated frame at the time of the call
+}
+
+// TransmittedEvent records a TransmitClientEvent or TransmitClientEvent_EX1 call
+type TransmittedEvent struct {
+	Name     string                                  // Sim event the client event is mapped to, e.g. "PAUSE_TOGGLE"
+	EventID  client.SIMCONNECT_CLIENT_EVENT_ID       // Client event ID used by the call
+	ObjectID client.SIMCONNECT_OBJECT_ID             // Target object
+	GroupID  client.SIMCONNECT_NOTIFICATION_GROUP_ID // Notification group, or priority with SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY
+	Flags    client.SIMCONNECT_EVENT_FLAG            // Event flags
+	Data     [5]uint32                               // Parameters, TransmitClientEvent only sets Data[0]
+	Frame    uint64                                  // Simulated frame at the time of the call
+}
+
+// NotificationGroup describes a notification group created with AddClientEventToNotificationGroup
+type NotificationGroup struct {
+	Priority client.SIMCONNECT_GROUP_PRIORITY           // Group priority, SIMCONNECT_GROUP_PRIORITY_DEFAULT until set
+	Events   map[client.SIMCONNECT_CLIENT_EVENT_ID]bool // Client events in the group and whether they are maskable
+}
+
+// InputMapping describes an input mapped with MapInputEventToClientEvent
+type InputMapping struct {
+	Input       string                            // Input definition as passed by the client, e.g. "shift+ctrl+u"
+	DownEventID client.SIMCONNECT_CLIENT_EVENT_ID // Client event transmitted when the input is pressed
+	DownValue   uint32                            // Data of the down event
+	UpEventID   client.SIMCONNECT_CLIENT_EVENT_ID // Client event transmitted when the input is released, SIMCONNECT_UNUSED for none
+	UpValue     uint32                            // Data of the up event
+	Maskable    bool                              // Whether the input is withheld from lower priority input groups
+}
+
+// InputGroup describes an input group created with MapInputEventToClientEvent
+type InputGroup struct {
+	Priority client.SIMCONNECT_GROUP_PRIORITY // Group priority, SIMCONNECT_GROUP_PRIORITY_DEFAULT until set
+	State    client.SIMCONNECT_STATE          // Groups are off until turned on with SetInputGroupState
+	Inputs   map[string]InputMapping          // Mapped inputs by normalized input definition
+}
+
+// InputEvent describes an input event of the loaded aircraft, as listed by EnumerateInputEvents
+type InputEvent struct {
+	Name   string      // Input event name, e.g. "AUTOPILOT_AP_MASTER"
+	Hash   uint64      // Hash identifying the input event, InputEventHash(Name) when 0
+	Value  interface{} // Current value, a float64 or a string; the value type is derived from it
+	Params string      // Parameter types answered by EnumerateInputEventParams, e.g. "FLOAT64"
+}
+
+// SystemState is the answer the server gives to RequestSystemState
+type SystemState struct {
+	Integer uint32
+	Float   float32
+	String  string
+}
+
+// dataRequest tracks the scheduling state of an active request
+type dataRequest struct {
+	DataRequest
+	periods uint64   // Periods elapsed since the request was made
+	last    [][]byte // Last values sent, per datum, for the CHANGED flag
+}
+
+// subscription is a system event subscription
+type subscription struct {
+	name  string
+	state client.SIMCONNECT_STATE
+}
+
+// Server is an in-process SimConnect server implementing client.Transport
+type Server struct {
+	mutex         sync.Mutex
+	open          bool
+	disconnected  bool
+	appName       string
+	sendID        uint32
+	frame         uint64
+	frameRate     int
+	strict        bool
+	simvars       map[string]interface{}
+	simvarFuncs   map[string]ValueFunc
+	definitions   map[client.DataDefinitionID][]Datum
+	requests      map[client.SimObjectDataRequestID]*dataRequest
+	subscriptions map[client.SIMCONNECT_CLIENT_EVENT_ID]*subscription
+	clientEvents  map[client.SIMCONNECT_CLIENT_EVENT_ID]string
+	groups        map[client.SIMCONNECT_NOTIFICATION_GROUP_ID]*NotificationGroup
+	inputGroups   map[client.SIMCONNECT_INPUT_GROUP_ID]*InputGroup
+	reservedKeys  map[client.SIMCONNECT_CLIENT_EVENT_ID][]string
+	inputEvents   map[uint64]*InputEvent // Input events of the loaded aircraft by hash
+	inputSubs     map[uint64]bool        // Input events subscribed with SubscribeInputEvent
+	systemStates  map[string]SystemState
+	setData       []SetDataCall
+	transmitted   []TransmittedEvent
+	failures      map[string]error
+	queue         [][]byte
+	notify        chan struct{} // Wakes the network writer
+	wake          chan struct{} // Wakes WaitForMessage of an in-process client
+}
+
+// NewServer creates a server with default system states and no simvars
+func NewServer() *Server {
+	return &Server{
+		frameRate:     DefaultFrameRate,
+		simvars:       make(map[string]interface{}),
+		simvarFuncs:   make(map[string]ValueFunc),
+		definitions:   make(map[client.DataDefinitionID][]Datum),
+		requests:      make(map[client.SimObjectDataRequestID]*dataRequest),
+		subscriptions: make(map[client.SIMCONNECT_CLIENT_EVENT_ID]*subscription),
+		clientEvents:  make(map[client.SIMCONNECT_CLIENT_EVENT_ID]string),
+		groups:        make(map[client.SIMCONNECT_NOTIFICATION_GROUP_ID]*NotificationGroup),
+		inputGroups:   make(map[client.SIMCONNECT_INPUT_GROUP_ID]*InputGroup),
+		reservedKeys:  make(map[client.SIMCONNECT_CLIENT_EVENT_ID][]string),
+		inputEvents:   make(map[uint64]*InputEvent),
+		inputSubs:     make(map[uint64]bool),
+		systemStates: map[string]SystemState{
+			normalize(client.SystemStateAircraftLoaded): {String: `SimObjects\Airplanes\Asobo_C172SP_AS1000\aircraft.CFG`},
+			normalize(client.SystemStateDialogMode):     {Integer: 0},
+			normalize(client.SystemStateFlightLoaded):   {String: `flights\other\MainMenu.FLT`},
+			normalize(client.SystemStateFlightPlan):     {String: ""},
+			normalize(client.SystemStateSim):            {Integer: 1},
+		},
+		failures: make(map[string]error),
+		notify:   make(chan struct{}, 1),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// NewClient creates a SimConnect client connected to this server (Open still has to be called)
+func (s *Server) NewClient(name string) *client.Client {
+	return client.NewClientWithTransport(name, s)
+}
+
+// normalize makes simvar, event and state names case-insensitive like SimConnect
+func normalize(name string) string {
+	return strings.ToUpper(strings.TrimSpace(name))
+}
+
+// ---------------------------------------------------------------------------
+// Scripting API
+
+// SetFrameRate sets how many simulated frames make up one second
+func (s *Server) SetFrameRate(fps int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if fps < 1 {
+		fps = 1
+	}
+	s.frameRate = fps
+}
+
+// SetSimVar sets the value reported for a simulation variable.
+// Numbers and booleans are converted to the requested data type, strings are used for STRING types
+// and []byte values are copied verbatim.
+func (s *Server) SetSimVar(name string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := normalize(name)
+	delete(s.simvarFuncs, key)
+	s.simvars[key] = value
+}
+
+// SetSimVarFunc makes a simulation variable report values generated per frame
+func (s *Server) SetSimVarFunc(name string, fn ValueFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := normalize(name)
+	delete(s.simvars, key)
+	s.simvarFuncs[key] = fn
+}
+
+// SimVar returns the current value of a simulation variable, including values written by the client
+func (s *Server) SimVar(name string) (interface{}, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.simVarLocked(normalize(name))
+}
+
+// SetStrictSimVars makes AddToDataDefinition raise NAME_UNRECOGNIZED exceptions for
+// simvars that have not been set with SetSimVar or SetSimVarFunc
+func (s *Server) SetStrictSimVars(strict bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.strict = strict
+}
+
+// SetSystemState sets the answer for RequestSystemState(state)
+func (s *Server) SetSystemState(state string, value SystemState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.systemStates[normalize(state)] = value
+}
+
+// FailCall makes every subsequent call to a SimConnect function (e.g. "SimConnect_RequestDataOnSimObject")
+// return err; a nil err clears the failure
+func (s *Server) FailCall(function string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err == nil {
+		delete(s.failures, function)
+		return
+	}
+	s.failures[function] = err
+}
+
+// Step advances simulated time by the given number of frames and sends all data that becomes due
+func (s *Server) Step(frames int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := 0; i < frames; i++ {
+		s.frame++
+		for _, request := range s.sortedRequests() {
+			if s.isDue(request) {
+				s.tick(request)
+			}
+		}
+	}
+}
+
+// StartClock advances one frame per interval in the background until the returned stop function is called
+func (s *Server) StartClock(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				s.Step(1)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// Frame returns the current simulated frame number
+func (s *Server) Frame() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.frame
+}
+
+// FireEvent sends a SIMCONNECT_RECV_EVENT to every active subscription of the named
+// system event (e.g. "Pause" with data 1) and returns how many were notified
+func (s *Server) FireEvent(name string, data uint32) int {
+	return s.fire(name, func(eventID client.SIMCONNECT_CLIENT_EVENT_ID) []byte {
+		return EncodeEvent(0, eventID, data)
+	})
+}
+
+// FireFilenameEvent sends a SIMCONNECT_RECV_EVENT_FILENAME (e.g. "FlightLoaded") to every active subscription
+func (s *Server) FireFilenameEvent(name, filename string) int {
+	return s.fire(name, func(eventID client.SIMCONNECT_CLIENT_EVENT_ID) []byte {
+		return EncodeEventFilename(eventID, 0, filename)
+	})
+}
+
+// FireObjectEvent sends a SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE (e.g. "ObjectAdded") to every active subscription
+func (s *Server) FireObjectEvent(name string, objectID client.SIMCONNECT_OBJECT_ID, objectType uint32) int {
+	return s.fire(name, func(eventID client.SIMCONNECT_CLIENT_EVENT_ID) []byte {
+		return EncodeEventObjectAddRemove(eventID, objectID, objectType)
+	})
+}
+
+// FireFrameEvent sends a SIMCONNECT_RECV_EVENT_FRAME (e.g. "Frame") to every active subscription
+func (s *Server) FireFrameEvent(name string, frameRate, simSpeed float32) int {
+	return s.fire(name, func(eventID client.SIMCONNECT_CLIENT_EVENT_ID) []byte {
+		return EncodeEventFrame(eventID, frameRate, simSpeed)
+	})
+}
+
+// TriggerEvent simulates a sim event (key event) such as "THROTTLE_SET" occurring in the simulator,
+// e.g. by a key press or control movement, with up to five parameters. Every notification group
+// containing a client event mapped to it is notified, highest priority first, with a
+// SIMCONNECT_RECV_EVENT, or a SIMCONNECT_RECV_EVENT_EX1 for more than one parameter.
+// A maskable event in a group at SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE or lower masks the
+// event from lower priority groups and the simulator. TriggerEvent reports whether the simulator
+// received the event, i.e. whether no group masked it.
+func (s *Server) TriggerEvent(name string, data ...uint32) bool {
+	if len(data) > 5 {
+		panic(fmt.Sprintf("simtest: TriggerEvent takes at most 5 parameters, got %d", len(data)))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.open || s.disconnected {
+		return true
+	}
+
+	key := normalize(name)
+	return s.notifyGroups(func(eventID client.SIMCONNECT_CLIENT_EVENT_ID) bool {
+		return normalize(s.clientEvents[eventID]) == key
+	}, data)
+}
+
+// PressInput simulates pressing a key or joystick button, e.g. "shift+ctrl+u" or "joystick:0:button:3".
+// Every input group that is turned on and maps the input transmits its down event, highest priority
+// first, until a maskable mapping masks the input. The client receives the transmitted events
+// through its notification groups. PressInput returns how many mappings were triggered.
+func (s *Server) PressInput(input string) int {
+	return s.input(input, true)
+}
+
+// ReleaseInput simulates releasing a key or joystick button, transmitting the up events like PressInput
+func (s *Server) ReleaseInput(input string) int {
+	return s.input(input, false)
+}
+
+// InputEventHash returns the hash the server assigns to an input event without an explicit Hash.
+// Real simulators hand out opaque hashes, clients must take them from EnumerateInputEvents.
+func InputEventHash(name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(normalize(name)))
+	return h.Sum64()
+}
+
+// SetInputEvents replaces the input events of the loaded aircraft
+func (s *Server) SetInputEvents(events ...InputEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.setInputEvents(events)
+}
+
+// LoadAircraft simulates loading another aircraft: the AircraftLoaded system state answers path,
+// the aircraft's input events replace the current ones and every subscription of the
+// "AircraftLoaded" system event is notified. It returns how many subscriptions were notified.
+func (s *Server) LoadAircraft(path string, events ...InputEvent) int {
+	s.mutex.Lock()
+	s.systemStates[normalize(client.SystemStateAircraftLoaded)] = SystemState{String: path}
+	s.setInputEvents(events)
+	s.mutex.Unlock()
+
+	return s.FireFilenameEvent(client.SystemEventAircraftLoaded, path)
+}
+
+// SetInputEventValue changes the value of an input event as a cockpit interaction would, notifying
+// a subscribed client with SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT. It reports whether the loaded
+// aircraft has the input event.
+func (s *Server) SetInputEventValue(name string, value interface{}) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	event := s.inputEventLocked(name)
+	if event == nil {
+		return false
+	}
+	s.updateInputEvent(event, value)
+	return true
+}
+
+// InputEventValue returns the current value of an input event of the loaded aircraft
+func (s *Server) InputEventValue(name string) (interface{}, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	event := s.inputEventLocked(name)
+	if event == nil {
+		return nil, false
+	}
+	return event.Value, true
+}
+
+// InputEventSubscriptions returns the hashes of the input events the client subscribed to, in ascending order
+func (s *Server) InputEventSubscriptions() []uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hashes := make([]uint64, 0, len(s.inputSubs))
+	for hash := range s.inputSubs {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	return hashes
+}
+
+// InjectException sends a SIMCONNECT_RECV_EXCEPTION for the given packet
+func (s *Server) InjectException(exception, sendID, index uint32) {
+	s.Inject(EncodeException(exception, sendID, index))
+}
+
+// Inject queues a raw message for the client
+func (s *Server) Inject(message []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.enqueue(append([]byte(nil), message...))
+}
+
+// Quit sends SIMCONNECT_RECV_QUIT, as the simulator does when it shuts down
+func (s *Server) Quit() {
+	s.Inject(EncodeQuit())
+}
+
+// Disconnect drops the connection: once queued messages are drained every call fails
+// with STATUS_REMOTE_DISCONNECT until the client closes and reopens
+func (s *Server) Disconnect() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.disconnected = true
+	s.signal()
+}
+
+// LastSendID returns the packet ID assigned to the most recent client call
+func (s *Server) LastSendID() uint32 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.sendID
+}
+
+// ClientName returns the application name the client passed to Open
+func (s *Server) ClientName() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.appName
+}
+
+// IsOpen reports whether a client is connected
+func (s *Server) IsOpen() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.open && !s.disconnected
+}
+
+// Definition returns the datums registered for a data definition
+func (s *Server) Definition(defineID client.DataDefinitionID) []Datum {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Datum(nil), s.definitions[defineID]...)
+}
+
+// Requests returns the active data requests
+func (s *Server) Requests() []DataRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	requests := make([]DataRequest, 0, len(s.requests))
+	for _, request := range s.sortedRequests() {
+		requests = append(requests, request.DataRequest)
+	}
+	return requests
+}
+
+// Subscriptions returns the subscribed system events by client event ID
+func (s *Server) Subscriptions() map[client.SIMCONNECT_CLIENT_EVENT_ID]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	subscriptions := make(map[client.SIMCONNECT_CLIENT_EVENT_ID]string, len(s.subscriptions))
+	for id, sub := range s.subscriptions {
+		subscriptions[id] = sub.name
+	}
+	return subscriptions
+}
+
+// SetDataCalls returns every SetDataOnSimObject call received so far
+func (s *Server) SetDataCalls() []SetDataCall {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]SetDataCall(nil), s.setData...)
+}
+
+// ClientEvents returns the sim event names mapped with MapClientEventToSimEvent by client event ID
+func (s *Server) ClientEvents() map[client.SIMCONNECT_CLIENT_EVENT_ID]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	events := make(map[client.SIMCONNECT_CLIENT_EVENT_ID]string, len(s.clientEvents))
+	for id, name := range s.clientEvents {
+		events[id] = name
+	}
+	return events
+}
+
+// InputGroups returns the input groups by group ID
+func (s *Server) InputGroups() map[client.SIMCONNECT_INPUT_GROUP_ID]InputGroup {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	groups := make(map[client.SIMCONNECT_INPUT_GROUP_ID]InputGroup, len(s.inputGroups))
+	for groupID, group := range s.inputGroups {
+		inputs := make(map[string]InputMapping, len(group.Inputs))
+		for key, mapping := range group.Inputs {
+			inputs[key] = mapping
+		}
+		groups[groupID] = InputGroup{Priority: group.Priority, State: group.State, Inputs: inputs}
+	}
+	return groups
+}
+
+// ReservedKeys returns the key choices of every RequestReservedKey call by client event ID
+func (s *Server) ReservedKeys() map[client.SIMCONNECT_CLIENT_EVENT_ID][]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	keys := make(map[client.SIMCONNECT_CLIENT_EVENT_ID][]string, len(s.reservedKeys))
+	for eventID, choices := range s.reservedKeys {
+		keys[eventID] = append([]string(nil), choices...)
+	}
+	return keys
+}
+
+// NotificationGroups returns the notification groups by group ID
+func (s *Server) NotificationGroups() map[client.SIMCONNECT_NOTIFICATION_GROUP_ID]NotificationGroup {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	groups := make(map[client.SIMCONNECT_NOTIFICATION_GROUP_ID]NotificationGroup, len(s.groups))
+	for groupID, group := range s.groups {
+		events := make(map[client.SIMCONNECT_CLIENT_EVENT_ID]bool, len(group.Events))
+		for eventID, maskable := range group.Events {
+			events[eventID] = maskable
+		}
+		groups[groupID] = NotificationGroup{Priority: group.Priority, Events: events}
+	}
+	return groups
+}
+
+// TransmittedEvents returns every client event transmitted to the server, oldest first
+func (s *Server) TransmittedEvents() []TransmittedEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]TransmittedEvent(nil), s.transmitted...)
+}
+
+// ---------------------------------------------------------------------------
+// client.Transport implementation
+
+// begin assigns a packet ID to a call and checks the connection state
+// The caller must hold the mutex.
+func (s *Server) begin(function string) error {
+	if err, exists := s.failures[function]; exists {
+		return err
+	}
+	if s.disconnected {
+		return client.NewSimConnectError(function, client.STATUS_REMOTE_DISCONNECT, client.GetHRESULTMessage(client.STATUS_REMOTE_DISCONNECT))
+	}
+	if !s.open {
+		return client.NewSimConnectError(function, client.E_FAIL, "connection is not open")
+	}
+
+	s.sendID++
+	return nil
+}
+
+// Open accepts the connection and queues the SIMCONNECT_RECV_OPEN message
+func (s *Server) Open(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err, exists := s.failures["SimConnect_Open"]; exists {
+		return err
+	}
+	if s.open {
+		return client.NewSimConnectError("SimConnect_Open", client.E_FAIL, "connection is already open")
+	}
+
+	s.open = true
+	s.disconnected = false
+	s.appName = name
+	s.sendID = 1
+	s.enqueue(EncodeOpen("SimTest"))
+	return nil
+}
+
+// Close ends the connection and forgets all connection-scoped state
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err, exists := s.failures["SimConnect_Close"]; exists {
+		return err
+	}
+	if !s.open {
+		return client.NewSimConnectError("SimConnect_Close", client.E_FAIL, "connection is not open")
+	}
+
+	s.open = false
+	s.disconnected = false
+	s.definitions = make(map[client.DataDefinitionID][]Datum)
+	s.requests = make(map[client.SimObjectDataRequestID]*dataRequest)
+	s.subscriptions = make(map[client.SIMCONNECT_CLIENT_EVENT_ID]*subscription)
+	s.clientEvents = make(map[client.SIMCONNECT_CLIENT_EVENT_ID]string)
+	s.groups = make(map[client.SIMCONNECT_NOTIFICATION_GROUP_ID]*NotificationGroup)
+	s.inputGroups = make(map[client.SIMCONNECT_INPUT_GROUP_ID]*InputGroup)
+	s.reservedKeys = make(map[client.SIMCONNECT_CLIENT_EVENT_ID][]string)
+	s.inputSubs = make(map[uint64]bool)
+	s.queue = nil
+	return nil
+}
+
+func (s *Server) AddToDataDefinition(defineID client.DataDefinitionID, datumName, unitsName string, datumType client.SIMCONNECT_DATATYPE, epsilon float32, datumID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_AddToDataDefinition"); err != nil {
+		return err
+	}
+
+	// Like SimConnect the call succeeds and problems are reported as exceptions
+	key := normalize(datumName)
+	if _, known := s.simVarLocked(key); s.strict && !known {
+		s.enqueue(EncodeException(exceptionNameUnrecognized, s.sendID, 3))
+		return nil
+	}
+	if _, err := client.DataTypeSize(datumType); err != nil {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 5))
+		return nil
+	}
+
+	s.definitions[defineID] = append(s.definitions[defineID], Datum{
+		Name:     datumName,
+		Units:    unitsName,
+		DataType: datumType,
+		Epsilon:  epsilon,
+		DatumID:  datumID,
+	})
+	return nil
+}
+
+func (s *Server) ClearDataDefinition(defineID client.DataDefinitionID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_ClearDataDefinition"); err != nil {
+		return err
+	}
+
+	if _, exists := s.definitions[defineID]; !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+	delete(s.definitions, defineID)
+	return nil
+}
+
+func (s *Server) RequestDataOnSimObject(requestID client.SimObjectDataRequestID, defineID client.DataDefinitionID, objectID client.SIMCONNECT_OBJECT_ID, period client.SIMCONNECT_PERIOD, flags client.SIMCONNECT_DATA_REQUEST_FLAG, origin, interval, limit uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_RequestDataOnSimObject"); err != nil {
+		return err
+	}
+
+	if period == client.SIMCONNECT_PERIOD_NEVER {
+		delete(s.requests, requestID)
+		return nil
+	}
+
+	if _, exists := s.definitions[defineID]; !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 2))
+		return nil
+	}
+
+	request := &dataRequest{DataRequest: DataRequest{
+		RequestID: requestID,
+		DefineID:  defineID,
+		ObjectID:  objectID,
+		Period:    period,
+		Flags:     flags,
+		Origin:    origin,
+		Interval:  interval,
+		Limit:     limit,
+	}}
+
+	if period == client.SIMCONNECT_PERIOD_ONCE {
+		s.send(request)
+		return nil
+	}
+
+	s.requests[requestID] = request
+	return nil
+}
+
+func (s *Server) SetDataOnSimObject(defineID client.DataDefinitionID, objectID client.SIMCONNECT_OBJECT_ID, flags client.SIMCONNECT_DATA_SET_FLAG, arrayCount, unitSize uint32, data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_SetDataOnSimObject"); err != nil {
+		return err
+	}
+
+	datums, exists := s.definitions[defineID]
+	if !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+
+	call := SetDataCall{
+		DefineID: defineID,
+		ObjectID: objectID,
+		Flags:    flags,
+		Data:     append([]byte(nil), data...),
+		Values:   make(map[string]interface{}),
+		Frame:    s.frame,
+	}
+
+	// Values are only applied when the whole block decodes
+	if err := decodeSetData(datums, flags, data, call.Values); err != nil {
+		s.enqueue(EncodeException(exceptionInvalidDataSize, s.sendID, 6))
+	} else {
+		for name, value := range call.Values {
+			key := normalize(name)
+			delete(s.simvarFuncs, key)
+			s.simvars[key] = value
+		}
+	}
+
+	s.setData = append(s.setData, call)
+	return nil
+}
+
+func (s *Server) RequestSystemState(requestID client.DataRequestID, state string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_RequestSystemState"); err != nil {
+		return err
+	}
+
+	value, exists := s.systemStates[normalize(state)]
+	if !exists {
+		s.enqueue(EncodeException(exceptionNameUnrecognized, s.sendID, 2))
+		return nil
+	}
+
+	s.enqueue(EncodeSystemState(requestID, value.Integer, value.Float, value.String))
+	return nil
+}
+
+func (s *Server) MapClientEventToSimEvent(eventID client.SIMCONNECT_CLIENT_EVENT_ID, eventName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_MapClientEventToSimEvent"); err != nil {
+		return err
+	}
+
+	if _, exists := s.clientEvents[eventID]; exists {
+		s.enqueue(EncodeException(exceptionEventIDDuplicate, s.sendID, 1))
+		return nil
+	}
+	s.clientEvents[eventID] = eventName
+	return nil
+}
+
+func (s *Server) TransmitClientEvent(objectID client.SIMCONNECT_OBJECT_ID, eventID client.SIMCONNECT_CLIENT_EVENT_ID, data uint32, groupID client.SIMCONNECT_NOTIFICATION_GROUP_ID, flags client.SIMCONNECT_EVENT_FLAG) error {
+	return s.transmit("SimConnect_TransmitClientEvent", objectID, eventID, groupID, flags, [5]uint32{data})
+}
+
+func (s *Server) TransmitClientEvent_EX1(objectID client.SIMCONNECT_OBJECT_ID, eventID client.SIMCONNECT_CLIENT_EVENT_ID, groupID client.SIMCONNECT_NOTIFICATION_GROUP_ID, flags client.SIMCONNECT_EVENT_FLAG, data [5]uint32) error {
+	return s.transmit("SimConnect_TransmitClientEvent_EX1", objectID, eventID, groupID, flags, data)
+}
+
+// transmit records a transmitted client event; unmapped event IDs raise UNRECOGNIZED_ID
+func (s *Server) transmit(function string, objectID client.SIMCONNECT_OBJECT_ID, eventID client.SIMCONNECT_CLIENT_EVENT_ID, groupID client.SIMCONNECT_NOTIFICATION_GROUP_ID, flags client.SIMCONNECT_EVENT_FLAG, data [5]uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin(function); err != nil {
+		return err
+	}
+
+	name, exists := s.clientEvents[eventID]
+	if !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 2))
+		return nil
+	}
+
+	s.transmitted = append(s.transmitted, TransmittedEvent{
+		Name:     name,
+		EventID:  eventID,
+		ObjectID: objectID,
+		GroupID:  groupID,
+		Flags:    flags,
+		Data:     data,
+		Frame:    s.frame,
+	})
+	return nil
+}
+
+func (s *Server) AddClientEventToNotificationGroup(groupID client.SIMCONNECT_NOTIFICATION_GROUP_ID, eventID client.SIMCONNECT_CLIENT_EVENT_ID, maskable bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_AddClientEventToNotificationGroup"); err != nil {
+		return err
+	}
+
+	if _, exists := s.clientEvents[eventID]; !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 2))
+		return nil
+	}
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		group = &NotificationGroup{Priority: client.SIMCONNECT_GROUP_PRIORITY_DEFAULT, Events: make(map[client.SIMCONNECT_CLIENT_EVENT_ID]bool)}
+		s.groups[groupID] = group
+	}
+	if _, exists := group.Events[eventID]; exists {
+		s.enqueue(EncodeException(exceptionEventIDDuplicate, s.sendID, 2))
+		return nil
+	}
+	group.Events[eventID] = maskable
+	return nil
+}
+
+func (s *Server) RemoveClientEvent(groupID client.SIMCONNECT_NOTIFICATION_GROUP_ID, eventID client.SIMCONNECT_CLIENT_EVENT_ID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_RemoveClientEvent"); err != nil {
+		return err
+	}
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+	if _, exists := group.Events[eventID]; !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 2))
+		return nil
+	}
+	delete(group.Events, eventID)
+	return nil
+}
+
+func (s *Server) SetNotificationGroupPriority(groupID client.SIMCONNECT_NOTIFICATION_GROUP_ID, priority client.SIMCONNECT_GROUP_PRIORITY) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_SetNotificationGroupPriority"); err != nil {
+		return err
+	}
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+	group.Priority = priority
+	return nil
+}
+
+func (s *Server) ClearNotificationGroup(groupID client.SIMCONNECT_NOTIFICATION_GROUP_ID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_ClearNotificationGroup"); err != nil {
+		return err
+	}
+
+	if _, exists := s.groups[groupID]; !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+	delete(s.groups, groupID)
+	return nil
+}
+
+func (s *Server) MapInputEventToClientEvent(groupID client.SIMCONNECT_INPUT_GROUP_ID, inputDefinition string, downEventID client.SIMCONNECT_CLIENT_EVENT_ID, downValue uint32, upEventID client.SIMCONNECT_CLIENT_EVENT_ID, upValue uint32, maskable bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_MapInputEventToClientEvent"); err != nil {
+		return err
+	}
+
+	if _, exists := s.clientEvents[downEventID]; !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 3))
+		return nil
+	}
+	if _, exists := s.clientEvents[upEventID]; !exists && upEventID != client.SIMCONNECT_UNUSED {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 5))
+		return nil
+	}
+
+	group, exists := s.inputGroups[groupID]
+	if !exists {
+		group = &InputGroup{Priority: client.SIMCONNECT_GROUP_PRIORITY_DEFAULT, State: client.SIMCONNECT_STATE_OFF, Inputs: make(map[string]InputMapping)}
+		s.inputGroups[groupID] = group
+	}
+	group.Inputs[normalize(inputDefinition)] = InputMapping{
+		Input:       inputDefinition,
+		DownEventID: downEventID,
+		DownValue:   downValue,
+		UpEventID:   upEventID,
+		UpValue:     upValue,
+		Maskable:    maskable,
+	}
+	return nil
+}
+
+func (s *Server) SetInputGroupPriority(groupID client.SIMCONNECT_INPUT_GROUP_ID, priority client.SIMCONNECT_GROUP_PRIORITY) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_SetInputGroupPriority"); err != nil {
+		return err
+	}
+
+	group, exists := s.inputGroups[groupID]
+	if !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+	group.Priority = priority
+	return nil
+}
+
+func (s *Server) RemoveInputEvent(groupID client.SIMCONNECT_INPUT_GROUP_ID, inputDefinition string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_RemoveInputEvent"); err != nil {
+		return err
+	}
+
+	group, exists := s.inputGroups[groupID]
+	if !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+	key := normalize(inputDefinition)
+	if _, exists := group.Inputs[key]; !exists {
+		s.enqueue(EncodeException(exceptionNameUnrecognized, s.sendID, 2))
+		return nil
+	}
+	delete(group.Inputs, key)
+	return nil
+}
+
+func (s *Server) ClearInputGroup(groupID client.SIMCONNECT_INPUT_GROUP_ID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_ClearInputGroup"); err != nil {
+		return err
+	}
+
+	if _, exists := s.inputGroups[groupID]; !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+	delete(s.inputGroups, groupID)
+	return nil
+}
+
+func (s *Server) SetInputGroupState(groupID client.SIMCONNECT_INPUT_GROUP_ID, state client.SIMCONNECT_STATE) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_SetInputGroupState"); err != nil {
+		return err
+	}
+
+	group, exists := s.inputGroups[groupID]
+	if !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+	group.State = state
+	return nil
+}
+
+// RequestReservedKey reserves the first non-empty key choice and answers with SIMCONNECT_RECV_RESERVED_KEY
+func (s *Server) RequestReservedKey(eventID client.SIMCONNECT_CLIENT_EVENT_ID, keyChoice1, keyChoice2, keyChoice3 string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_RequestReservedKey"); err != nil {
+		return err
+	}
+
+	choices := []string{keyChoice1, keyChoice2, keyChoice3}
+	s.reservedKeys[eventID] = choices
+	for _, choice := range choices {
+		if choice != "" {
+			s.enqueue(EncodeReservedKey(choice, "Tab+"+choice))
+			return nil
+		}
+	}
+	s.enqueue(EncodeException(exceptionNameUnrecognized, s.sendID, 2))
+	return nil
+}
+
+func (s *Server) SubscribeToSystemEvent(eventID client.SIMCONNECT_CLIENT_EVENT_ID, systemEventName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_SubscribeToSystemEvent"); err != nil {
+		return err
+	}
+
+	s.subscriptions[eventID] = &subscription{name: systemEventName, state: client.SIMCONNECT_STATE_ON}
+	return nil
+}
+
+func (s *Server) UnsubscribeFromSystemEvent(eventID client.SIMCONNECT_CLIENT_EVENT_ID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_UnsubscribeFromSystemEvent"); err != nil {
+		return err
+	}
+
+	if _, exists := s.subscriptions[eventID]; !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+
+	delete(s.subscriptions, eventID)
+	return nil
+}
+
+func (s *Server) SetSystemEventState(eventID client.SIMCONNECT_CLIENT_EVENT_ID, state client.SIMCONNECT_STATE) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_SetSystemEventState"); err != nil {
+		return err
+	}
+
+	sub, exists := s.subscriptions[eventID]
+	if !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+
+	sub.state = state
+	return nil
+}
+
+// EnumerateInputEvents answers with the input events of the loaded aircraft ordered by name,
+// split into several messages like the simulator does for long lists
+func (s *Server) EnumerateInputEvents(requestID client.DataRequestID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_EnumerateInputEvents"); err != nil {
+		return err
+	}
+
+	events := make([]client.SIMCONNECT_INPUT_EVENT_DESCRIPTOR, 0, len(s.inputEvents))
+	for _, event := range s.inputEvents {
+		events = append(events, client.SIMCONNECT_INPUT_EVENT_DESCRIPTOR{Name: event.Name, Hash: event.Hash, Type: inputEventType(event.Value)})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+
+	outOf := uint32((len(events) + inputEventsPerMessage - 1) / inputEventsPerMessage)
+	if outOf == 0 {
+		outOf = 1 // An aircraft without input events still gets an (empty) answer
+	}
+	for entry := uint32(0); entry < outOf; entry++ {
+		start := int(entry) * inputEventsPerMessage
+		end := start + inputEventsPerMessage
+		if end > len(events) {
+			end = len(events)
+		}
+		s.enqueue(EncodeEnumerateInputEvents(requestID, entry, outOf, events[start:end]))
+	}
+	return nil
+}
+
+// GetInputEvent answers with the value of an input event
+func (s *Server) GetInputEvent(requestID client.DataRequestID, hash uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_GetInputEvent"); err != nil {
+		return err
+	}
+
+	event, exists := s.inputEvents[hash]
+	if !exists {
+		s.enqueue(EncodeException(exceptionGetInputEvent, s.sendID, 2))
+		return nil
+	}
+
+	s.enqueue(EncodeGetInputEvent(requestID, event.Value))
+	return nil
+}
+
+// SetInputEvent changes the value of an input event, notifying the client if it is subscribed
+func (s *Server) SetInputEvent(hash uint64, value []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_SetInputEvent"); err != nil {
+		return err
+	}
+
+	event, exists := s.inputEvents[hash]
+	if !exists {
+		s.enqueue(EncodeException(exceptionSetInputEvent, s.sendID, 1))
+		return nil
+	}
+
+	if inputEventType(event.Value) == client.SIMCONNECT_INPUT_EVENT_TYPE_STRING {
+		s.updateInputEvent(event, string(bytes.TrimRight(value, "\x00")))
+		return nil
+	}
+	if len(value) != 8 {
+		s.enqueue(EncodeException(exceptionInvalidDataSize, s.sendID, 2))
+		return nil
+	}
+	s.updateInputEvent(event, math.Float64frombits(binary.LittleEndian.Uint64(value)))
+	return nil
+}
+
+// SubscribeInputEvent notifies the client of value changes of an input event
+func (s *Server) SubscribeInputEvent(hash uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_SubscribeInputEvent"); err != nil {
+		return err
+	}
+
+	if _, exists := s.inputEvents[hash]; !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+
+	s.inputSubs[hash] = true
+	return nil
+}
+
+func (s *Server) UnsubscribeInputEvent(hash uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_UnsubscribeInputEvent"); err != nil {
+		return err
+	}
+
+	if !s.inputSubs[hash] {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+
+	delete(s.inputSubs, hash)
+	return nil
+}
+
+// EnumerateInputEventParams answers with the parameter types of an input event
+func (s *Server) EnumerateInputEventParams(hash uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.begin("SimConnect_EnumerateInputEventParams"); err != nil {
+		return err
+	}
+
+	event, exists := s.inputEvents[hash]
+	if !exists {
+		s.enqueue(EncodeException(exceptionUnrecognizedID, s.sendID, 1))
+		return nil
+	}
+
+	s.enqueue(EncodeEnumerateInputEventParams(hash, event.Params))
+	return nil
+}
+
+// GetLastSentPacketID returns the packet ID of the last successful call
+func (s *Server) GetLastSentPacketID() (uint32, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err, exists := s.failures["SimConnect_GetLastSentPacketID"]; exists {
+		return 0, err
+	}
+	return s.sendID, nil
+}
+
+// GetNextDispatch returns the next queued message, or the disconnect error once the queue is drained
+func (s *Server) GetNextDispatch() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err, exists := s.failures["SimConnect_GetNextDispatch"]; exists {
+		return nil, err
+	}
+
+	if len(s.queue) > 0 {
+		data := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		return data, nil
+	}
+
+	if s.disconnected {
+		return nil, client.NewSimConnectError("SimConnect_GetNextDispatch", client.STATUS_REMOTE_DISCONNECT, client.GetHRESULTMessage(client.STATUS_REMOTE_DISCONNECT))
+	}
+
+	return nil, nil
+}
+
+// WaitForMessage blocks until a message is queued, the connection drops or ctx is done
+func (s *Server) WaitForMessage(ctx context.Context) error {
+	s.mutex.Lock()
+	ready := len(s.queue) > 0 || s.disconnected
+	s.mutex.Unlock()
+
+	if ready {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.wake:
+		return nil
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Internals (callers hold the mutex)
+
+// enqueue adds a message to the dispatch queue and wakes up waiting readers
+func (s *Server) enqueue(message []byte) {
+	s.queue = append(s.queue, message)
+	s.signal()
+}
+
+// signal performs a non-blocking notification
+func (s *Server) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// simVarLocked returns the current value of a simvar
+func (s *Server) simVarLocked(key string) (interface{}, bool) {
+	if fn, exists := s.simvarFuncs[key]; exists {
+		return fn(s.frame), true
+	}
+	value, exists := s.simvars[key]
+	return value, exists
+}
+
+// sortedRequests returns active requests ordered by request ID for deterministic output
+func (s *Server) sortedRequests() []*dataRequest {
+	requests := make([]*dataRequest, 0, len(s.requests))
+	for _, request := range s.requests {
+		requests = append(requests, request)
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].RequestID < requests[j].RequestID })
+	return requests
+}
+
+// isDue reports whether a request's period elapses on the current frame
+func (s *Server) isDue(request *dataRequest) bool {
+	switch request.Period {
+	case client.SIMCONNECT_PERIOD_VISUAL_FRAME, client.SIMCONNECT_PERIOD_SIM_FRAME:
+		return true
+	case client.SIMCONNECT_PERIOD_SECOND:
+		return s.frame%uint64(s.frameRate) == 0
+	default:
+		return false
+	}
+}
+
+// tick applies origin, interval and limit to an elapsed period and sends data when appropriate
+func (s *Server) tick(request *dataRequest) {
+	request.periods++
+	if request.periods <= uint64(request.Origin) {
+		return
+	}
+	if (request.periods-uint64(request.Origin)-1)%(uint64(request.Interval)+1) != 0 {
+		return
+	}
+
+	s.send(request)
+
+	if request.Limit > 0 && request.Sent >= request.Limit {
+		delete(s.requests, request.RequestID)
+	}
+}
+
+// send encodes the current values of a request's definition and queues a SIMOBJECT_DATA message
+func (s *Server) send(request *dataRequest) {
+	datums := s.definitions[request.DefineID]
+
+	values := make([][]byte, len(datums))
+	for i, datum := range datums {
+		value, _ := s.simVarLocked(normalize(datum.Name))
+		encoded, err := encodeValue(datum.DataType, value)
+		if err != nil {
+			encoded, _ = encodeValue(datum.DataType, nil)
+		}
+		values[i] = encoded
+	}
+
+	if len(request.last) != len(values) {
+		request.last = nil // The definition changed since the last message, everything counts as changed
+	}
+
+	changedOnly := request.Flags&client.SIMCONNECT_DATA_REQUEST_FLAG_CHANGED != 0
+	tagged := request.Flags&client.SIMCONNECT_DATA_REQUEST_FLAG_TAGGED != 0
+
+	var block []byte
+	count := 0
+	for i, value := range values {
+		changed := request.last == nil || !bytes.Equal(request.last[i], value)
+		if tagged {
+			if changedOnly && !changed {
+				continue
+			}
+			block = binary.LittleEndian.AppendUint32(block, datums[i].DatumID)
+		}
+		block = append(block, value...)
+		if changed {
+			count++
+		}
+	}
+
+	if changedOnly && request.last != nil && count == 0 {
+		return
+	}
+
+	defineCount := uint32(len(datums))
+	if tagged {
+		defineCount = uint32(count)
+		if !changedOnly {
+			defineCount = uint32(len(datums))
+		}
+	}
+
+	request.last = values
+	request.Sent++
+	s.enqueue(EncodeSimObjectData(request.RequestID, request.ObjectID, request.DefineID, request.Flags, defineCount, block))
+}
+
+// decodeSetData decodes a SetDataOnSimObject block into values keyed by simvar name
+func decodeSetData(datums []Datum, flags client.SIMCONNECT_DATA_SET_FLAG, data []byte, values map[string]interface{}) error {
+	offset := 0
+	if flags&client.SIMCONNECT_DATA_SET_FLAG_TAGGED != 0 {
+		for offset < len(data) {
+			if len(data)-offset < 4 {
+				return fmt.Errorf("truncated tagged datum")
+			}
+			datumID := binary.LittleEndian.Uint32(data[offset:])
+			offset += 4
+
+			index := -1
+			for i, datum := range datums {
+				if datum.DatumID == datumID {
+					index = i
+					break
+				}
+			}
+			if index < 0 {
+				return fmt.Errorf("unknown datum ID %d", datumID)
+			}
+
+			value, n, err := decodeValue(datums[index].DataType, data[offset:])
+			if err != nil {
+				return err
+			}
+			offset += n
+			values[datums[index].Name] = value
+		}
+		return nil
+	}
+
+	for _, datum := range datums {
+		value, n, err := decodeValue(datum.DataType, data[offset:])
+		if err != nil {
+			return err
+		}
+		offset += n
+		values[datum.Name] = value
+	}
+	return nil
+}
+
+// notifyGroups notifies the notification groups containing the matching client events, highest
+// priority first, and reports whether the event reached the simulator, i.e. no maskable event
+// in a group at SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE or lower masked it
+func (s *Server) notifyGroups(match func(eventID client.SIMCONNECT_CLIENT_EVENT_ID) bool, data []uint32) bool {
+	// Groups containing the event, ordered by priority (lowest value first) and group ID
+	type member struct {
+		groupID  client.SIMCONNECT_NOTIFICATION_GROUP_ID
+		eventID  client.SIMCONNECT_CLIENT_EVENT_ID
+		priority client.SIMCONNECT_GROUP_PRIORITY
+		maskable bool
+	}
+	members := make([]member, 0)
+	for groupID, group := range s.groups {
+		for eventID, maskable := range group.Events {
+			if match(eventID) {
+				members = append(members, member{groupID, eventID, group.Priority, maskable})
+			}
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].priority != members[j].priority {
+			return members[i].priority < members[j].priority
+		}
+		return members[i].groupID < members[j].groupID
+	})
+
+	var values [5]uint32
+	copy(values[:], data)
+	for _, m := range members {
+		if len(data) > 1 {
+			s.enqueue(EncodeEventEx1(uint32(m.groupID), m.eventID, values))
+		} else {
+			s.enqueue(EncodeEvent(uint32(m.groupID), m.eventID, values[0]))
+		}
+		if m.maskable && m.priority >= client.SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE {
+			return false
+		}
+	}
+	return true
+}
+
+// input transmits the down or up events of every active input group mapping an input
+func (s *Server) input(input string, down bool) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.open || s.disconnected {
+		return 0
+	}
+
+	// Active groups mapping the input, ordered by priority (lowest value first) and group ID
+	key := normalize(input)
+	groupIDs := make([]client.SIMCONNECT_INPUT_GROUP_ID, 0)
+	for groupID, group := range s.inputGroups {
+		if _, exists := group.Inputs[key]; exists && group.State == client.SIMCONNECT_STATE_ON {
+			groupIDs = append(groupIDs, groupID)
+		}
+	}
+	sort.Slice(groupIDs, func(i, j int) bool {
+		a, b := s.inputGroups[groupIDs[i]], s.inputGroups[groupIDs[j]]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return groupIDs[i] < groupIDs[j]
+	})
+
+	triggered := 0
+	for _, groupID := range groupIDs {
+		mapping := s.inputGroups[groupID].Inputs[key]
+		eventID, value := mapping.DownEventID, mapping.DownValue
+		if !down {
+			eventID, value = mapping.UpEventID, mapping.UpValue
+		}
+		if eventID != client.SIMCONNECT_UNUSED {
+			s.notifyGroups(func(id client.SIMCONNECT_CLIENT_EVENT_ID) bool { return id == eventID }, []uint32{value})
+			triggered++
+		}
+		if mapping.Maskable {
+			break
+		}
+	}
+	return triggered
+}
+
+// setInputEvents replaces the input events of the loaded aircraft; the caller holds the mutex
+func (s *Server) setInputEvents(events []InputEvent) {
+	s.inputEvents = make(map[uint64]*InputEvent, len(events))
+	for _, event := range events {
+		event := event
+		if event.Hash == 0 {
+			event.Hash = InputEventHash(event.Name)
+		}
+		if event.Value == nil {
+			event.Value = 0.0
+		}
+		s.inputEvents[event.Hash] = &event
+	}
+}
+
+// inputEventLocked finds an input event of the loaded aircraft by name; the caller holds the mutex
+func (s *Server) inputEventLocked(name string) *InputEvent {
+	key := normalize(name)
+	for _, event := range s.inputEvents {
+		if normalize(event.Name) == key {
+			return event
+		}
+	}
+	return nil
+}
+
+// updateInputEvent stores a new value and notifies a subscribed client; the caller holds the mutex
+func (s *Server) updateInputEvent(event *InputEvent, value interface{}) {
+	event.Value = value
+	if s.open && !s.disconnected && s.inputSubs[event.Hash] {
+		s.enqueue(EncodeSubscribeInputEvent(event.Hash, value))
+	}
+}
+
+// inputEventType returns the SIMCONNECT_INPUT_EVENT_TYPE of an input event value
+func inputEventType(value interface{}) client.SIMCONNECT_INPUT_EVENT_TYPE {
+	if _, ok := value.(string); ok {
+		return client.SIMCONNECT_INPUT_EVENT_TYPE_STRING
+	}
+	return client.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE
+}
+
+// fire queues an event message for every active subscription of the named event
+func (s *Server) fire(name string, encode func(eventID client.SIMCONNECT_CLIENT_EVENT_ID) []byte) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.open || s.disconnected {
+		return 0
+	}
+
+	key := normalize(name)
+	ids := make([]client.SIMCONNECT_CLIENT_EVENT_ID, 0)
+	for id, sub := range s.subscriptions {
+		if normalize(sub.name) == key && sub.state == client.SIMCONNECT_STATE_ON {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		s.enqueue(encode(id))
+	}
+	return len(ids)
+}
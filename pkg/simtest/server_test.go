@@ -0,0 +1,253 @@
+package simtest_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+	"github.com/mrlm-net/go-simconnect/pkg/simtest"
+)
+
+// openServer returns a server with an open connection and the OPEN message consumed
+func openServer(t *testing.T) *simtest.Server {
+	t.Helper()
+	server := simtest.NewServer()
+	if err := server.Open("test"); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if messages := drain(t, server); len(messages) != 1 {
+		t.Fatalf("%d messages after Open, want the OPEN message", len(messages))
+	}
+	return server
+}
+
+// drain returns the queued messages
+func drain(t *testing.T, server *simtest.Server) [][]byte {
+	t.Helper()
+	var messages [][]byte
+	for {
+		data, err := server.GetNextDispatch()
+		if err != nil {
+			t.Fatalf("GetNextDispatch: %v", err)
+		}
+		if data == nil {
+			return messages
+		}
+		messages = append(messages, data)
+	}
+}
+
+// float64s decodes a data block of float64 values
+func float64s(block []byte) []float64 {
+	values := make([]float64, len(block)/8)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(block[i*8:]))
+	}
+	return values
+}
+
+func TestServerSendsValuesAtEachPeriod(t *testing.T) {
+	tests := []struct {
+		name   string
+		period client.SIMCONNECT_PERIOD
+		want   []float64 // Airspeed of each message, which is the frame it was sent on
+	}{
+		{"never", client.SIMCONNECT_PERIOD_NEVER, nil},
+		{"once", client.SIMCONNECT_PERIOD_ONCE, []float64{0}},
+		{"visual frame", client.SIMCONNECT_PERIOD_VISUAL_FRAME, []float64{1, 2, 3, 4, 5, 6, 7, 8}},
+		{"sim frame", client.SIMCONNECT_PERIOD_SIM_FRAME, []float64{1, 2, 3, 4, 5, 6, 7, 8}},
+		{"second", client.SIMCONNECT_PERIOD_SECOND, []float64{4, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := openServer(t)
+			server.SetFrameRate(4)
+			server.SetSimVar("PLANE ALTITUDE", 1500)
+			server.SetSimVarFunc("AIRSPEED INDICATED", func(frame uint64) interface{} { return float64(frame) })
+
+			server.AddToDataDefinition(1, "PLANE ALTITUDE", "feet", client.SIMCONNECT_DATATYPE_FLOAT64, 0, 0)
+			server.AddToDataDefinition(1, "AIRSPEED INDICATED", "knots", client.SIMCONNECT_DATATYPE_FLOAT64, 0, 0)
+			if err := server.RequestDataOnSimObject(7, 1, client.SIMCONNECT_OBJECT_ID_USER, tt.period, 0, 0, 0, 0); err != nil {
+				t.Fatalf("RequestDataOnSimObject: %v", err)
+			}
+			server.Step(8)
+
+			var got []float64
+			for _, data := range drain(t, server) {
+				recv, block, err := client.ParseSimObjectData(data)
+				if err != nil {
+					t.Fatalf("ParseSimObjectData: %v", err)
+				}
+				if recv.DwRequestID != 7 || recv.DwDefineID != 1 || recv.DwDefineCount != 2 {
+					t.Fatalf("data of request %d, definition %d with %d values", recv.DwRequestID, recv.DwDefineID, recv.DwDefineCount)
+				}
+				values := float64s(block)
+				if values[0] != 1500 {
+					t.Errorf("scripted altitude %v, want 1500", values[0])
+				}
+				got = append(got, values[1])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generated values %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerFiresSystemEventsOnDemand(t *testing.T) {
+	server := openServer(t)
+	server.SubscribeToSystemEvent(1, client.SystemEventPause)
+	server.SubscribeToSystemEvent(2, "pause")
+	server.SetSystemEventState(2, client.SIMCONNECT_STATE_OFF)
+	server.SubscribeToSystemEvent(3, client.SystemEventFlightLoaded)
+
+	// Subscriptions turned off are skipped
+	if notified := server.FireEvent(client.SystemEventPause, 1); notified != 1 {
+		t.Errorf("FireEvent notified %d subscriptions, want 1", notified)
+	}
+	if notified := server.FireFilenameEvent(client.SystemEventFlightLoaded, `flights\other\test.flt`); notified != 1 {
+		t.Errorf("FireFilenameEvent notified %d subscriptions, want 1", notified)
+	}
+	if notified := server.FireEvent(client.SystemEventSimStart, 0); notified != 0 {
+		t.Errorf("FireEvent without subscription notified %d subscriptions", notified)
+	}
+
+	messages := drain(t, server)
+	if len(messages) != 2 {
+		t.Fatalf("%d messages, want 2", len(messages))
+	}
+	event, err := client.ParseEvent(messages[0])
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	if event.EventID != 1 || event.Data != 1 {
+		t.Errorf("event %d with data %d, want event 1 with data 1", event.EventID, event.Data)
+	}
+	filename, err := client.ParseEventFilename(messages[1])
+	if err != nil {
+		t.Fatalf("ParseEventFilename: %v", err)
+	}
+	if filename.EventID != 3 {
+		t.Errorf("filename event %d, want 3", filename.EventID)
+	}
+
+	// System states are answered with the scripted value
+	server.SetSystemState(client.SystemStateSim, simtest.SystemState{Integer: 0})
+	server.RequestSystemState(5, client.SystemStateSim)
+	messages = drain(t, server)
+	if len(messages) != 1 {
+		t.Fatalf("%d messages, want 1", len(messages))
+	}
+	state, err := client.ParseSystemState(messages[0])
+	if err != nil {
+		t.Fatalf("ParseSystemState: %v", err)
+	}
+	if state.DwRequestID != 5 || state.DwInteger != 0 {
+		t.Errorf("state of request %d is %d, want request 5 with 0", state.DwRequestID, state.DwInteger)
+	}
+}
+
+func TestServerRecordsSetData(t *testing.T) {
+	server := openServer(t)
+	server.SetSimVar("PLANE ALTITUDE", 1500.0)
+	server.AddToDataDefinition(1, "PLANE ALTITUDE", "feet", client.SIMCONNECT_DATATYPE_FLOAT64, 0, 0)
+	server.Step(3)
+
+	data := binary.LittleEndian.AppendUint64(nil, math.Float64bits(2000))
+	if err := server.SetDataOnSimObject(1, client.SIMCONNECT_OBJECT_ID_USER, 0, 1, 8, data); err != nil {
+		t.Fatalf("SetDataOnSimObject: %v", err)
+	}
+	// A truncated block is recorded, raises an exception and changes nothing
+	if err := server.SetDataOnSimObject(1, client.SIMCONNECT_OBJECT_ID_USER, 0, 1, 4, data[:4]); err != nil {
+		t.Fatalf("SetDataOnSimObject: %v", err)
+	}
+	truncatedSendID := server.LastSendID()
+
+	calls := server.SetDataCalls()
+	if len(calls) != 2 {
+		t.Fatalf("%d calls recorded, want 2", len(calls))
+	}
+	call := calls[0]
+	if call.DefineID != 1 || call.ObjectID != client.SIMCONNECT_OBJECT_ID_USER || call.Frame != 3 || !reflect.DeepEqual(call.Data, data) {
+		t.Errorf("recorded %+v", call)
+	}
+	if value := call.Values["PLANE ALTITUDE"]; value != 2000.0 {
+		t.Errorf("decoded altitude %v, want 2000", value)
+	}
+	if value, _ := server.SimVar("plane altitude"); value != 2000.0 {
+		t.Errorf("altitude %v after the write, want 2000", value)
+	}
+
+	messages := drain(t, server)
+	if len(messages) != 1 {
+		t.Fatalf("%d messages, want the exception of the truncated write", len(messages))
+	}
+	exception, err := client.ParseException(messages[0])
+	if err != nil {
+		t.Fatalf("ParseException: %v", err)
+	}
+	if exception.Exception != client.SIMCONNECT_EXCEPTION_INVALID_DATA_SIZE || exception.SendID != truncatedSendID {
+		t.Errorf("exception %d for send ID %d, want INVALID_DATA_SIZE for %d", exception.Exception, exception.SendID, truncatedSendID)
+	}
+}
+
+func TestServerInjectsExceptionsQuitAndDisconnects(t *testing.T) {
+	server := openServer(t)
+
+	server.InjectException(uint32(client.SIMCONNECT_EXCEPTION_ERROR), 42, 3)
+	server.Quit()
+	messages := drain(t, server)
+	if len(messages) != 2 {
+		t.Fatalf("%d messages, want 2", len(messages))
+	}
+	exception, err := client.ParseException(messages[0])
+	if err != nil {
+		t.Fatalf("ParseException: %v", err)
+	}
+	if exception.Exception != client.SIMCONNECT_EXCEPTION_ERROR || exception.SendID != 42 || exception.Index != 3 {
+		t.Errorf("exception %+v, want ERROR for send ID 42 at index 3", exception)
+	}
+	if recvID, _ := client.ParseMessageType(messages[1]); recvID != client.SIMCONNECT_RECV_ID_QUIT {
+		t.Errorf("message type %d, want QUIT", recvID)
+	}
+
+	// Failed calls return the scripted error until cleared
+	refused := errors.New("refused")
+	server.FailCall("SimConnect_SubscribeToSystemEvent", refused)
+	if err := server.SubscribeToSystemEvent(1, client.SystemEventPause); !errors.Is(err, refused) {
+		t.Errorf("SubscribeToSystemEvent error %v, want %v", err, refused)
+	}
+	server.FailCall("SimConnect_SubscribeToSystemEvent", nil)
+	if err := server.SubscribeToSystemEvent(1, client.SystemEventPause); err != nil {
+		t.Errorf("SubscribeToSystemEvent after clearing the failure: %v", err)
+	}
+
+	// After a disconnect queued messages are still delivered, then every call fails
+	server.InjectException(uint32(client.SIMCONNECT_EXCEPTION_ERROR), 43, 1)
+	server.Disconnect()
+	if data, err := server.GetNextDispatch(); err != nil || data == nil {
+		t.Fatalf("queued message not delivered after Disconnect: %v", err)
+	}
+	var simErr *client.SimConnectError
+	if _, err := server.GetNextDispatch(); !errors.As(err, &simErr) || simErr.HRESULT != client.STATUS_REMOTE_DISCONNECT {
+		t.Errorf("GetNextDispatch error %v, want STATUS_REMOTE_DISCONNECT", err)
+	}
+	if err := server.SubscribeToSystemEvent(2, client.SystemEventPause); !errors.As(err, &simErr) || simErr.HRESULT != client.STATUS_REMOTE_DISCONNECT {
+		t.Errorf("call error %v, want STATUS_REMOTE_DISCONNECT", err)
+	}
+
+	// Closing and reopening starts a fresh connection
+	if err := server.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := server.Open("test"); err != nil {
+		t.Fatalf("Open after disconnect: %v", err)
+	}
+	if subscriptions := server.Subscriptions(); len(subscriptions) != 0 {
+		t.Errorf("subscriptions %v survived the reconnect", subscriptions)
+	}
+}
@@ -0,0 +1,140 @@
+package simtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/mrlm-net/go-simconnect/pkg/client"
+)
+
+// encodeValue converts a simvar value to the wire representation of dataType.
+// Numbers and booleans convert between numeric types, strings are NUL-padded,
+// SimConnect structures (e.g. client.SIMCONNECT_DATA_LATLONALT) are packed
+// and []byte values are copied verbatim into fixed-size fields.
+func encodeValue(dataType client.SIMCONNECT_DATATYPE, value interface{}) ([]byte, error) {
+	size, err := client.DataTypeSize(dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	if raw, ok := value.([]byte); ok && size > 0 {
+		out := make([]byte, size)
+		copy(out, raw)
+		return out, nil
+	}
+
+	switch dataType {
+	case client.SIMCONNECT_DATATYPE_INT32:
+		f, err := toFloat64(value)
+		return binary.LittleEndian.AppendUint32(nil, uint32(int32(f))), err
+	case client.SIMCONNECT_DATATYPE_INT64:
+		f, err := toFloat64(value)
+		return binary.LittleEndian.AppendUint64(nil, uint64(int64(f))), err
+	case client.SIMCONNECT_DATATYPE_FLOAT32:
+		f, err := toFloat64(value)
+		return binary.LittleEndian.AppendUint32(nil, math.Float32bits(float32(f))), err
+	case client.SIMCONNECT_DATATYPE_FLOAT64:
+		f, err := toFloat64(value)
+		return binary.LittleEndian.AppendUint64(nil, math.Float64bits(f)), err
+	case client.SIMCONNECT_DATATYPE_STRINGV:
+		return append([]byte(toString(value)), 0), nil
+	case client.SIMCONNECT_DATATYPE_INITPOSITION, client.SIMCONNECT_DATATYPE_MARKERSTATE,
+		client.SIMCONNECT_DATATYPE_WAYPOINT, client.SIMCONNECT_DATATYPE_LATLONALT, client.SIMCONNECT_DATATYPE_XYZ,
+		client.SIMCONNECT_DATATYPE_PBH:
+		if value == nil {
+			return make([]byte, size), nil
+		}
+		out, err := binary.Append(nil, binary.LittleEndian, value)
+		if err == nil && len(out) != size {
+			err = fmt.Errorf("value %T does not match data type %s", value, dataType)
+		}
+		return out, err
+	default:
+		// Fixed-size strings keep at least one terminating NUL
+		out := make([]byte, size)
+		copy(out[:size-1], toString(value))
+		return out, nil
+	}
+}
+
+// decodeValue reads one datum of dataType from data and returns it with its encoded size
+func decodeValue(dataType client.SIMCONNECT_DATATYPE, data []byte) (interface{}, int, error) {
+	size, err := client.DataTypeSize(dataType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if dataType == client.SIMCONNECT_DATATYPE_STRINGV {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			return nil, 0, fmt.Errorf("unterminated STRINGV value")
+		}
+		return string(data[:end]), end + 1, nil
+	}
+
+	if len(data) < size {
+		return nil, 0, fmt.Errorf("need %d bytes for data type %d, have %d", size, dataType, len(data))
+	}
+
+	switch dataType {
+	case client.SIMCONNECT_DATATYPE_INT32:
+		return int32(binary.LittleEndian.Uint32(data)), size, nil
+	case client.SIMCONNECT_DATATYPE_INT64:
+		return int64(binary.LittleEndian.Uint64(data)), size, nil
+	case client.SIMCONNECT_DATATYPE_FLOAT32:
+		return math.Float32frombits(binary.LittleEndian.Uint32(data)), size, nil
+	case client.SIMCONNECT_DATATYPE_FLOAT64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), size, nil
+	case client.SIMCONNECT_DATATYPE_INITPOSITION, client.SIMCONNECT_DATATYPE_MARKERSTATE,
+		client.SIMCONNECT_DATATYPE_WAYPOINT, client.SIMCONNECT_DATATYPE_LATLONALT, client.SIMCONNECT_DATATYPE_XYZ,
+		client.SIMCONNECT_DATATYPE_PBH:
+		return append([]byte(nil), data[:size]...), size, nil
+	default:
+		field := data[:size]
+		if end := bytes.IndexByte(field, 0); end >= 0 {
+			field = field[:end]
+		}
+		return string(field), size, nil
+	}
+}
+
+// toFloat64 converts the numeric value kinds accepted by SetSimVar
+func toFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case nil:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("value %v (%T) is not numeric", value, value)
+	}
+}
+
+// toString converts a simvar value for string data types
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}